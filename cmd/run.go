@@ -29,6 +29,8 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 )
@@ -83,6 +85,16 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	g, ctx := errgroup.WithContext(ctx)
 
+	// go-ethereum only records metrics when they
+	// are enabled globally.
+	var registry metrics.Registry
+	var clientMetrics *ethereum.Metrics
+	if cfg.Metrics {
+		metrics.Enabled = true
+		registry = metrics.NewRegistry()
+		clientMetrics = ethereum.NewMetrics(registry)
+	}
+
 	var client *ethereum.Client
 	if cfg.Mode == configuration.Online {
 		if !cfg.RemoteGeth {
@@ -91,8 +103,42 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 			})
 		}
 
-		var err error
-		client, err = ethereum.NewClient(cfg.GethURL, cfg.Params, cfg.SkipGethAdmin)
+		var cache *ethereum.BlockCache
+		if len(cfg.BlockCacheDir) > 0 {
+			storage, err := ethereum.NewFileBlockStorage(cfg.BlockCacheDir)
+			if err != nil {
+				return fmt.Errorf("%w: cannot initialize block cache", err)
+			}
+			cache = ethereum.NewBlockCache(storage, cfg.BlockCacheFinalityDepth)
+		}
+
+		traceConfig, err := cfg.TraceConfig()
+		if err != nil {
+			return err
+		}
+
+		var bundlerURL string
+		if cfg.AccountAbstraction != nil {
+			bundlerURL = cfg.AccountAbstraction.BundlerURL
+		}
+
+		client, err = ethereum.NewClient(
+			append([]string{cfg.GethURL}, cfg.GethFailoverURLs...),
+			cfg.Params,
+			cfg.SkipGethAdmin,
+			&ethereum.ClientOptions{
+				TraceAPI:              cfg.TraceAPI,
+				TraceConfig:           traceConfig,
+				BalanceAPI:            cfg.BalanceAPI,
+				Tokens:                cfg.Tokens,
+				Events:                cfg.Events,
+				Cache:                 cache,
+				BlockTransactionLimit: cfg.BlockTransactionLimit,
+				IncludeZeroValueCalls: cfg.IncludeZeroValueCalls,
+				BundlerURL:            bundlerURL,
+				Metrics:               clientMetrics,
+			},
+		)
 		if err != nil {
 			return fmt.Errorf("%w: cannot initialize ethereum client", err)
 		}
@@ -103,9 +149,18 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	loggedRouter := server.LoggerMiddleware(router)
 	corsRouter := server.CorsMiddleware(loggedRouter)
+
+	var handler http.Handler = corsRouter
+	if cfg.Metrics {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", prometheus.Handler(registry))
+		mux.Handle("/", services.MetricsMiddleware(registry, corsRouter))
+		handler = mux
+	}
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      corsRouter,
+		Handler:      handler,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
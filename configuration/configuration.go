@@ -15,14 +15,20 @@
 package configuration
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/coinbase/rosetta-ethereum/ethereum"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -54,6 +60,10 @@ const (
 	// Testnet defaults to `Ropsten` for backwards compatibility.
 	Testnet string = "TESTNET"
 
+	// Custom is a network defined in the file
+	// at NetworkConfigEnv.
+	Custom string = "CUSTOM"
+
 	// DataDirectory is the default location for all
 	// persistent data.
 	DataDirectory = "/data"
@@ -66,6 +76,11 @@ const (
 	// read to determine network.
 	NetworkEnv = "NETWORK"
 
+	// NetworkConfigEnv is the environment variable
+	// read to determine the path of the file defining
+	// the network when NetworkEnv is Custom.
+	NetworkConfigEnv = "NETWORK_CONFIG"
+
 	// PortEnv is the environment variable
 	// read to determine the port for the Rosetta
 	// implementation.
@@ -73,7 +88,8 @@ const (
 
 	// GethEnv is an optional environment variable
 	// used to connect rosetta-ethereum to an already
-	// running geth node.
+	// running geth node. Multiple comma-separated nodes
+	// can be provided to fail over between them.
 	GethEnv = "GETH"
 
 	// DefaultGethURL is the default URL for
@@ -86,6 +102,118 @@ const (
 	// by hosted node services. When not set, defaults to false.
 	SkipGethAdminEnv = "SKIP_GETH_ADMIN"
 
+	// TokenListEnv is an optional environment variable
+	// pointing to a JSON file of ERC-20 tokens to support.
+	// When not set, no tokens are supported.
+	TokenListEnv = "TOKEN_LIST"
+
+	// ABIDirEnv is an optional environment variable
+	// pointing to a directory of contract ABI JSON files.
+	// The logs of the events in these ABIs are decoded
+	// into transaction metadata. When not set, no logs
+	// are decoded.
+	ABIDirEnv = "ABI_DIR"
+
+	// BlockCacheDirEnv is an optional environment variable
+	// pointing to a directory where the receipts and traces
+	// of final blocks are cached. When not set, blocks are
+	// not cached.
+	BlockCacheDirEnv = "BLOCK_CACHE_DIR"
+
+	// BlockCacheFinalityDepthEnv is an optional environment
+	// variable that sets the number of blocks a block must be
+	// below the chain head before it is cached.
+	BlockCacheFinalityDepthEnv = "BLOCK_CACHE_FINALITY_DEPTH"
+
+	// DefaultBlockCacheFinalityDepth is the default number of
+	// blocks a block must be below the chain head before it
+	// is cached. This is used when BlockCacheFinalityDepthEnv
+	// is not populated.
+	DefaultBlockCacheFinalityDepth = 64
+
+	// MetricsEnv is an optional environment variable
+	// that serves Prometheus metrics at /metrics when
+	// set to true. When not set, defaults to false.
+	MetricsEnv = "METRICS"
+
+	// TraceAPIEnv is an optional environment variable
+	// that selects the API used to trace blocks and
+	// transactions. When not set, defaults to DEBUG.
+	TraceAPIEnv = "TRACE_API"
+
+	// TracerEnv is an optional environment variable that
+	// selects the tracer used with the DEBUG TRACE_API
+	// (NATIVE or JS). When not set, defaults to JS if
+	// JSTracerEnv is populated and NATIVE otherwise.
+	TracerEnv = "TRACER"
+
+	// JSTracerEnv is an optional environment variable
+	// pointing to a JavaScript tracer file used instead of
+	// geth's native callTracer. It must be populated to use
+	// the JS TRACER.
+	JSTracerEnv = "JS_TRACER"
+
+	// CallTracerOnlyTopCallEnv is an optional environment
+	// variable that skips tracing internal calls with the
+	// NATIVE TRACER when set to true. When not set, defaults
+	// to false.
+	CallTracerOnlyTopCallEnv = "CALL_TRACER_ONLY_TOP_CALL"
+
+	// CallTracerWithLogEnv is an optional environment
+	// variable that includes the logs of each call in the
+	// traces of the NATIVE TRACER when set to true. When not
+	// set, defaults to false.
+	CallTracerWithLogEnv = "CALL_TRACER_WITH_LOG"
+
+	// BalanceAPIEnv is an optional environment variable
+	// that selects the API used to fetch account balances.
+	// When not set, defaults to GRAPHQL.
+	BalanceAPIEnv = "BALANCE_API"
+
+	// BlockTransactionLimitEnv is an optional environment
+	// variable that sets the maximum number of transactions
+	// returned in /block. The transactions of larger blocks
+	// are returned as other_transactions to be fetched with
+	// /block/transaction. When not set, all transactions
+	// are returned.
+	BlockTransactionLimitEnv = "BLOCK_TRANSACTION_LIMIT"
+
+	// IncludeZeroValueCallsEnv is an optional environment
+	// variable that includes operations for calls that do
+	// not transfer any value (i.e. DELEGATECALL) when set
+	// to true. When not set, defaults to false.
+	IncludeZeroValueCallsEnv = "INCLUDE_ZERO_VALUE_CALLS"
+
+	// AccountFactoryEnv is an optional environment variable
+	// with the address of an ERC-4337 account factory. When set,
+	// construction creates user operations sent by the smart
+	// accounts of this factory instead of transactions. When not
+	// set, construction creates transactions.
+	AccountFactoryEnv = "ACCOUNT_FACTORY"
+
+	// EntryPointEnv is an optional environment variable with
+	// the address of the ERC-4337 EntryPoint user operations are
+	// sent to. When not set, defaults to ethereum.DefaultEntryPoint.
+	EntryPointEnv = "ENTRY_POINT"
+
+	// AccountImplementationEnv is an optional environment variable
+	// with the address of the smart account implementation the
+	// proxies created by the account factory point to. It must be
+	// populated in offline mode when AccountFactoryEnv is populated.
+	AccountImplementationEnv = "ACCOUNT_IMPLEMENTATION"
+
+	// AccountProxyCodeEnv is an optional environment variable
+	// pointing to a file with the hex creation code of the proxy
+	// the account factory deploys (i.e. ERC1967Proxy). It must be
+	// populated with AccountImplementationEnv.
+	AccountProxyCodeEnv = "ACCOUNT_PROXY_CODE"
+
+	// BundlerURLEnv is an optional environment variable with
+	// the URL of the ERC-4337 bundler used to estimate and submit
+	// user operations. It must be populated in online mode when
+	// AccountFactoryEnv is populated.
+	BundlerURLEnv = "BUNDLER_URL"
+
 	// MiddlewareVersion is the version of rosetta-ethereum.
 	MiddlewareVersion = "0.0.4"
 )
@@ -96,15 +224,260 @@ type Configuration struct {
 	Network                *types.NetworkIdentifier
 	GenesisBlockIdentifier *types.BlockIdentifier
 	GethURL                string
+	GethFailoverURLs       []string
 	RemoteGeth             bool
 	Port                   int
 	GethArguments          string
 	SkipGethAdmin          bool
+	TraceAPI               ethereum.TraceAPI
+	Tracer                 ethereum.Tracer
+	JSTracer               string
+	BalanceAPI             ethereum.BalanceAPI
+	Tokens                 *ethereum.TokenRegistry
+	Events                 *ethereum.EventRegistry
+
+	// Block Cache (disabled if BlockCacheDir is empty)
+	BlockCacheDir           string
+	BlockCacheFinalityDepth uint64
+
+	// Metrics (served at /metrics if enabled)
+	Metrics bool
+
+	// Block Transaction Limit (disabled if 0)
+	BlockTransactionLimit int
+
+	// Zero-Value Call Operations (skipped if disabled)
+	IncludeZeroValueCalls bool
+
+	// Native callTracer Config (defaults if nil)
+	CallTracer *ethereum.CallTracerConfig
+
+	// Account Abstraction (disabled if nil)
+	AccountAbstraction *AccountAbstraction
 
 	// Block Reward Data
 	Params *params.ChainConfig
 }
 
+// AccountAbstraction configures the construction of ERC-4337
+// user operations sent by the smart accounts of AccountFactory.
+type AccountAbstraction struct {
+	EntryPoint     common.Address
+	AccountFactory common.Address
+	BundlerURL     string
+
+	// Smart account addresses are computed offline
+	// if AccountProxyCode is populated.
+	AccountImplementation common.Address
+	AccountProxyCode      []byte
+}
+
+// NetworkConfig defines a network that is not known by go-ethereum
+// (i.e. a private devnet). It is loaded from the file at
+// NetworkConfigEnv when NetworkEnv is Custom.
+type NetworkConfig struct {
+	Network                string                 `json:"network"`
+	GenesisBlockIdentifier *types.BlockIdentifier `json:"genesis_block_identifier"`
+	ChainConfig            *params.ChainConfig    `json:"chain_config"`
+	GethArguments          string                 `json:"geth_arguments"`
+}
+
+// LoadNetworkConfig loads and validates the
+// *NetworkConfig in the file at path.
+func LoadNetworkConfig(path string) (*NetworkConfig, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not load network config", err)
+	}
+
+	var networkConfig NetworkConfig
+	if err := json.Unmarshal(contents, &networkConfig); err != nil {
+		return nil, fmt.Errorf("%w: could not parse network config", err)
+	}
+
+	if len(networkConfig.Network) == 0 {
+		return nil, errors.New("network must be populated")
+	}
+
+	if networkConfig.GenesisBlockIdentifier == nil {
+		return nil, errors.New("genesis_block_identifier must be populated")
+	}
+
+	if networkConfig.ChainConfig == nil || networkConfig.ChainConfig.ChainID == nil {
+		return nil, errors.New("chain_config must be populated with a chainId")
+	}
+
+	return &networkConfig, nil
+}
+
+// withTerminalTotalDifficulty returns a copy of the *params.ChainConfig
+// with the terminal total difficulty populated, if it is not already
+// populated by go-ethereum. The terminal total difficulty is used to
+// determine which blocks were produced after the Merge.
+func withTerminalTotalDifficulty(
+	chainConfig *params.ChainConfig,
+	terminalTotalDifficulty *big.Int,
+) *params.ChainConfig {
+	if chainConfig.TerminalTotalDifficulty != nil {
+		return chainConfig
+	}
+
+	withTTD := *chainConfig
+	withTTD.TerminalTotalDifficulty = terminalTotalDifficulty
+	return &withTTD
+}
+
+// TraceConfig returns the *ethereum.TraceConfig
+// of the Tracer used with the DebugTraceAPI.
+func (c *Configuration) TraceConfig() (*ethereum.TraceConfig, error) {
+	if c.Tracer == ethereum.JSTracer {
+		traceConfig, err := ethereum.LoadJSTraceConfig(c.JSTracer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot load JS_TRACER %s", err, c.JSTracer)
+		}
+
+		return traceConfig, nil
+	}
+
+	return ethereum.NativeTraceConfig(c.CallTracer), nil
+}
+
+// loadTracer populates the Tracer of config
+// using the ENVs in the environment.
+func loadTracer(config *Configuration) error {
+	config.Tracer = ethereum.NativeTracer
+	if len(config.JSTracer) > 0 {
+		config.Tracer = ethereum.JSTracer
+	}
+
+	envTracer := os.Getenv(TracerEnv)
+	if len(envTracer) > 0 {
+		switch tracer := ethereum.Tracer(envTracer); tracer {
+		case ethereum.NativeTracer:
+			if len(config.JSTracer) > 0 {
+				return errors.New("JS_TRACER cannot be used with the NATIVE TRACER")
+			}
+			config.Tracer = tracer
+		case ethereum.JSTracer:
+			if len(config.JSTracer) == 0 {
+				return errors.New("JS_TRACER must be populated to use the JS TRACER")
+			}
+			config.Tracer = tracer
+		default:
+			return fmt.Errorf("%s is not a valid TRACER", envTracer)
+		}
+	}
+
+	var callTracer ethereum.CallTracerConfig
+	envOnlyTopCall := os.Getenv(CallTracerOnlyTopCallEnv)
+	if len(envOnlyTopCall) > 0 {
+		val, err := strconv.ParseBool(envOnlyTopCall)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse CALL_TRACER_ONLY_TOP_CALL %s", err, envOnlyTopCall)
+		}
+		callTracer.OnlyTopCall = val
+	}
+
+	envWithLog := os.Getenv(CallTracerWithLogEnv)
+	if len(envWithLog) > 0 {
+		val, err := strconv.ParseBool(envWithLog)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse CALL_TRACER_WITH_LOG %s", err, envWithLog)
+		}
+		callTracer.WithLog = val
+	}
+
+	if callTracer.OnlyTopCall || callTracer.WithLog {
+		if config.Tracer != ethereum.NativeTracer {
+			return errors.New("CALL_TRACER_ONLY_TOP_CALL and CALL_TRACER_WITH_LOG require the NATIVE TRACER")
+		}
+		config.CallTracer = &callTracer
+	}
+
+	return nil
+}
+
+// loadAccountAbstraction creates an *AccountAbstraction
+// for the account factory at envAccountFactory using the
+// ENVs in the environment.
+func loadAccountAbstraction(mode Mode, envAccountFactory string) (*AccountAbstraction, error) {
+	accountFactory, ok := ethereum.ChecksumAddress(envAccountFactory)
+	if !ok {
+		return nil, fmt.Errorf("ACCOUNT_FACTORY %s is not a valid address", envAccountFactory)
+	}
+
+	entryPoint := ethereum.DefaultEntryPoint
+	envEntryPoint := os.Getenv(EntryPointEnv)
+	if len(envEntryPoint) > 0 {
+		entryPoint, ok = ethereum.ChecksumAddress(envEntryPoint)
+		if !ok {
+			return nil, fmt.Errorf("ENTRY_POINT %s is not a valid address", envEntryPoint)
+		}
+	}
+
+	// User operations are estimated and submitted
+	// with a bundler instead of geth.
+	bundlerURL := os.Getenv(BundlerURLEnv)
+	if mode == Online && len(bundlerURL) == 0 {
+		return nil, errors.New("BUNDLER_URL must be populated to use ACCOUNT_FACTORY")
+	}
+
+	accountAbstraction := &AccountAbstraction{
+		EntryPoint:     common.HexToAddress(entryPoint),
+		AccountFactory: common.HexToAddress(accountFactory),
+		BundlerURL:     bundlerURL,
+	}
+
+	// Smart account addresses can only be fetched from
+	// the factory online, so they are computed from the
+	// proxy code of the factory offline.
+	envImplementation := os.Getenv(AccountImplementationEnv)
+	envProxyCode := os.Getenv(AccountProxyCodeEnv)
+	if len(envImplementation) == 0 && len(envProxyCode) == 0 {
+		if mode == Offline {
+			return nil, errors.New(
+				"ACCOUNT_IMPLEMENTATION and ACCOUNT_PROXY_CODE must be populated to use ACCOUNT_FACTORY offline",
+			)
+		}
+
+		return accountAbstraction, nil
+	}
+
+	if len(envImplementation) == 0 || len(envProxyCode) == 0 {
+		return nil, errors.New("ACCOUNT_IMPLEMENTATION and ACCOUNT_PROXY_CODE must be populated together")
+	}
+
+	implementation, ok := ethereum.ChecksumAddress(envImplementation)
+	if !ok {
+		return nil, fmt.Errorf("ACCOUNT_IMPLEMENTATION %s is not a valid address", envImplementation)
+	}
+	accountAbstraction.AccountImplementation = common.HexToAddress(implementation)
+
+	proxyCode, err := loadProxyCode(envProxyCode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load ACCOUNT_PROXY_CODE %s", err, envProxyCode)
+	}
+	accountAbstraction.AccountProxyCode = proxyCode
+
+	return accountAbstraction, nil
+}
+
+// loadProxyCode loads the hex creation
+// code in the file at path.
+func loadProxyCode(path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not load proxy code", err)
+	}
+
+	proxyCode, err := hexutil.Decode(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not decode proxy code", err)
+	}
+
+	return proxyCode, nil
+}
+
 // LoadConfiguration attempts to create a new Configuration
 // using the ENVs in the environment.
 func LoadConfiguration() (*Configuration, error) {
@@ -130,7 +503,10 @@ func LoadConfiguration() (*Configuration, error) {
 			Network:    ethereum.MainnetNetwork,
 		}
 		config.GenesisBlockIdentifier = ethereum.MainnetGenesisBlockIdentifier
-		config.Params = params.MainnetChainConfig
+		config.Params = withTerminalTotalDifficulty(
+			params.MainnetChainConfig,
+			ethereum.MainnetTerminalTotalDifficulty,
+		)
 		config.GethArguments = ethereum.MainnetGethArguments
 	case Ropsten:
 		config.Network = &types.NetworkIdentifier{
@@ -154,7 +530,10 @@ func LoadConfiguration() (*Configuration, error) {
 			Network:    ethereum.GoerliNetwork,
 		}
 		config.GenesisBlockIdentifier = ethereum.GoerliGenesisBlockIdentifier
-		config.Params = params.GoerliChainConfig
+		config.Params = withTerminalTotalDifficulty(
+			params.GoerliChainConfig,
+			ethereum.GoerliTerminalTotalDifficulty,
+		)
 		config.GethArguments = ethereum.GoerliGethArguments
 	case Testnet:
 		config.Network = &types.NetworkIdentifier{
@@ -164,6 +543,24 @@ func LoadConfiguration() (*Configuration, error) {
 		config.GenesisBlockIdentifier = nil
 		config.Params = params.AllCliqueProtocolChanges
 		config.GethArguments = ethereum.DevGethArguments
+	case Custom:
+		networkConfigPath := os.Getenv(NetworkConfigEnv)
+		if len(networkConfigPath) == 0 {
+			return nil, errors.New("NETWORK_CONFIG must be populated for a CUSTOM network")
+		}
+
+		networkConfig, err := LoadNetworkConfig(networkConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load NETWORK_CONFIG %s", err, networkConfigPath)
+		}
+
+		config.Network = &types.NetworkIdentifier{
+			Blockchain: ethereum.Blockchain,
+			Network:    networkConfig.Network,
+		}
+		config.GenesisBlockIdentifier = networkConfig.GenesisBlockIdentifier
+		config.Params = networkConfig.ChainConfig
+		config.GethArguments = networkConfig.GethArguments
 	case "":
 		return nil, errors.New("NETWORK must be populated")
 	default:
@@ -174,7 +571,25 @@ func LoadConfiguration() (*Configuration, error) {
 	envGethURL := os.Getenv(GethEnv)
 	if len(envGethURL) > 0 {
 		config.RemoteGeth = true
-		config.GethURL = envGethURL
+
+		urls := strings.Split(envGethURL, ",")
+		for i, url := range urls {
+			urls[i] = strings.TrimSpace(url)
+			if len(urls[i]) == 0 {
+				return nil, fmt.Errorf("GETH %s contains an empty url", envGethURL)
+			}
+		}
+
+		config.GethURL = urls[0]
+		if len(urls) > 1 {
+			config.GethFailoverURLs = urls[1:]
+		}
+	}
+
+	// geth would otherwise start on mainnet for a
+	// custom network without any arguments.
+	if config.Mode == Online && !config.RemoteGeth && len(config.GethArguments) == 0 {
+		return nil, errors.New("geth_arguments must be populated in NETWORK_CONFIG to start geth")
 	}
 
 	config.SkipGethAdmin = false
@@ -187,6 +602,123 @@ func LoadConfiguration() (*Configuration, error) {
 		config.SkipGethAdmin = val
 	}
 
+	config.TraceAPI = ethereum.DebugTraceAPI
+	envTraceAPI := os.Getenv(TraceAPIEnv)
+	if len(envTraceAPI) > 0 {
+		switch traceAPI := ethereum.TraceAPI(envTraceAPI); traceAPI {
+		case ethereum.DebugTraceAPI:
+		case ethereum.ParityTraceAPI:
+			// geth does not support trace_block, so a
+			// remote node must be used.
+			if config.Mode == Online && !config.RemoteGeth {
+				return nil, errors.New("GETH must be populated to use the PARITY TRACE_API")
+			}
+			config.TraceAPI = traceAPI
+		default:
+			return nil, fmt.Errorf("%s is not a valid TRACE_API", envTraceAPI)
+		}
+	}
+
+	config.JSTracer = os.Getenv(JSTracerEnv)
+	if err := loadTracer(config); err != nil {
+		return nil, err
+	}
+
+	config.BalanceAPI = ethereum.GraphQLBalanceAPI
+	envBalanceAPI := os.Getenv(BalanceAPIEnv)
+	if len(envBalanceAPI) > 0 {
+		switch balanceAPI := ethereum.BalanceAPI(envBalanceAPI); balanceAPI {
+		case ethereum.GraphQLBalanceAPI, ethereum.RPCBalanceAPI:
+			config.BalanceAPI = balanceAPI
+		default:
+			return nil, fmt.Errorf("%s is not a valid BALANCE_API", envBalanceAPI)
+		}
+	}
+
+	envTokenList := os.Getenv(TokenListEnv)
+	if len(envTokenList) > 0 {
+		tokens, err := ethereum.LoadTokenRegistry(envTokenList)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load TOKEN_LIST %s", err, envTokenList)
+		}
+		config.Tokens = tokens
+	}
+
+	envABIDir := os.Getenv(ABIDirEnv)
+	if len(envABIDir) > 0 {
+		events, err := ethereum.LoadEventRegistry(envABIDir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load ABI_DIR %s", err, envABIDir)
+		}
+		config.Events = events
+	}
+
+	envBlockCacheDir := os.Getenv(BlockCacheDirEnv)
+	if len(envBlockCacheDir) > 0 {
+		config.BlockCacheDir = envBlockCacheDir
+		config.BlockCacheFinalityDepth = DefaultBlockCacheFinalityDepth
+
+		envFinalityDepth := os.Getenv(BlockCacheFinalityDepthEnv)
+		if len(envFinalityDepth) > 0 {
+			depth, err := strconv.ParseUint(envFinalityDepth, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w: unable to parse BLOCK_CACHE_FINALITY_DEPTH %s",
+					err,
+					envFinalityDepth,
+				)
+			}
+			config.BlockCacheFinalityDepth = depth
+		}
+	}
+
+	envMetrics := os.Getenv(MetricsEnv)
+	if len(envMetrics) > 0 {
+		val, err := strconv.ParseBool(envMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse METRICS %s", err, envMetrics)
+		}
+		config.Metrics = val
+	}
+
+	envBlockTransactionLimit := os.Getenv(BlockTransactionLimitEnv)
+	if len(envBlockTransactionLimit) > 0 {
+		limit, err := strconv.Atoi(envBlockTransactionLimit)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse BLOCK_TRANSACTION_LIMIT %s",
+				err,
+				envBlockTransactionLimit,
+			)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("BLOCK_TRANSACTION_LIMIT %d cannot be negative", limit)
+		}
+		config.BlockTransactionLimit = limit
+	}
+
+	envIncludeZeroValueCalls := os.Getenv(IncludeZeroValueCallsEnv)
+	if len(envIncludeZeroValueCalls) > 0 {
+		val, err := strconv.ParseBool(envIncludeZeroValueCalls)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse INCLUDE_ZERO_VALUE_CALLS %s",
+				err,
+				envIncludeZeroValueCalls,
+			)
+		}
+		config.IncludeZeroValueCalls = val
+	}
+
+	envAccountFactory := os.Getenv(AccountFactoryEnv)
+	if len(envAccountFactory) > 0 {
+		accountAbstraction, err := loadAccountAbstraction(config.Mode, envAccountFactory)
+		if err != nil {
+			return nil, err
+		}
+		config.AccountAbstraction = accountAbstraction
+	}
+
 	portValue := os.Getenv(PortEnv)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
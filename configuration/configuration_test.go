@@ -16,23 +16,45 @@ package configuration
 
 import (
 	"errors"
+	"math/big"
 	"os"
 	"testing"
 
 	"github.com/coinbase/rosetta-ethereum/ethereum"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadConfiguration(t *testing.T) {
 	tests := map[string]struct {
-		Mode          string
-		Network       string
-		Port          string
-		Geth          string
-		SkipGethAdmin string
+		Mode           string
+		Network        string
+		NetworkConfig  string
+		Port           string
+		Geth           string
+		SkipGethAdmin  string
+		TokenList      string
+		ABIDir         string
+		BlockCache     string
+		FinalityDepth  string
+		Metrics        string
+		TraceAPI       string
+		Tracer         string
+		JSTracer       string
+		OnlyTopCall    string
+		WithLog        string
+		BalanceAPI     string
+		TxLimit        string
+		ZeroValue      string
+		Factory        string
+		EntryPoint     string
+		Bundler        string
+		Implementation string
+		ProxyCode      string
 
 		cfg *Configuration
 		err error
@@ -60,11 +82,17 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    ethereum.MainnetNetwork,
 					Blockchain: ethereum.Blockchain,
 				},
-				Params:                 params.MainnetChainConfig,
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
 				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 				SkipGethAdmin:          false,
 			},
 		},
@@ -80,12 +108,18 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    ethereum.MainnetNetwork,
 					Blockchain: ethereum.Blockchain,
 				},
-				Params:                 params.MainnetChainConfig,
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
 				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                "http://blah",
 				RemoteGeth:             true,
 				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 				SkipGethAdmin:          true,
 			},
 		},
@@ -104,6 +138,9 @@ func TestLoadConfiguration(t *testing.T) {
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          ethereum.RopstenGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 			},
 		},
 		"all set (rinkeby)": {
@@ -121,6 +158,9 @@ func TestLoadConfiguration(t *testing.T) {
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          ethereum.RinkebyGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 			},
 		},
 		"all set (goerli)": {
@@ -133,11 +173,17 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    ethereum.GoerliNetwork,
 					Blockchain: ethereum.Blockchain,
 				},
-				Params:                 params.GoerliChainConfig,
+				Params: withTerminalTotalDifficulty(
+					params.GoerliChainConfig,
+					ethereum.GoerliTerminalTotalDifficulty,
+				),
 				GenesisBlockIdentifier: ethereum.GoerliGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          ethereum.GoerliGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 			},
 		},
 		"all set (testnet)": {
@@ -156,9 +202,208 @@ func TestLoadConfiguration(t *testing.T) {
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          ethereum.DevGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
 				SkipGethAdmin:          true,
 			},
 		},
+		"all set (mainnet) + failover": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Geth:    "http://node-1:8545, http://node-2:8545,http://node-3:8545",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                "http://node-1:8545",
+				GethFailoverURLs:       []string{"http://node-2:8545", "http://node-3:8545"},
+				RemoteGeth:             true,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+			},
+		},
+		"invalid geth urls": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Geth:    "http://node-1:8545,,http://node-2:8545",
+			err:     errors.New("GETH http://node-1:8545,,http://node-2:8545 contains an empty url"),
+		},
+		"all set (mainnet) + parity traces": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			Geth:     "http://erigon:8545",
+			TraceAPI: "PARITY",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                "http://erigon:8545",
+				RemoteGeth:             true,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.ParityTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+			},
+		},
+		"all set (mainnet) + js tracer": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			JSTracer: "/app/ethereum/call_tracer.js",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.JSTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				JSTracer:               "/app/ethereum/call_tracer.js",
+			},
+		},
+		"all set (mainnet) + native tracer config": {
+			Mode:        string(Online),
+			Network:     Mainnet,
+			Port:        "1000",
+			Tracer:      "NATIVE",
+			OnlyTopCall: "true",
+			WithLog:     "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				CallTracer: &ethereum.CallTracerConfig{
+					OnlyTopCall: true,
+					WithLog:     true,
+				},
+			},
+		},
+		"invalid tracer": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Tracer:  "PRESTATE",
+			err:     errors.New("PRESTATE is not a valid TRACER"),
+		},
+		"js tracer without file": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Tracer:  "JS",
+			err:     errors.New("JS_TRACER must be populated to use the JS TRACER"),
+		},
+		"native tracer with js file": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			Tracer:   "NATIVE",
+			JSTracer: "/app/ethereum/call_tracer.js",
+			err:      errors.New("JS_TRACER cannot be used with the NATIVE TRACER"),
+		},
+		"native tracer config with js tracer": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			JSTracer: "/app/ethereum/call_tracer.js",
+			WithLog:  "true",
+			err:      errors.New("CALL_TRACER_ONLY_TOP_CALL and CALL_TRACER_WITH_LOG require the NATIVE TRACER"),
+		},
+		"invalid only top call": {
+			Mode:        string(Online),
+			Network:     Mainnet,
+			Port:        "1000",
+			OnlyTopCall: "bad",
+			err:         errors.New("unable to parse CALL_TRACER_ONLY_TOP_CALL bad"),
+		},
+		"all set (mainnet) + rpc balances": {
+			Mode:       string(Online),
+			Network:    Mainnet,
+			Port:       "1000",
+			BalanceAPI: "RPC",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.RPCBalanceAPI,
+			},
+		},
+		"invalid balance api": {
+			Mode:       string(Online),
+			Network:    Mainnet,
+			Port:       "1000",
+			BalanceAPI: "bad",
+			err:        errors.New("bad is not a valid BALANCE_API"),
+		},
+		"parity traces without remote node": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			TraceAPI: "PARITY",
+			err:      errors.New("GETH must be populated to use the PARITY TRACE_API"),
+		},
+		"invalid trace api": {
+			Mode:     string(Online),
+			Network:  Mainnet,
+			Port:     "1000",
+			TraceAPI: "bad",
+			err:      errors.New("bad is not a valid TRACE_API"),
+		},
 		"invalid mode": {
 			Mode:    "bad mode",
 			Network: Ropsten,
@@ -177,15 +422,307 @@ func TestLoadConfiguration(t *testing.T) {
 			Port:    "bad port",
 			err:     errors.New("unable to parse port bad port"),
 		},
+		"all set (mainnet) + block cache": {
+			Mode:          string(Online),
+			Network:       Mainnet,
+			Port:          "1000",
+			BlockCache:    "/data/cache",
+			FinalityDepth: "128",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier:  ethereum.MainnetGenesisBlockIdentifier,
+				Port:                    1000,
+				GethURL:                 DefaultGethURL,
+				GethArguments:           ethereum.MainnetGethArguments,
+				TraceAPI:                ethereum.DebugTraceAPI,
+				Tracer:                  ethereum.NativeTracer,
+				BalanceAPI:              ethereum.GraphQLBalanceAPI,
+				BlockCacheDir:           "/data/cache",
+				BlockCacheFinalityDepth: 128,
+			},
+		},
+		"invalid block cache finality depth": {
+			Mode:          string(Online),
+			Network:       Mainnet,
+			Port:          "1000",
+			BlockCache:    "/data/cache",
+			FinalityDepth: "-1",
+			err:           errors.New("unable to parse BLOCK_CACHE_FINALITY_DEPTH -1"),
+		},
+		"all set (mainnet) + metrics": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Metrics: "TRUE",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				Tracer:                 ethereum.NativeTracer,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				Metrics:                true,
+			},
+		},
+		"all set (mainnet) + block transaction limit": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			TxLimit: "500",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				BlockTransactionLimit:  500,
+			},
+		},
+		"invalid block transaction limit": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			TxLimit: "-1",
+			err:     errors.New("BLOCK_TRANSACTION_LIMIT -1 cannot be negative"),
+		},
+		"all set (mainnet) + zero-value calls": {
+			Mode:      string(Online),
+			Network:   Mainnet,
+			Port:      "1000",
+			ZeroValue: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				IncludeZeroValueCalls:  true,
+			},
+		},
+		"invalid zero-value calls": {
+			Mode:      string(Online),
+			Network:   Mainnet,
+			Port:      "1000",
+			ZeroValue: "bad",
+			err:       errors.New("unable to parse INCLUDE_ZERO_VALUE_CALLS bad"),
+		},
+		"account abstraction": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Factory: "0x9406Cc6185a346906296840746125a0E44976454",
+			Bundler: "http://bundler:4337",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				AccountAbstraction: &AccountAbstraction{
+					EntryPoint:     common.HexToAddress(ethereum.DefaultEntryPoint),
+					AccountFactory: common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454"),
+					BundlerURL:     "http://bundler:4337",
+				},
+			},
+		},
+		"account abstraction (offline with entry point)": {
+			Mode:           string(Offline),
+			Network:        Mainnet,
+			Port:           "1000",
+			Factory:        "0x9406Cc6185a346906296840746125a0E44976454",
+			EntryPoint:     "0x0000000071727De22E5E9d8BAf0edAc6f37da032",
+			Implementation: "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+			ProxyCode:      "testdata/account_proxy_code.hex",
+			cfg: &Configuration{
+				Mode: Offline,
+				Network: &types.NetworkIdentifier{
+					Network:    ethereum.MainnetNetwork,
+					Blockchain: ethereum.Blockchain,
+				},
+				Params: withTerminalTotalDifficulty(
+					params.MainnetChainConfig,
+					ethereum.MainnetTerminalTotalDifficulty,
+				),
+				GenesisBlockIdentifier: ethereum.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          ethereum.MainnetGethArguments,
+				TraceAPI:               ethereum.DebugTraceAPI,
+				BalanceAPI:             ethereum.GraphQLBalanceAPI,
+				AccountAbstraction: &AccountAbstraction{
+					EntryPoint:     common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032"),
+					AccountFactory: common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454"),
+					AccountImplementation: common.HexToAddress(
+						"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+					),
+					AccountProxyCode: hexutil.MustDecode(
+						"0x60806040526040516100b83803806100b8833981016040819052610022916100a1565b600080546001600160a01b0319166001600160a01b039390931692909217909155005b", // nolint
+					),
+				},
+			},
+		},
+		"account abstraction offline without proxy code": {
+			Mode:    string(Offline),
+			Network: Mainnet,
+			Port:    "1000",
+			Factory: "0x9406Cc6185a346906296840746125a0E44976454",
+			err: errors.New(
+				"ACCOUNT_IMPLEMENTATION and ACCOUNT_PROXY_CODE must be populated to use ACCOUNT_FACTORY offline",
+			),
+		},
+		"account abstraction without implementation": {
+			Mode:      string(Online),
+			Network:   Mainnet,
+			Port:      "1000",
+			Factory:   "0x9406Cc6185a346906296840746125a0E44976454",
+			Bundler:   "http://bundler:4337",
+			ProxyCode: "testdata/account_proxy_code.hex",
+			err:       errors.New("ACCOUNT_IMPLEMENTATION and ACCOUNT_PROXY_CODE must be populated together"),
+		},
+		"invalid account proxy code": {
+			Mode:           string(Online),
+			Network:        Mainnet,
+			Port:           "1000",
+			Factory:        "0x9406Cc6185a346906296840746125a0E44976454",
+			Bundler:        "http://bundler:4337",
+			Implementation: "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+			ProxyCode:      "testdata/sepolia.json",
+			err:            errors.New("unable to load ACCOUNT_PROXY_CODE testdata/sepolia.json"),
+		},
+		"account abstraction without bundler": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Factory: "0x9406Cc6185a346906296840746125a0E44976454",
+			err:     errors.New("BUNDLER_URL must be populated to use ACCOUNT_FACTORY"),
+		},
+		"invalid account factory": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Factory: "bad",
+			Bundler: "http://bundler:4337",
+			err:     errors.New("ACCOUNT_FACTORY bad is not a valid address"),
+		},
+		"invalid entry point": {
+			Mode:       string(Online),
+			Network:    Mainnet,
+			Port:       "1000",
+			Factory:    "0x9406Cc6185a346906296840746125a0E44976454",
+			EntryPoint: "bad",
+			Bundler:    "http://bundler:4337",
+			err:        errors.New("ENTRY_POINT bad is not a valid address"),
+		},
+		"invalid metrics": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			Metrics: "bad",
+			err:     errors.New("unable to parse METRICS bad"),
+		},
+		"custom network without network config": {
+			Mode:    string(Online),
+			Network: Custom,
+			Port:    "1000",
+			err:     errors.New("NETWORK_CONFIG must be populated for a CUSTOM network"),
+		},
+		"custom network without chain id": {
+			Mode:          string(Online),
+			Network:       Custom,
+			NetworkConfig: "testdata/invalid_network.json",
+			Port:          "1000",
+			err:           errors.New("chain_config must be populated with a chainId"),
+		},
+		"invalid token list": {
+			Mode:      string(Online),
+			Network:   Mainnet,
+			Port:      "1000",
+			TokenList: "missing_tokens.json",
+			err:       errors.New("unable to load TOKEN_LIST missing_tokens.json"),
+		},
+		"invalid abi dir": {
+			Mode:    string(Online),
+			Network: Mainnet,
+			Port:    "1000",
+			ABIDir:  "missing_abis",
+			err:     errors.New("unable to load ABI_DIR missing_abis"),
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			os.Setenv(ModeEnv, test.Mode)
 			os.Setenv(NetworkEnv, test.Network)
+			os.Setenv(NetworkConfigEnv, test.NetworkConfig)
 			os.Setenv(PortEnv, test.Port)
 			os.Setenv(GethEnv, test.Geth)
 			os.Setenv(SkipGethAdminEnv, test.SkipGethAdmin)
+			os.Setenv(TokenListEnv, test.TokenList)
+			os.Setenv(ABIDirEnv, test.ABIDir)
+			os.Setenv(BlockCacheDirEnv, test.BlockCache)
+			os.Setenv(BlockCacheFinalityDepthEnv, test.FinalityDepth)
+			os.Setenv(MetricsEnv, test.Metrics)
+			os.Setenv(TraceAPIEnv, test.TraceAPI)
+			os.Setenv(TracerEnv, test.Tracer)
+			os.Setenv(JSTracerEnv, test.JSTracer)
+			os.Setenv(CallTracerOnlyTopCallEnv, test.OnlyTopCall)
+			os.Setenv(CallTracerWithLogEnv, test.WithLog)
+			os.Setenv(BalanceAPIEnv, test.BalanceAPI)
+			os.Setenv(BlockTransactionLimitEnv, test.TxLimit)
+			os.Setenv(IncludeZeroValueCallsEnv, test.ZeroValue)
+			os.Setenv(AccountFactoryEnv, test.Factory)
+			os.Setenv(EntryPointEnv, test.EntryPoint)
+			os.Setenv(BundlerURLEnv, test.Bundler)
+			os.Setenv(AccountImplementationEnv, test.Implementation)
+			os.Setenv(AccountProxyCodeEnv, test.ProxyCode)
 
 			cfg, err := LoadConfiguration()
 			if test.err != nil {
@@ -198,3 +735,117 @@ func TestLoadConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestConfiguration_TraceConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfg *Configuration
+
+		expectedTraceConfig *ethereum.TraceConfig
+		err                 error
+	}{
+		"native": {
+			cfg: &Configuration{
+				Tracer: ethereum.NativeTracer,
+			},
+			expectedTraceConfig: ethereum.NativeTraceConfig(nil),
+		},
+		"native with config": {
+			cfg: &Configuration{
+				Tracer: ethereum.NativeTracer,
+				CallTracer: &ethereum.CallTracerConfig{
+					OnlyTopCall: true,
+					WithLog:     true,
+				},
+			},
+			expectedTraceConfig: ethereum.NativeTraceConfig(&ethereum.CallTracerConfig{
+				OnlyTopCall: true,
+				WithLog:     true,
+			}),
+		},
+		"js": {
+			cfg: &Configuration{
+				Tracer:   ethereum.JSTracer,
+				JSTracer: "../ethereum/call_tracer.js",
+			},
+		},
+		"missing js": {
+			cfg: &Configuration{
+				Tracer:   ethereum.JSTracer,
+				JSTracer: "missing_tracer.js",
+			},
+			err: errors.New("cannot load JS_TRACER missing_tracer.js"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			traceConfig, err := test.cfg.TraceConfig()
+			if test.err != nil {
+				assert.Nil(t, traceConfig)
+				assert.Contains(t, err.Error(), test.err.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			if test.expectedTraceConfig != nil {
+				assert.Equal(t, test.expectedTraceConfig, traceConfig)
+				return
+			}
+
+			// The JS tracer is loaded from the file
+			// without a config for the native tracer
+			expectedTraceConfig, err := ethereum.LoadJSTraceConfig(test.cfg.JSTracer)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedTraceConfig, traceConfig)
+			assert.Nil(t, traceConfig.TracerConfig)
+			assert.NotEqual(t, "callTracer", traceConfig.Tracer)
+		})
+	}
+}
+
+func TestLoadConfiguration_CustomNetwork(t *testing.T) {
+	os.Setenv(ModeEnv, string(Online))
+	os.Setenv(NetworkEnv, Custom)
+	os.Setenv(NetworkConfigEnv, "testdata/sepolia.json")
+	os.Setenv(PortEnv, "1000")
+	os.Setenv(GethEnv, "")
+	os.Setenv(SkipGethAdminEnv, "")
+	os.Setenv(TokenListEnv, "")
+	os.Setenv(ABIDirEnv, "")
+	os.Setenv(BlockCacheDirEnv, "")
+	os.Setenv(BlockCacheFinalityDepthEnv, "")
+	os.Setenv(MetricsEnv, "")
+	os.Setenv(TraceAPIEnv, "")
+	os.Setenv(TracerEnv, "")
+	os.Setenv(JSTracerEnv, "")
+	os.Setenv(CallTracerOnlyTopCallEnv, "")
+	os.Setenv(CallTracerWithLogEnv, "")
+	os.Setenv(BalanceAPIEnv, "")
+	os.Setenv(BlockTransactionLimitEnv, "")
+	os.Setenv(IncludeZeroValueCallsEnv, "")
+	os.Setenv(AccountFactoryEnv, "")
+	os.Setenv(EntryPointEnv, "")
+	os.Setenv(BundlerURLEnv, "")
+	os.Setenv(AccountImplementationEnv, "")
+	os.Setenv(AccountProxyCodeEnv, "")
+
+	cfg, err := LoadConfiguration()
+	assert.NoError(t, err)
+	assert.Equal(t, &types.NetworkIdentifier{
+		Blockchain: ethereum.Blockchain,
+		Network:    "Sepolia",
+	}, cfg.Network)
+	assert.Equal(t, &types.BlockIdentifier{
+		Index: 0,
+		Hash:  "0x25a5cc106eea7138acab33231d7160d69cb777ee0c2c553fcddf5138993e6dd9",
+	}, cfg.GenesisBlockIdentifier)
+	assert.Equal(t, int64(11155111), cfg.Params.ChainID.Int64())
+	assert.True(t, cfg.Params.IsLondon(big.NewInt(0)))
+	assert.Equal(t, "--config=/app/ethereum/geth.toml --sepolia", cfg.GethArguments)
+	assert.Equal(t, DefaultGethURL, cfg.GethURL)
+
+	os.Setenv(NetworkConfigEnv, "testdata/missing.json")
+	cfg, err = LoadConfiguration()
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), "unable to load NETWORK_CONFIG testdata/missing.json")
+}
@@ -0,0 +1,230 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// blockCacheDirMode is the file mode of the
+	// directory created by FileBlockStorage.
+	blockCacheDirMode = 0700
+
+	// traceKeyLength is the number of bytes of the
+	// hash of the trace settings in each key.
+	traceKeyLength = 8
+)
+
+// BlockStorage persists the cached data of blocks by key. Any
+// key-value store can be used to back a BlockCache by implementing
+// BlockStorage. Keys are the hash of the block followed by a
+// digest of the settings its traces were fetched with.
+type BlockStorage interface {
+	// Get returns the value stored for the key. If no value is
+	// stored, it returns !ok.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores the value for the key.
+	Set(key string, value []byte) error
+}
+
+// cachedBlock is the data of a block that is expensive to
+// fetch from geth.
+type cachedBlock struct {
+	Receipts []*types.Receipt `json:"receipts"`
+	Traces   json.RawMessage  `json:"traces,omitempty"`
+}
+
+// BlockCache caches the receipts and traces of blocks so that
+// they are not fetched from geth again. Only blocks at least
+// finalityDepth blocks below the chain head are cached, so that
+// orphaned blocks are never stored. A nil *BlockCache caches
+// nothing.
+//
+// Traces depend on the API and tracer they are fetched with, so
+// blocks are cached separately for each of them.
+type BlockCache struct {
+	storage       BlockStorage
+	finalityDepth uint64
+	traceKey      string
+}
+
+// NewBlockCache creates a *BlockCache backed by
+// the provided BlockStorage.
+func NewBlockCache(storage BlockStorage, finalityDepth uint64) *BlockCache {
+	return &BlockCache{
+		storage:       storage,
+		finalityDepth: finalityDepth,
+	}
+}
+
+// forTraces returns a copy of the *BlockCache that caches
+// blocks with the traces fetched with the settings of
+// traceKey.
+func (c *BlockCache) forTraces(traceKey string) *BlockCache {
+	if c == nil {
+		return nil
+	}
+
+	cache := *c
+	cache.traceKey = traceKey
+	return &cache
+}
+
+// traceKey returns a digest of the settings that determine the
+// traces of a block. tc is only used with the DebugTraceAPI, and
+// its timeout does not change the traces it returns.
+func traceKey(traceAPI TraceAPI, tc *TraceConfig) string {
+	settings := [][]byte{[]byte(traceAPI)}
+	if traceAPI == DebugTraceAPI && tc != nil {
+		var callTracer CallTracerConfig
+		if tc.TracerConfig != nil {
+			callTracer = *tc.TracerConfig
+		}
+
+		settings = append(
+			settings,
+			[]byte(tc.Tracer),
+			[]byte(strconv.FormatBool(callTracer.OnlyTopCall)),
+			[]byte(strconv.FormatBool(callTracer.WithLog)),
+		)
+	}
+
+	// Each setting is hashed so that the
+	// concatenation is unambiguous.
+	hashes := make([][]byte, len(settings))
+	for i, setting := range settings {
+		hashes[i] = crypto.Keccak256(setting)
+	}
+
+	return hex.EncodeToString(crypto.Keccak256(hashes...)[:traceKeyLength])
+}
+
+// key returns the key of the block with the
+// provided hash in the BlockStorage.
+func (c *BlockCache) key(hash common.Hash) string {
+	return hash.Hex() + "-" + c.traceKey
+}
+
+// get returns the *cachedBlock of the block with the provided
+// hash or nil if the block is not cached.
+func (c *BlockCache) get(hash common.Hash) (*cachedBlock, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	value, ok, err := c.storage.Get(c.key(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	var block cachedBlock
+	if err := json.Unmarshal(value, &block); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode cached block %s", err, hash.Hex())
+	}
+
+	return &block, nil
+}
+
+// final returns a boolean indicating if the block
+// at number can be cached when the chain is at head.
+func (c *BlockCache) final(number uint64, head uint64) bool {
+	if c == nil {
+		return false
+	}
+
+	return number+c.finalityDepth <= head
+}
+
+// set stores the *cachedBlock of the block with
+// the provided hash.
+func (c *BlockCache) set(hash common.Hash, block *cachedBlock) error {
+	value, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	return c.storage.Set(c.key(hash), value)
+}
+
+// FileBlockStorage is a BlockStorage that stores
+// each value in a file in a directory.
+type FileBlockStorage struct {
+	dir string
+}
+
+// NewFileBlockStorage creates a *FileBlockStorage in the
+// provided directory, creating it if it does not exist.
+func NewFileBlockStorage(dir string) (*FileBlockStorage, error) {
+	if err := os.MkdirAll(dir, blockCacheDirMode); err != nil {
+		return nil, fmt.Errorf("%w: unable to create block cache directory", err)
+	}
+
+	return &FileBlockStorage{dir: dir}, nil
+}
+
+// Get returns the value stored for the key.
+func (s *FileBlockStorage) Get(key string) ([]byte, bool, error) {
+	value, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set stores the value for the key. The value is written
+// to a temporary file first so that a partially written
+// file is never read.
+func (s *FileBlockStorage) Set(key string, value []byte) error {
+	tmp, err := ioutil.TempFile(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close() // nolint:errcheck
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *FileBlockStorage) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
@@ -22,6 +22,7 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -32,10 +33,9 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/types"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"golang.org/x/sync/semaphore"
 )
@@ -49,6 +49,10 @@ const (
 	// eip1559TxType is the EthTypes.Transaction.Type() value that indicates this transaction
 	// follows EIP-1559.
 	eip1559TxType = 2
+
+	// withdrawalTransactionSuffix is hashed with the hash of a
+	// block to derive the hash of its withdrawal transaction.
+	withdrawalTransactionSuffix = "withdrawals"
 )
 
 // Client allows for querying a set of specific Ethereum endpoints in an
@@ -58,7 +62,10 @@ const (
 // Client borrows HEAVILY from https://github.com/ethereum/go-ethereum/tree/master/ethclient.
 type Client struct {
 	p  *params.ChainConfig
-	tc *tracers.TraceConfig
+	tc *TraceConfig
+
+	traceAPI   TraceAPI
+	balanceAPI BalanceAPI
 
 	c JSONRPC
 	g GraphQL
@@ -66,33 +73,191 @@ type Client struct {
 	traceSemaphore *semaphore.Weighted
 
 	skipAdminCalls bool
+
+	tokens *TokenRegistry
+	events *EventRegistry
+
+	cache *BlockCache
+
+	// blockTransactionLimit is the number of transactions
+	// above which blocks only include transaction identifiers
+	// (disabled if 0)
+	blockTransactionLimit int
+	recentBlocks          *recentBlocks
+
+	// includeZeroValueCalls adds operations for all
+	// call traces that do not transfer any value
+	includeZeroValueCalls bool
+
+	// bundler is nil when no ERC-4337
+	// bundler is configured
+	bundler JSONRPC
+
+	metrics *Metrics
+
+	// nodes is nil when the Client
+	// is connected to a single node
+	nodes *nodePool
+}
+
+// ClientOptions configures the optional features of a Client.
+// The zero value of each field disables its feature or selects
+// its default.
+type ClientOptions struct {
+	// TraceAPI and TraceConfig select how
+	// block traces are fetched from geth.
+	TraceAPI    TraceAPI
+	TraceConfig *TraceConfig
+
+	// BalanceAPI selects how account
+	// balances are fetched from geth.
+	BalanceAPI BalanceAPI
+
+	// Tokens and Events are the ERC-20 tokens and
+	// contract events parsed from transaction logs.
+	Tokens *TokenRegistry
+	Events *EventRegistry
+
+	// Cache stores the receipts and traces of
+	// finalized blocks when it is not nil.
+	Cache *BlockCache
+
+	// BlockTransactionLimit is the number of transactions
+	// above which blocks only include transaction
+	// identifiers (disabled if 0).
+	BlockTransactionLimit int
+
+	// IncludeZeroValueCalls adds operations for all
+	// call traces that do not transfer any value.
+	IncludeZeroValueCalls bool
+
+	// BundlerURL is the url of the ERC-4337
+	// bundler user operations are sent to.
+	BundlerURL string
+
+	// Metrics records the latency and errors
+	// of geth calls when it is not nil.
+	Metrics *Metrics
+}
+
+// NewClient creates a Client that from the provided urls and params.
+// When multiple urls are provided, calls are load balanced across
+// all healthy nodes and fail over to another node on errors.
+func NewClient(
+	urls []string,
+	params *params.ChainConfig,
+	skipAdminCalls bool,
+	opts *ClientOptions,
+) (*Client, error) {
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+
+	if len(urls) == 0 {
+		return nil, errors.New("at least one node url must be provided")
+	}
+
+	nodes := make([]*node, len(urls))
+	for i, nodeURL := range urls {
+		var err error
+		nodes[i], err = dialNode(nodeURL, opts.Metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var c JSONRPC = nodes[0].c
+	var g GraphQL = nodes[0].g
+	var pool *nodePool
+	if len(nodes) > 1 {
+		pool = newNodePool(nodes)
+		pool.start()
+		c = pool
+		g = pool
+	}
+
+	var recent *recentBlocks
+	if opts.BlockTransactionLimit > 0 {
+		recent = newRecentBlocks()
+	}
+
+	var bundler JSONRPC
+	if len(opts.BundlerURL) > 0 {
+		bundlerClient, err := rpc.DialHTTPWithClient(opts.BundlerURL, &http.Client{
+			Timeout: gethHTTPTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to dial bundler %s", err, opts.BundlerURL)
+		}
+		bundler = bundlerClient
+	}
+
+	return &Client{
+		p:              params,
+		tc:             opts.TraceConfig,
+		traceAPI:       opts.TraceAPI,
+		balanceAPI:     opts.BalanceAPI,
+		c:              c,
+		g:              g,
+		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
+		skipAdminCalls: skipAdminCalls,
+		tokens:         opts.Tokens,
+		events:         opts.Events,
+		cache:          opts.Cache.forTraces(traceKey(opts.TraceAPI, opts.TraceConfig)),
+		bundler:        bundler,
+		metrics:        opts.Metrics,
+		nodes:          pool,
+
+		blockTransactionLimit: opts.BlockTransactionLimit,
+		recentBlocks:          recent,
+		includeZeroValueCalls: opts.IncludeZeroValueCalls,
+	}, nil
 }
 
-// NewClient creates a Client that from the provided url and params.
-func NewClient(url string, params *params.ChainConfig, skipAdminCalls bool) (*Client, error) {
-	c, err := rpc.DialHTTPWithClient(url, &http.Client{
+// dialNode creates a *node connected to
+// the geth node at the provided url.
+func dialNode(url string, metrics *Metrics) (*node, error) {
+	rpcClient, err := rpc.DialHTTPWithClient(url, &http.Client{
 		Timeout: gethHTTPTimeout,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to dial node", err)
+		return nil, fmt.Errorf("%w: unable to dial node %s", err, url)
 	}
 
-	tc, err := loadTraceConfig()
+	graphQLClient, err := newGraphQLClient(url)
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to load trace config", err)
+		return nil, fmt.Errorf("%w: unable to create GraphQL client for %s", err, url)
 	}
 
-	g, err := newGraphQLClient(url)
-	if err != nil {
-		return nil, fmt.Errorf("%w: unable to create GraphQL client", err)
+	var c JSONRPC = rpcClient
+	var g GraphQL = graphQLClient
+	if metrics != nil {
+		c = &metricsJSONRPC{JSONRPC: c, m: metrics}
+		g = &metricsGraphQL{GraphQL: g, m: metrics}
+	}
+
+	return &node{url: url, c: c, g: g}, nil
+}
+
+// pinNode calls fn with a context that routes all geth calls
+// to a single node so that the data of a block or transaction
+// is fetched consistently. If the Client is connected to a
+// single node, fn is called with ctx.
+func (ec *Client) pinNode(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ec.nodes == nil {
+		return fn(ctx)
 	}
 
-	return &Client{params, tc, c, g, semaphore.NewWeighted(maxTraceConcurrency), skipAdminCalls}, nil
+	return ec.nodes.pin(ctx, fn)
 }
 
 // Close shuts down the RPC client connection.
 func (ec *Client) Close() {
 	ec.c.Close()
+
+	if ec.bundler != nil {
+		ec.bundler.Close()
+	}
 }
 
 // Status returns geth status information
@@ -104,7 +269,7 @@ func (ec *Client) Status(ctx context.Context) (
 	[]*RosettaTypes.Peer,
 	error,
 ) {
-	header, err := ec.blockHeaderByNumber(ctx, nil)
+	header, err := ec.latestBlockHeader(ctx)
 	if err != nil {
 		return nil, -1, nil, nil, err
 	}
@@ -131,7 +296,7 @@ func (ec *Client) Status(ctx context.Context) (
 	}
 
 	return &RosettaTypes.BlockIdentifier{
-			Hash:  header.Hash().Hex(),
+			Hash:  header.Hash.Hex(),
 			Index: header.Number.Int64(),
 		},
 		convertTime(header.Time),
@@ -148,6 +313,19 @@ func (ec *Client) PendingNonceAt(ctx context.Context, account common.Address) (u
 	return uint64(result), err
 }
 
+// CodeAt returns the contract code of the given account.
+// The block number can be nil, in which case the code is
+// taken from the latest known block.
+func (ec *Client) CodeAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	var result hexutil.Bytes
+	err := ec.c.CallContext(ctx, &result, "eth_getCode", account, toBlockNumArg(blockNumber))
+	return result, err
+}
+
 // SuggestGasPrice retrieves the currently suggested gas price to allow a timely
 // execution of a transaction.
 func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
@@ -158,6 +336,143 @@ func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
+// SuggestGasTipCap retrieves the currently suggested gas tip cap after EIP-1559
+// to allow a timely execution of a transaction.
+func (ec *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	var hex hexutil.Big
+	if err := ec.c.CallContext(ctx, &hex, "eth_maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&hex), nil
+}
+
+// FeeHistory is the fee market data of a range of blocks. BaseFee
+// contains one more entry than the number of blocks: the base fee
+// of the block after the range.
+type FeeHistory struct {
+	OldestBlock  *big.Int
+	Reward       [][]*big.Int
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+}
+
+// feeHistoryResult is the response of geth to
+// a call of the "eth_feeHistory" method.
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+// FeeHistory returns the base fees, gas used ratios, and priority fee
+// rewards at the provided percentiles of the blockCount blocks up to
+// and including lastBlock. The lastBlock can be nil, in which case
+// the history ends at the latest block.
+func (ec *Client) FeeHistory(
+	ctx context.Context,
+	blockCount uint64,
+	lastBlock *big.Int,
+	rewardPercentiles []float64,
+) (*FeeHistory, error) {
+	var result feeHistoryResult
+	if err := ec.c.CallContext(
+		ctx,
+		&result,
+		"eth_feeHistory",
+		hexutil.Uint64(blockCount),
+		toBlockNumArg(lastBlock),
+		rewardPercentiles,
+	); err != nil {
+		return nil, err
+	}
+
+	if result.OldestBlock == nil {
+		return nil, errors.New("fee history is missing the oldest block")
+	}
+
+	reward := make([][]*big.Int, len(result.Reward))
+	for i, blockRewards := range result.Reward {
+		reward[i] = make([]*big.Int, len(blockRewards))
+		for j, r := range blockRewards {
+			reward[i][j] = r.ToInt()
+		}
+	}
+
+	baseFee := make([]*big.Int, len(result.BaseFee))
+	for i, b := range result.BaseFee {
+		baseFee[i] = b.ToInt()
+	}
+
+	return &FeeHistory{
+		OldestBlock:  result.OldestBlock.ToInt(),
+		Reward:       reward,
+		BaseFee:      baseFee,
+		GasUsedRatio: result.GasUsedRatio,
+	}, nil
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific
+// transaction based on the current pending state of the chain.
+func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var hex hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
+
+// CreateAccessList returns the access list of the addresses and
+// storage slots accessed by a call, based on the current pending
+// state of the chain.
+func (ec *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, error) {
+	var result struct {
+		AccessList types.AccessList `json:"accessList"`
+		Error      string           `json:"error,omitempty"`
+	}
+	err := ec.c.CallContext(ctx, &result, "eth_createAccessList", toCallArg(msg), "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	// geth returns the access list created up to the
+	// point of failure if the call reverts.
+	if len(result.Error) > 0 {
+		return nil, fmt.Errorf("unable to create access list: %s", result.Error)
+	}
+
+	return result.AccessList, nil
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if len(msg.AccessList) > 0 {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
+
+// HeaderByNumber returns a block header from the current canonical chain. If
+// number is nil, the latest known header is returned.
+func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return ec.blockHeaderByNumber(ctx, number)
+}
+
 // Peers retrieves all peers of the node.
 func (ec *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 	var info []*p2p.PeerInfo
@@ -192,7 +507,7 @@ func (ec *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -221,6 +536,74 @@ func (ec *Client) Transaction(
 		return nil, errors.New("transaction hash is required")
 	}
 
+	var tx *RosettaTypes.Transaction
+	err := ec.pinNode(ctx, func(ctx context.Context) error {
+		var err error
+		if ec.blockTransactionLimit > 0 {
+			tx, err = ec.blockTransaction(ctx, blockIdentifier, transactionIdentifier)
+		} else {
+			tx, err = ec.transaction(ctx, blockIdentifier, transactionIdentifier)
+		}
+		return err
+	})
+
+	return tx, err
+}
+
+// blockTransaction populates a transaction from the receipts and
+// traces of its entire block, so that fetching all transactions of
+// a block only traces the block once.
+func (ec *Client) blockTransaction(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transactionIdentifier *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.Transaction, error) {
+	var head *types.Header
+	var body *rpcBlock
+	var err error
+	if blockIdentifier.Hash != "" {
+		head, body, err = ec.getBlockBody(ctx, "eth_getBlockByHash", blockIdentifier.Hash, true)
+	} else {
+		head, body, err = ec.getBlockBody(
+			ctx,
+			"eth_getBlockByNumber",
+			toBlockNumArg(big.NewInt(blockIdentifier.Index)),
+			true,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get block %x", err, blockIdentifier.Hash)
+	}
+
+	hash := common.HexToHash(transactionIdentifier.Hash)
+	index := -1
+	for i, tx := range body.Transactions {
+		if tx.tx.Hash() == hash {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, ethereum.NotFound
+	}
+
+	loadedTxs, err := ec.loadTransactions(ctx, head, body)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ec.populateTransaction(loadedTxs[index])
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse %s", err, hash.Hex())
+	}
+	return tx, nil
+}
+
+func (ec *Client) transaction(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transactionIdentifier *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.Transaction, error) {
 	var raw json.RawMessage
 	err := ec.c.CallContext(ctx, &raw, "eth_getTransactionByHash", transactionIdentifier.Hash)
 	if err != nil {
@@ -296,11 +679,13 @@ func (ec *Client) Transaction(
 
 // Block returns a populated block at the *RosettaTypes.PartialBlockIdentifier.
 // If neither the hash or index is populated in the *RosettaTypes.PartialBlockIdentifier,
-// the current block is returned.
+// the current block is returned. Blocks with more transactions than the
+// block transaction limit only include the identifiers of their transactions
+// in OtherTransactions.
 func (ec *Client) Block(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
-) (*RosettaTypes.Block, error) {
+) (*RosettaTypes.BlockResponse, error) {
 	if blockIdentifier != nil {
 		if blockIdentifier.Hash != nil {
 			return ec.getParsedBlock(ctx, "eth_getBlockByHash", *blockIdentifier.Hash, true)
@@ -331,6 +716,43 @@ func (ec *Client) blockHeaderByNumber(ctx context.Context, number *big.Int) (*ty
 	return head, err
 }
 
+// rpcHeader is a block header and the hash of the block returned
+// by geth. The hash is not computed from the header, as go-ethereum's
+// header does not include the fields added after London (i.e.
+// withdrawalsRoot).
+type rpcHeader struct {
+	types.Header
+	Hash common.Hash
+}
+
+// UnmarshalJSON decodes the header and the hash of the block.
+func (h *rpcHeader) UnmarshalJSON(input []byte) error {
+	if err := h.Header.UnmarshalJSON(input); err != nil {
+		return err
+	}
+
+	var id struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(input, &id); err != nil {
+		return err
+	}
+
+	h.Hash = id.Hash
+	return nil
+}
+
+// latestBlockHeader returns the header and hash of the latest block.
+func (ec *Client) latestBlockHeader(ctx context.Context) (*rpcHeader, error) {
+	var head *rpcHeader
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(nil), false)
+	if err == nil && head == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return head, err
+}
+
 // Header returns a block header from the current canonical chain. If hash is empty
 // it returns error.
 func (ec *Client) blockHeaderByHash(ctx context.Context, hash string) (*types.Header, error) {
@@ -350,6 +772,17 @@ type rpcBlock struct {
 	Hash         common.Hash      `json:"hash"`
 	Transactions []rpcTransaction `json:"transactions"`
 	UncleHashes  []common.Hash    `json:"uncles"`
+	Withdrawals  []*rpcWithdrawal `json:"withdrawals"`
+}
+
+// rpcWithdrawal is a withdrawal from the beacon chain
+// included in a block after Shanghai (EIP-4895). The
+// amount is denominated in gwei.
+type rpcWithdrawal struct {
+	Index          hexutil.Uint64 `json:"index"`
+	ValidatorIndex hexutil.Uint64 `json:"validatorIndex"`
+	Address        common.Address `json:"address"`
+	Amount         hexutil.Uint64 `json:"amount"`
 }
 
 func (ec *Client) getUncles(
@@ -410,13 +843,21 @@ func (ec *Client) getUncles(
 	return uncles, nil
 }
 
-func (ec *Client) getBlock(
+// isLazyBlock returns a boolean indicating if a block with
+// the provided number of transactions exceeds the block
+// transaction limit.
+func (ec *Client) isLazyBlock(transactions int) bool {
+	return ec.blockTransactionLimit > 0 && transactions > ec.blockTransactionLimit
+}
+
+// getBlockBody fetches the header and transactions of a block.
+func (ec *Client) getBlockBody(
 	ctx context.Context,
 	blockMethod string,
 	args ...interface{},
 ) (
-	*types.Block,
-	[]*loadedTransaction,
+	*types.Header,
+	*rpcBlock,
 	error,
 ) {
 	var raw json.RawMessage
@@ -437,48 +878,97 @@ func (ec *Client) getBlock(
 		return nil, nil, err
 	}
 
-	uncles, err := ec.getUncles(ctx, &head, &body)
+	return &head, &body, nil
+}
+
+func (ec *Client) getBlock(
+	ctx context.Context,
+	blockMethod string,
+	args ...interface{},
+) (
+	*types.Block,
+	*rpcBlock,
+	[]*loadedTransaction,
+	error,
+) {
+	head, body, err := ec.getBlockBody(ctx, blockMethod, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	uncles, err := ec.getUncles(ctx, head, body)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: unable to get uncles", err)
+		return nil, nil, nil, fmt.Errorf("%w: unable to get uncles", err)
 	}
 
-	// Get all transaction receipts
-	receipts, err := ec.getBlockReceipts(ctx, body.Hash, body.Transactions)
+	txs := make([]*types.Transaction, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		txs[i] = tx.tx
+	}
+
+	// Receipts and traces of blocks exceeding the block transaction
+	// limit are only loaded when their transactions are fetched.
+	var loadedTxs []*loadedTransaction
+	if !ec.isLazyBlock(len(body.Transactions)) {
+		loadedTxs, err = ec.loadTransactions(ctx, head, body)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return types.NewBlockWithHeader(head).WithBody(txs, uncles), body, loadedTxs, nil
+}
+
+// loadTransactions converts all transactions of a block to loaded
+// transactions using the receipts and traces of the block.
+func (ec *Client) loadTransactions(
+	ctx context.Context,
+	head *types.Header,
+	body *rpcBlock,
+) ([]*loadedTransaction, error) {
+	addTraces := head.Number.Int64() != GenesisBlockIndex // not possible to get traces at genesis
+
+	// Get all transaction receipts and block traces from the
+	// cache if the block was previously cached.
+	cached, err := ec.recentBlocks.load(ctx, body.Hash, func(ctx context.Context) (*cachedBlock, error) {
+		// A cache that cannot be read should not fail the
+		// request, as the block can still be fetched from geth.
+		cached, err := ec.cache.get(body.Hash)
+		if err != nil {
+			log.Printf("%s: could not read cache for %x\n", err.Error(), body.Hash[:])
+			cached = nil
+		}
+
+		if cached != nil && len(cached.Receipts) == len(body.Transactions) {
+			return cached, nil
+		}
+
+		return ec.fetchBlockData(ctx, head, body, addTraces)
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: could not get receipts for %x", err, body.Hash[:])
+		return nil, err
 	}
+	receipts := cached.Receipts
 
-	// Get block traces (not possible to make idempotent block transaction trace requests)
-	//
-	// We fetch traces last because we want to avoid limiting the number of other
-	// block-related data fetches we perform concurrently (we limit the number of
-	// concurrent traces that are computed to 16 to avoid overwhelming geth).
 	var traces []*rpcCall
 	var rawTraces []*rpcRawCall
-	var addTraces bool
-	if head.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
-		addTraces = true
-		traces, rawTraces, err = ec.getBlockTraces(ctx, body.Hash)
+	if addTraces {
+		traces, rawTraces, err = decodeBlockTraces(cached.Traces)
 		if err != nil {
-			return nil, nil, fmt.Errorf("%w: could not get traces for %x", err, body.Hash[:])
+			return nil, fmt.Errorf("%w: could not decode traces for %x", err, body.Hash[:])
 		}
 	}
 
 	// Convert all txs to loaded txs
-	txs := make([]*types.Transaction, len(body.Transactions))
 	loadedTxs := make([]*loadedTransaction, len(body.Transactions))
 	for i, tx := range body.Transactions {
-		txs[i] = tx.tx
 		receipt := receipts[i]
-		if err != nil {
-			return nil, nil, fmt.Errorf("%w: failure getting effective gas price", err)
-		}
 		loadedTxs[i] = tx.LoadedTransaction()
-		loadedTxs[i].Transaction = txs[i]
+		loadedTxs[i].Transaction = tx.tx
 
-		feeAmount, feeBurned, err := calculateGas(txs[i], receipt, head)
+		feeAmount, feeBurned, err := calculateGas(tx.tx, receipt, *head)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		loadedTxs[i].FeeAmount = feeAmount
 		loadedTxs[i].FeeBurned = feeBurned
@@ -494,7 +984,60 @@ func (ec *Client) getBlock(
 		loadedTxs[i].RawTrace = rawTraces[i].Result
 	}
 
-	return types.NewBlockWithHeader(&head).WithBody(txs, uncles), loadedTxs, nil
+	return loadedTxs, nil
+}
+
+// fetchBlockData fetches the receipts and traces of a block from
+// geth and stores them in the cache if the block is final.
+func (ec *Client) fetchBlockData(
+	ctx context.Context,
+	head *types.Header,
+	body *rpcBlock,
+	addTraces bool,
+) (*cachedBlock, error) {
+	// Get all transaction receipts
+	receipts, err := ec.getBlockReceipts(ctx, body.Hash, body.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get receipts for %x", err, body.Hash[:])
+	}
+
+	// Get block traces (not possible to make idempotent block transaction trace requests)
+	//
+	// We fetch traces last because we want to avoid limiting the number of other
+	// block-related data fetches we perform concurrently (we limit the number of
+	// concurrent traces that are computed to 16 to avoid overwhelming geth).
+	var traces json.RawMessage
+	if addTraces {
+		traces, err = ec.getBlockTraces(ctx, body.Hash, head.Number, len(body.Transactions))
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not get traces for %x", err, body.Hash[:])
+		}
+	}
+
+	block := &cachedBlock{
+		Receipts: receipts,
+		Traces:   traces,
+	}
+
+	if ec.cache == nil {
+		return block, nil
+	}
+
+	// Failing to cache a block should not fail the request,
+	// as the block can still be fetched from geth later.
+	var current hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &current, "eth_blockNumber"); err != nil {
+		log.Printf("%s: could not get current block number\n", err.Error())
+		return block, nil
+	}
+
+	if ec.cache.final(head.Number.Uint64(), uint64(current)) {
+		if err := ec.cache.set(body.Hash, block); err != nil {
+			log.Printf("%s: could not cache %x\n", err.Error(), body.Hash[:])
+		}
+	}
+
+	return block, nil
 }
 
 func calculateGas(
@@ -533,18 +1076,32 @@ func effectiveGasPrice(tx *EthTypes.Transaction, baseFee *big.Int) (*big.Int, er
 	return new(big.Int).Add(tip, baseFee), nil
 }
 
+// acquireTraceSemaphore waits until a trace can be requested
+// from geth, recording the number of waiting requests.
+func (ec *Client) acquireTraceSemaphore(ctx context.Context) error {
+	ec.metrics.updateTraceQueue(1)
+	defer ec.metrics.updateTraceQueue(-1)
+
+	return ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight)
+}
+
 func (ec *Client) getTransactionTraces(
 	ctx context.Context,
 	transactionHash common.Hash,
 ) (*Call, json.RawMessage, error) {
-	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
 		return nil, nil, err
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
 
 	var call *Call
 	var raw json.RawMessage
-	err := ec.c.CallContext(ctx, &raw, "debug_traceTransaction", transactionHash, ec.tc)
+	var err error
+	if ec.traceAPI == ParityTraceAPI {
+		raw, err = ec.getParityTransactionTraces(ctx, transactionHash)
+	} else {
+		err = ec.c.CallContext(ctx, &raw, "debug_traceTransaction", transactionHash, ec.tc)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -560,20 +1117,33 @@ func (ec *Client) getTransactionTraces(
 func (ec *Client) getBlockTraces(
 	ctx context.Context,
 	blockHash common.Hash,
-) ([]*rpcCall, []*rpcRawCall, error) {
-	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
-		return nil, nil, err
+	blockNumber *big.Int,
+	transactions int,
+) (json.RawMessage, error) {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
+		return nil, err
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
 
-	var calls []*rpcCall
-	var rawCalls []*rpcRawCall
+	if ec.traceAPI == ParityTraceAPI {
+		return ec.getParityBlockTraces(ctx, blockHash, blockNumber, transactions)
+	}
+
 	var raw json.RawMessage
 	err := ec.c.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, ec.tc)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
+	return raw, nil
+}
+
+// decodeBlockTraces decodes the result of debug_traceBlockByHash
+// (trace_block results are converted into the same format).
+func decodeBlockTraces(raw json.RawMessage) ([]*rpcCall, []*rpcRawCall, error) {
+	var calls []*rpcCall
+	var rawCalls []*rpcRawCall
+
 	// Decode []*rpcCall
 	if err := json.Unmarshal(raw, &calls); err != nil {
 		return nil, nil, err
@@ -644,6 +1214,7 @@ type Call struct {
 	To           common.Address `json:"to"`
 	Value        *big.Int       `json:"value"`
 	GasUsed      *big.Int       `json:"gasUsed"`
+	Input        []byte         `json:"input"`
 	Revert       bool
 	ErrorMessage string  `json:"error"`
 	Calls        []*Call `json:"calls"`
@@ -655,23 +1226,31 @@ type flatCall struct {
 	To           common.Address `json:"to"`
 	Value        *big.Int       `json:"value"`
 	GasUsed      *big.Int       `json:"gasUsed"`
+	Input        []byte         `json:"input"`
+	Depth        int            `json:"depth"`
 	Revert       bool
 	ErrorMessage string `json:"error"`
 }
 
-func (t *Call) flatten() *flatCall {
+func (t *Call) flatten(depth int) *flatCall {
 	return &flatCall{
 		Type:         t.Type,
 		From:         t.From,
 		To:           t.To,
 		Value:        t.Value,
 		GasUsed:      t.GasUsed,
+		Input:        t.Input,
+		Depth:        depth,
 		Revert:       t.Revert,
 		ErrorMessage: t.ErrorMessage,
 	}
 }
 
-// UnmarshalJSON is a custom unmarshaler for Call.
+// UnmarshalJSON is a custom unmarshaler for Call. It decodes
+// the output of both geth's native callTracer and the JavaScript
+// call tracer. The native callTracer omits the value of calls
+// that cannot transfer value (i.e. DELEGATECALL and STATICCALL)
+// and may include the logs of each call, which are ignored.
 func (t *Call) UnmarshalJSON(input []byte) error {
 	type CustomTrace struct {
 		Type         string         `json:"type"`
@@ -679,6 +1258,7 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		To           common.Address `json:"to"`
 		Value        *hexutil.Big   `json:"value"`
 		GasUsed      *hexutil.Big   `json:"gasUsed"`
+		Input        hexutil.Bytes  `json:"input"`
 		Revert       bool
 		ErrorMessage string  `json:"error"`
 		Calls        []*Call `json:"calls"`
@@ -701,6 +1281,7 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 	} else {
 		t.GasUsed = new(big.Int)
 	}
+	t.Input = dec.Input
 	if dec.ErrorMessage != "" {
 		// Any error surfaced by the decoder means that the transaction
 		// has reverted.
@@ -713,7 +1294,13 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 
 // flattenTraces recursively flattens all traces.
 func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
-	results := append(flattened, data.flatten())
+	return flattenTracesAtDepth(data, 0, flattened)
+}
+
+// flattenTracesAtDepth recursively flattens all traces, recording
+// the call depth of each trace (0 for the transaction itself).
+func flattenTracesAtDepth(data *Call, depth int, flattened []*flatCall) []*flatCall {
+	results := append(flattened, data.flatten(depth))
 	for _, child := range data.Calls {
 		// Ensure all children of a reverted call
 		// are also reverted!
@@ -727,15 +1314,20 @@ func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
 			}
 		}
 
-		children := flattenTraces(child, flattened)
+		children := flattenTracesAtDepth(child, depth+1, flattened)
 		results = append(results, children...)
 	}
 	return results
 }
 
 // traceOps returns all *RosettaTypes.Operation for a given
-// array of flattened traces.
-func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { // nolint: gocognit
+// array of flattened traces. Call traces that do not transfer
+// any value are skipped unless includeZeroValueCalls is set.
+func traceOps( // nolint: gocognit
+	calls []*flatCall,
+	startIndex int,
+	includeZeroValueCalls bool,
+) []*RosettaTypes.Operation {
 	var ops []*RosettaTypes.Operation
 	if len(calls) == 0 {
 		return ops
@@ -756,13 +1348,18 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 			zeroValue = true
 		}
 
-		// Skip all 0 value CallType operations (TODO: make optional to include)
+		// Skip all 0 value CallType operations unless includeZeroValueCalls
+		// is set, in which case the call depth, input selector, and gas
+		// used are included in the metadata to describe the call.
 		//
 		// We can't continue here because we may need to adjust our destroyed
 		// accounts map if a CallTYpe operation resurrects an account.
 		shouldAdd := true
 		if zeroValue && CallType(trace.Type) {
-			shouldAdd = false
+			shouldAdd = includeZeroValueCalls
+			if shouldAdd {
+				addCallMetadata(metadata, trace)
+			}
 		}
 
 		// Checksum addresses
@@ -889,6 +1486,16 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 	return ops
 }
 
+// addCallMetadata adds the call depth, input selector (if any),
+// and gas used of a call trace to the metadata of its operations.
+func addCallMetadata(metadata map[string]interface{}, trace *flatCall) {
+	metadata["call_depth"] = trace.Depth
+	metadata["gas_used"] = hexutil.EncodeBig(trace.GasUsed)
+	if len(trace.Input) >= methodIDLength {
+		metadata["input_selector"] = hexutil.Encode(trace.Input[:methodIDLength])
+	}
+}
+
 type txExtraInfo struct {
 	BlockNumber *string         `json:"blockNumber,omitempty"`
 	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
@@ -995,10 +1602,75 @@ func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
 	return append(ops, burntOp)
 }
 
-// transactionReceipt returns the receipt of a transaction by transaction hash.
-// Note that the receipt is not available for pending transactions.
-func (ec *Client) transactionReceipt(
-	ctx context.Context,
+// tokenOps returns all *RosettaTypes.Operation for the ERC-20
+// Transfer events of supported tokens in a receipt. Mints and
+// burns only produce an operation for the non-zero address.
+func (ec *Client) tokenOps(
+	receipt *types.Receipt,
+	startIndex int,
+) []*RosettaTypes.Operation {
+	var ops []*RosettaTypes.Operation
+	for _, log := range receipt.Logs {
+		currency, ok := ec.tokens.Currency(log.Address)
+		if !ok {
+			continue
+		}
+
+		from, to, amount, ok := parseERC20TransferLog(log)
+		if !ok || amount.Sign() == 0 {
+			continue
+		}
+
+		var fromOp *RosettaTypes.Operation
+		if from != (common.Address{}) {
+			fromOp = &RosettaTypes.Operation{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: int64(len(ops) + startIndex),
+				},
+				Type:   ERC20TransferOpType,
+				Status: RosettaTypes.String(SuccessStatus),
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: MustChecksum(from.Hex()),
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    new(big.Int).Neg(amount).String(),
+					Currency: currency,
+				},
+			}
+			ops = append(ops, fromOp)
+		}
+
+		if to != (common.Address{}) {
+			toOp := &RosettaTypes.Operation{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: int64(len(ops) + startIndex),
+				},
+				Type:   ERC20TransferOpType,
+				Status: RosettaTypes.String(SuccessStatus),
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: MustChecksum(to.Hex()),
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    amount.String(),
+					Currency: currency,
+				},
+			}
+			if fromOp != nil {
+				toOp.RelatedOperations = []*RosettaTypes.OperationIdentifier{
+					fromOp.OperationIdentifier,
+				}
+			}
+			ops = append(ops, toOp)
+		}
+	}
+
+	return ops
+}
+
+// transactionReceipt returns the receipt of a transaction by transaction hash.
+// Note that the receipt is not available for pending transactions.
+func (ec *Client) transactionReceipt(
+	ctx context.Context,
 	txHash common.Hash,
 ) (*types.Receipt, error) {
 	var r *types.Receipt
@@ -1140,16 +1812,26 @@ func (ec *Client) getParsedBlock(
 	blockMethod string,
 	args ...interface{},
 ) (
-	*RosettaTypes.Block,
+	*RosettaTypes.BlockResponse,
 	error,
 ) {
-	block, loadedTransactions, err := ec.getBlock(ctx, blockMethod, args...)
+	var block *EthTypes.Block
+	var body *rpcBlock
+	var loadedTransactions []*loadedTransaction
+	err := ec.pinNode(ctx, func(ctx context.Context) error {
+		var err error
+		block, body, loadedTransactions, err = ec.getBlock(ctx, blockMethod, args...)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not get block", err)
 	}
 
+	// The hash returned by geth is used instead of computing it
+	// from the header, as go-ethereum's header does not include
+	// the fields added after London (i.e. withdrawalsRoot).
 	blockIdentifier := &RosettaTypes.BlockIdentifier{
-		Hash:  block.Hash().String(),
+		Hash:  body.Hash.Hex(),
 		Index: block.Number().Int64(),
 	}
 
@@ -1161,17 +1843,33 @@ func (ec *Client) getParsedBlock(
 		}
 	}
 
-	txs, err := ec.populateTransactions(blockIdentifier, block, loadedTransactions)
+	txs, err := ec.populateTransactions(blockIdentifier, block, loadedTransactions, body.Withdrawals)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RosettaTypes.Block{
-		BlockIdentifier:       blockIdentifier,
-		ParentBlockIdentifier: parentBlockIdentifier,
-		Timestamp:             convertTime(block.Time()),
-		Transactions:          txs,
-	}, nil
+	response := &RosettaTypes.BlockResponse{
+		Block: &RosettaTypes.Block{
+			BlockIdentifier:       blockIdentifier,
+			ParentBlockIdentifier: parentBlockIdentifier,
+			Timestamp:             convertTime(block.Time()),
+			Transactions:          txs,
+		},
+	}
+
+	if ec.isLazyBlock(len(block.Transactions())) {
+		response.OtherTransactions = make(
+			[]*RosettaTypes.TransactionIdentifier,
+			len(block.Transactions()),
+		)
+		for i, tx := range block.Transactions() {
+			response.OtherTransactions[i] = &RosettaTypes.TransactionIdentifier{
+				Hash: tx.Hash().Hex(),
+			}
+		}
+	}
+
+	return response, nil
 }
 
 func convertTime(time uint64) int64 {
@@ -1182,20 +1880,38 @@ func (ec *Client) populateTransactions(
 	blockIdentifier *RosettaTypes.BlockIdentifier,
 	block *EthTypes.Block,
 	loadedTransactions []*loadedTransaction,
+	withdrawals []*rpcWithdrawal,
 ) ([]*RosettaTypes.Transaction, error) {
 	transactions := make(
 		[]*RosettaTypes.Transaction,
-		len(block.Transactions())+1, // include reward tx
+		0,
+		len(block.Transactions())+1, // include reward or withdrawal tx
 	)
 
-	// Compute reward transaction (block + uncle reward)
-	transactions[0] = ec.blockRewardTransaction(
-		blockIdentifier,
-		block.Coinbase().String(),
-		block.Uncles(),
-	)
-
-	for i, tx := range loadedTransactions {
+	// Compute reward transaction (block + uncle reward). The
+	// execution layer does not pay any block reward after the
+	// Merge, so proof-of-stake blocks have no reward transaction.
+	if !ec.isProofOfStake(block.Header()) {
+		transactions = append(transactions, ec.blockRewardTransaction(
+			blockIdentifier,
+			block.Coinbase().String(),
+			block.Uncles(),
+		))
+	}
+
+	// Compute withdrawal transaction (beacon chain withdrawals).
+	// A block of a network without a terminal total difficulty
+	// can have both a reward and a withdrawal transaction, so the
+	// withdrawal transaction is not identified by the hash of the
+	// block.
+	if len(withdrawals) > 0 {
+		transactions = append(transactions, withdrawalTransaction(
+			blockIdentifier,
+			withdrawals,
+		))
+	}
+
+	for _, tx := range loadedTransactions {
 		transaction, err := ec.populateTransaction(
 			tx,
 		)
@@ -1203,12 +1919,24 @@ func (ec *Client) populateTransactions(
 			return nil, fmt.Errorf("%w: cannot parse %s", err, tx.Transaction.Hash().Hex())
 		}
 
-		transactions[i+1] = transaction
+		transactions = append(transactions, transaction)
 	}
 
 	return transactions, nil
 }
 
+// isProofOfStake returns a boolean indicating if the block with
+// the provided header was produced after the Merge. EIP-3675
+// requires the difficulty of all proof-of-stake blocks to be 0,
+// so we only consider networks with a terminal total difficulty.
+func (ec *Client) isProofOfStake(header *EthTypes.Header) bool {
+	if ec.p.TerminalTotalDifficulty == nil || header.Difficulty == nil {
+		return false
+	}
+
+	return header.Difficulty.Sign() == 0
+}
+
 func (ec *Client) populateTransaction(
 	tx *loadedTransaction,
 ) (*RosettaTypes.Transaction, error) {
@@ -1221,9 +1949,13 @@ func (ec *Client) populateTransaction(
 	// Compute trace operations
 	traces := flattenTraces(tx.Trace, []*flatCall{})
 
-	traceOps := traceOps(traces, len(ops))
+	traceOps := traceOps(traces, len(ops), ec.includeZeroValueCalls)
 	ops = append(ops, traceOps...)
 
+	// Compute token transfer operations
+	tokenOps := ec.tokenOps(tx.Receipt, len(ops))
+	ops = append(ops, tokenOps...)
+
 	// Marshal receipt and trace data
 	// TODO: replace with marshalJSONMap (used in `services`)
 	receiptBytes, err := tx.Receipt.MarshalJSON()
@@ -1254,6 +1986,11 @@ func (ec *Client) populateTransaction(
 		},
 	}
 
+	// Decode the logs of all registered events
+	if events := ec.events.DecodeReceipt(tx.Receipt); len(events) > 0 {
+		populatedTransaction.Metadata["events"] = events
+	}
+
 	return populatedTransaction, nil
 }
 
@@ -1348,6 +2085,59 @@ func (ec *Client) blockRewardTransaction(
 	}
 }
 
+// withdrawalTransaction returns a synthetic transaction crediting
+// the withdrawals from the beacon chain included in a block. The
+// amount of each withdrawal is converted from gwei to wei.
+func withdrawalTransaction(
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	withdrawals []*rpcWithdrawal,
+) *RosettaTypes.Transaction {
+	ops := make([]*RosettaTypes.Operation, len(withdrawals))
+	for i, withdrawal := range withdrawals {
+		amount := new(big.Int).Mul(
+			new(big.Int).SetUint64(uint64(withdrawal.Amount)),
+			big.NewInt(params.GWei),
+		)
+
+		ops[i] = &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: int64(i),
+			},
+			Type:   WithdrawalOpType,
+			Status: RosettaTypes.String(SuccessStatus),
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: MustChecksum(withdrawal.Address.Hex()),
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    amount.String(),
+				Currency: Currency,
+			},
+			Metadata: map[string]interface{}{
+				"index":           withdrawal.Index.String(),
+				"validator_index": withdrawal.ValidatorIndex.String(),
+			},
+		}
+	}
+
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: withdrawalTransactionHash(blockIdentifier.Hash),
+		},
+		Operations: ops,
+	}
+}
+
+// withdrawalTransactionHash returns the hash of the withdrawal
+// transaction of the block with the provided hash, which differs
+// from the hash of the block that identifies its reward
+// transaction.
+func withdrawalTransactionHash(blockHash string) string {
+	return crypto.Keccak256Hash(
+		common.HexToHash(blockHash).Bytes(),
+		[]byte(withdrawalTransactionSuffix),
+	).Hex()
+}
+
 type rpcProgress struct {
 	StartingBlock hexutil.Uint64
 	CurrentBlock  hexutil.Uint64
@@ -1396,23 +2186,156 @@ type graphqlBalance struct {
 				Balance string `json:"balance"`
 				Nonce   string `json:"transactionCount"`
 				Code    string `json:"code"`
+				Storage string `json:"storage"`
 			} `json:"account"`
 		} `json:"block"`
 	} `json:"data"`
 }
 
+type graphqlCallResult struct {
+	Data   string `json:"data"`
+	Status int64  `json:"status"`
+}
+
 // Balance returns the balance of a *RosettaTypes.AccountIdentifier
-// at a *RosettaTypes.PartialBlockIdentifier.
+// at a *RosettaTypes.PartialBlockIdentifier for each of the provided
+// currencies. If no currencies are provided, the balance of ETH and
+// all supported tokens is returned.
+//
+// The balance must be fetched atomically with the block where it
+// was fetched. This is done with a single graphql query or, if
+// graphql is not available, by pinning JSON-RPC calls to the
+// block hash (EIP-1898).
 //
-// We must use graphql to get the balance atomically (the
-// rpc method for balance does not allow for querying
-// by block hash nor return the block hash where
-// the balance was fetched).
+// If the account has a *RosettaTypes.SubAccountIdentifier, its
+// address is a 32-byte storage slot of the account and the balance
+// is the value stored in that slot (i.e. a balance kept in a
+// contract's storage). The value is returned in the single requested
+// currency or, if no currency is requested, in ETH.
 func (ec *Client) Balance(
 	ctx context.Context,
 	account *RosettaTypes.AccountIdentifier,
 	block *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	var slot *common.Hash
+	if account.SubAccount != nil {
+		subAccountSlot, err := storageSlot(account.SubAccount)
+		if err != nil {
+			return nil, err
+		}
+		if len(currencies) > 1 {
+			return nil, fmt.Errorf(
+				"%w: balance of sub-account %s can only be returned in a single currency",
+				ErrInvalidSubAccount,
+				account.SubAccount.Address,
+			)
+		}
+
+		slot = &subAccountSlot
+	}
+
+	if len(currencies) == 0 {
+		currencies = []*RosettaTypes.Currency{Currency}
+		if slot == nil {
+			currencies = append(currencies, ec.tokens.Currencies()...)
+		}
+	}
+
+	// Token balances are fetched with a balanceOf call
+	// in the same request as the account balance. The
+	// balance of a sub-account is only denominated in
+	// the currency, so no call is needed.
+	contracts := make([]*common.Address, len(currencies))
+	for i, currency := range currencies {
+		if RosettaTypes.Hash(currency) == RosettaTypes.Hash(Currency) {
+			continue
+		}
+
+		contract, ok := ec.tokens.Contract(currency)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCurrencyNotSupported, RosettaTypes.PrintStruct(currency))
+		}
+		if slot == nil {
+			contracts[i] = &contract
+		}
+	}
+
+	if ec.balanceAPI == RPCBalanceAPI {
+		return ec.rpcBalance(ctx, account, block, currencies, contracts, slot)
+	}
+
+	return ec.graphQLBalance(ctx, account, block, currencies, contracts, slot)
+}
+
+// storageSlot parses the address of a
+// *RosettaTypes.SubAccountIdentifier as a storage slot.
+func storageSlot(subAccount *RosettaTypes.SubAccountIdentifier) (common.Hash, error) {
+	slot, err := hexutil.Decode(subAccount.Address)
+	if err != nil || len(slot) != common.HashLength {
+		return common.Hash{}, fmt.Errorf(
+			"%w: %s is not a 32-byte hex storage slot",
+			ErrInvalidSubAccount,
+			subAccount.Address,
+		)
+	}
+
+	return common.BytesToHash(slot), nil
+}
+
+// storageBalance returns the balance of a sub-account
+// from the value stored in its storage slot.
+func storageBalance(
+	value []byte,
+	currencies []*RosettaTypes.Currency,
+) ([]*RosettaTypes.Amount, error) {
+	if len(value) != common.HashLength {
+		return nil, fmt.Errorf("unexpected storage value %s", hexutil.Encode(value))
+	}
+
+	return []*RosettaTypes.Amount{
+		{
+			Value:    new(big.Int).SetBytes(value).String(),
+			Currency: currencies[0],
+		},
+	}, nil
+}
+
+// graphQLBalance fetches the balances of an account
+// in a single graphql query.
+func (ec *Client) graphQLBalance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	block *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+	contracts []*common.Address,
+	slot *common.Hash,
 ) (*RosettaTypes.AccountBalanceResponse, error) {
+	storageQuery := ""
+	if slot != nil {
+		storageQuery = fmt.Sprintf(`
+					storage(slot:"%s")`, slot.Hex())
+	}
+
+	tokenCalls := ""
+	tokenAliases := make([]string, len(currencies))
+	for i, contract := range contracts {
+		if contract == nil {
+			continue
+		}
+
+		tokenAliases[i] = fmt.Sprintf("token%d", i)
+		tokenCalls += fmt.Sprintf(`
+				%s: call(data:{to:"%s",data:"%s"}){
+					data
+					status
+				}`,
+			tokenAliases[i],
+			contract.Hex(),
+			hexutil.Encode(erc20BalanceOfData(common.HexToAddress(account.Address))),
+		)
+	}
+
 	blockQuery := ""
 	if block != nil {
 		if block.Hash != nil {
@@ -1430,10 +2353,10 @@ func (ec *Client) Balance(
 				account(address:"%s"){
 					balance
 					transactionCount
-					code
-				}
+					code%s
+				}%s
 			}
-		}`, blockQuery, account.Address))
+		}`, blockQuery, account.Address, storageQuery, tokenCalls))
 	if err != nil {
 		return nil, err
 	}
@@ -1462,13 +2385,52 @@ func (ec *Client) Balance(
 		)
 	}
 
-	return &RosettaTypes.AccountBalanceResponse{
-		Balances: []*RosettaTypes.Amount{
-			{
+	var calls struct {
+		Data struct {
+			Block map[string]json.RawMessage `json:"block"`
+		} `json:"data"`
+	}
+	if len(tokenCalls) > 0 {
+		if err := json.Unmarshal([]byte(result), &calls); err != nil {
+			return nil, err
+		}
+	}
+
+	balances := make([]*RosettaTypes.Amount, len(currencies))
+	for i, currency := range currencies {
+		if len(tokenAliases[i]) == 0 {
+			balances[i] = &RosettaTypes.Amount{
 				Value:    balance.String(),
-				Currency: Currency,
-			},
-		},
+				Currency: currency,
+			}
+			continue
+		}
+
+		tokenBalance, err := parseTokenBalance(calls.Data.Block[tokenAliases[i]])
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not extract %s balance", err, currency.Symbol)
+		}
+
+		balances[i] = &RosettaTypes.Amount{
+			Value:    tokenBalance.String(),
+			Currency: currency,
+		}
+	}
+
+	if slot != nil {
+		value, err := hexutil.Decode(bal.Data.Block.Account.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not extract storage value", err)
+		}
+
+		balances, err = storageBalance(value, currencies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RosettaTypes.AccountBalanceResponse{
+		Balances: balances,
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
 			Hash:  bal.Data.Block.Hash,
 			Index: bal.Data.Block.Number,
@@ -1480,6 +2442,195 @@ func (ec *Client) Balance(
 	}, nil
 }
 
+// rpcBlockIdentifier is the hash and number of a
+// block returned by eth_getBlockBy*.
+type rpcBlockIdentifier struct {
+	Hash   common.Hash    `json:"hash"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+// eip1898Block identifies a block by hash in the block
+// parameter of a JSON-RPC call (EIP-1898). Calls fail if the
+// block is no longer canonical.
+type eip1898Block struct {
+	BlockHash        common.Hash `json:"blockHash"`
+	RequireCanonical bool        `json:"requireCanonical"`
+}
+
+// rpcBalance fetches the balances of an account with
+// a single batch of JSON-RPC calls at the hash of
+// the requested block. The block lookup and the batch
+// are sent to the same node.
+func (ec *Client) rpcBalance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	block *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+	contracts []*common.Address,
+	slot *common.Hash,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	var balance *RosettaTypes.AccountBalanceResponse
+	err := ec.pinNode(ctx, func(ctx context.Context) error {
+		var err error
+		balance, err = ec.blockBalance(ctx, account, block, currencies, contracts, slot)
+		return err
+	})
+
+	return balance, err
+}
+
+// blockBalance looks up the requested block and fetches
+// the balances of an account at its hash.
+func (ec *Client) blockBalance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	block *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
+	contracts []*common.Address,
+	slot *common.Hash,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	var blockIdentifier *rpcBlockIdentifier
+	var err error
+	switch {
+	case block != nil && block.Hash != nil:
+		err = ec.c.CallContext(ctx, &blockIdentifier, "eth_getBlockByHash", *block.Hash, false)
+	case block != nil && block.Index != nil:
+		err = ec.c.CallContext(
+			ctx,
+			&blockIdentifier,
+			"eth_getBlockByNumber",
+			toBlockNumArg(big.NewInt(*block.Index)),
+			false,
+		)
+	default:
+		err = ec.c.CallContext(ctx, &blockIdentifier, "eth_getBlockByNumber", toBlockNumArg(nil), false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get block", err)
+	}
+	if blockIdentifier == nil {
+		return nil, ethereum.NotFound
+	}
+
+	address := common.HexToAddress(account.Address)
+	blockArg := &eip1898Block{
+		BlockHash:        blockIdentifier.Hash,
+		RequireCanonical: true,
+	}
+
+	var balance hexutil.Big
+	var nonce hexutil.Uint64
+	var code hexutil.Bytes
+	tokenBalances := make([]hexutil.Bytes, len(currencies))
+	reqs := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{address, blockArg}, Result: &balance},
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, blockArg}, Result: &nonce},
+		{Method: "eth_getCode", Args: []interface{}{address, blockArg}, Result: &code},
+	}
+	for i, contract := range contracts {
+		if contract == nil {
+			continue
+		}
+
+		reqs = append(reqs, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{
+					"to":   contract,
+					"data": hexutil.Bytes(erc20BalanceOfData(address)),
+				},
+				blockArg,
+			},
+			Result: &tokenBalances[i],
+		})
+	}
+
+	var storage hexutil.Bytes
+	if slot != nil {
+		reqs = append(reqs, rpc.BatchElem{
+			Method: "eth_getStorageAt",
+			Args:   []interface{}{address, slot, blockArg},
+			Result: &storage,
+		})
+	}
+
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, fmt.Errorf("%w: %s failed", reqs[i].Error, reqs[i].Method)
+		}
+	}
+
+	balances := make([]*RosettaTypes.Amount, len(currencies))
+	for i, currency := range currencies {
+		if contracts[i] == nil {
+			balances[i] = &RosettaTypes.Amount{
+				Value:    balance.ToInt().String(),
+				Currency: currency,
+			}
+			continue
+		}
+
+		if len(tokenBalances[i]) != erc20AmountLength {
+			return nil, fmt.Errorf(
+				"could not extract %s balance from %s",
+				currency.Symbol,
+				tokenBalances[i].String(),
+			)
+		}
+
+		balances[i] = &RosettaTypes.Amount{
+			Value:    new(big.Int).SetBytes(tokenBalances[i]).String(),
+			Currency: currency,
+		}
+	}
+
+	if slot != nil {
+		balances, err = storageBalance(storage, currencies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RosettaTypes.AccountBalanceResponse{
+		Balances: balances,
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  blockIdentifier.Hash.Hex(),
+			Index: int64(blockIdentifier.Number),
+		},
+		Metadata: map[string]interface{}{
+			"nonce": int64(nonce),
+			"code":  code.String(),
+		},
+	}, nil
+}
+
+// parseTokenBalance decodes the result of a balanceOf
+// call made with graphql.
+func parseTokenBalance(raw json.RawMessage) (*big.Int, error) {
+	var call *graphqlCallResult
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return nil, err
+	}
+
+	if call == nil || call.Status != 1 {
+		return nil, errors.New("balanceOf call failed")
+	}
+
+	data, err := hexutil.Decode(call.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != erc20AmountLength {
+		return nil, fmt.Errorf("unexpected balanceOf output %s", call.Data)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
 // GetBlockByNumberInput is the input to the call
 // method "eth_getBlockByNumber".
 type GetBlockByNumberInput struct {
@@ -1591,6 +2742,31 @@ type txPool map[string]txPoolInner
 
 type txPoolInner map[string]rpcTransaction
 
+const (
+	// txPoolPending is the TxPool of transactions
+	// that are ready to be included in a block.
+	txPoolPending = "pending"
+
+	// txPoolQueued is the TxPool of transactions
+	// that are waiting on a nonce gap to be filled.
+	txPoolQueued = "queued"
+)
+
+// transaction returns the *rpcTransaction in the txPool
+// with the provided hash.
+func (p txPool) transaction(hash common.Hash) (*rpcTransaction, bool) {
+	for _, inner := range p {
+		for _, info := range inner {
+			if info.tx.Hash() == hash {
+				tx := info
+				return &tx, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
 // GetMempool get and returns all the transactions on Ethereum TxPool (pending and queued).
 func (ec *Client) GetMempool(ctx context.Context) (*RosettaTypes.MempoolResponse, error) {
 	var response txPoolContentResponse
@@ -1618,3 +2794,355 @@ func (ec *Client) GetMempool(ctx context.Context) (*RosettaTypes.MempoolResponse
 
 	return &RosettaTypes.MempoolResponse{TransactionIdentifiers: identifiers}, nil
 }
+
+// GetMempoolTransaction returns the pending or queued transaction
+// in the Ethereum TxPool with the provided hash. As the transaction
+// has not been executed yet, its operations are estimated from the
+// transaction fields.
+func (ec *Client) GetMempoolTransaction(
+	ctx context.Context,
+	hash string,
+) (*RosettaTypes.Transaction, error) {
+	var response txPoolContentResponse
+	if err := ec.c.CallContext(ctx, &response, "txpool_content"); err != nil {
+		return nil, err
+	}
+
+	txHash := common.HexToHash(hash)
+	if tx, ok := response.Pending.transaction(txHash); ok {
+		return ec.mempoolTransaction(tx, txPoolPending)
+	}
+
+	if tx, ok := response.Queued.transaction(txHash); ok {
+		return ec.mempoolTransaction(tx, txPoolQueued)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ethereum.NotFound, hash)
+}
+
+// AccountState is the pending-aware state of an account returned
+// in the metadata of /account/coins. Balance changes are estimated
+// from the operations of the pending transactions sent by or to the
+// account (fees are estimated as the maximum the sender could pay).
+// Queued transactions cannot be included until the gap between the
+// pending nonce and their nonce is filled, so they do not change
+// the pending balances or nonce.
+type AccountState struct {
+	// Balances are the confirmed balances of each currency.
+	Balances []*RosettaTypes.Amount `json:"balances"`
+
+	// Nonce is the confirmed nonce of the account.
+	Nonce int64 `json:"nonce"`
+
+	// PendingBalanceChanges are the estimated changes of each
+	// balance once all pending transactions are included.
+	PendingBalanceChanges []*RosettaTypes.Amount `json:"pending_balance_changes"`
+
+	// PendingNonce is the nonce of the next transaction of the
+	// account (PendingNonceAt). It is lower than the nonce of any
+	// queued transaction.
+	PendingNonce int64 `json:"pending_nonce"`
+
+	// PendingTransactions are the hashes of the pending
+	// transactions sent by the account, ordered by nonce.
+	PendingTransactions []string `json:"pending_transactions"`
+
+	// QueuedTransactions are the hashes of the queued transactions
+	// sent by the account, ordered by nonce. These transactions are
+	// stuck until transactions filling the nonce gap are sent.
+	QueuedTransactions []string `json:"queued_transactions"`
+}
+
+// AccountCoins returns the state of an account at the current
+// head and after its transactions in the TxPool are included. As
+// Ethereum is not UTXO-based, no coins are returned. Instead, the
+// metadata contains an *AccountState.
+func (ec *Client) AccountCoins(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	currencies []*RosettaTypes.Currency,
+) (*RosettaTypes.AccountCoinsResponse, error) {
+	if account.SubAccount != nil {
+		return nil, fmt.Errorf(
+			"%w: coins of sub-account %s are not supported",
+			ErrInvalidSubAccount,
+			account.SubAccount.Address,
+		)
+	}
+
+	// The balance, nonce and TxPool are fetched from the
+	// same node so that the pending state is consistent
+	// with the state at head.
+	var coins *RosettaTypes.AccountCoinsResponse
+	err := ec.pinNode(ctx, func(ctx context.Context) error {
+		var err error
+		coins, err = ec.accountCoins(ctx, account, currencies)
+		return err
+	})
+
+	return coins, err
+}
+
+// accountCoins fetches the state of an account at the
+// current head and its pending state in the TxPool.
+func (ec *Client) accountCoins(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	currencies []*RosettaTypes.Currency,
+) (*RosettaTypes.AccountCoinsResponse, error) {
+	balance, err := ec.Balance(ctx, account, nil, currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ok := balance.Metadata["nonce"].(int64)
+	if !ok {
+		return nil, fmt.Errorf("could not get nonce of %s", account.Address)
+	}
+
+	state, err := ec.pendingAccount(ctx, account, balance.Balances)
+	if err != nil {
+		return nil, err
+	}
+	state.Balances = balance.Balances
+	state.Nonce = nonce
+
+	metadata, err := RosettaTypes.MarshalMap(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RosettaTypes.AccountCoinsResponse{
+		BlockIdentifier: balance.BlockIdentifier,
+		Coins:           []*RosettaTypes.Coin{},
+		Metadata:        metadata,
+	}, nil
+}
+
+// pendingAccount returns the pending state of an account
+// with the TxPool transactions sent by or to the account.
+func (ec *Client) pendingAccount(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	balances []*RosettaTypes.Amount,
+) (*AccountState, error) {
+	address := common.HexToAddress(account.Address)
+	pendingNonce, err := ec.PendingNonceAt(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get pending nonce", err)
+	}
+
+	var response txPoolContentResponse
+	if err := ec.c.CallContext(ctx, &response, "txpool_content"); err != nil {
+		return nil, err
+	}
+
+	// Balance changes are estimated from the operations of each
+	// pending transaction. Queued transactions cannot be included
+	// until their nonce gap is filled, so they are ignored.
+	deltas := make(map[string]*big.Int, len(balances))
+	for _, amount := range balances {
+		deltas[RosettaTypes.Hash(amount.Currency)] = new(big.Int)
+	}
+	for _, inner := range response.Pending {
+		for _, info := range inner {
+			// Transactions without a sender cannot be attributed
+			// to an account, so they are skipped like in
+			// sentTransactions.
+			tx := info
+			mempoolTx, err := ec.mempoolTransaction(&tx, txPoolPending)
+			if err != nil {
+				continue
+			}
+
+			for _, op := range mempoolTx.Operations {
+				if common.HexToAddress(op.Account.Address) != address {
+					continue
+				}
+
+				delta, ok := deltas[RosettaTypes.Hash(op.Amount.Currency)]
+				if !ok {
+					continue
+				}
+
+				value, ok := new(big.Int).SetString(op.Amount.Value, 10) // nolint:gomnd
+				if !ok {
+					return nil, fmt.Errorf("could not parse amount %s", op.Amount.Value)
+				}
+				delta.Add(delta, value)
+			}
+		}
+	}
+
+	changes := make([]*RosettaTypes.Amount, len(balances))
+	for i, amount := range balances {
+		changes[i] = &RosettaTypes.Amount{
+			Value:    deltas[RosettaTypes.Hash(amount.Currency)].String(),
+			Currency: amount.Currency,
+		}
+	}
+
+	return &AccountState{
+		PendingBalanceChanges: changes,
+		PendingNonce:          int64(pendingNonce),
+		PendingTransactions:   sentTransactions(response.Pending, address),
+		QueuedTransactions:    sentTransactions(response.Queued, address),
+	}, nil
+}
+
+// sentTransactions returns the hashes of the transactions
+// in the pool sent by address, ordered by nonce.
+func sentTransactions(pool txPool, address common.Address) []string {
+	var transactions []*types.Transaction
+	for _, inner := range pool {
+		for _, info := range inner {
+			if info.From == nil || *info.From != address {
+				continue
+			}
+
+			transactions = append(transactions, info.tx)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Nonce() < transactions[j].Nonce()
+	})
+
+	hashes := make([]string, len(transactions))
+	for i, tx := range transactions {
+		hashes[i] = tx.Hash().Hex()
+	}
+
+	return hashes
+}
+
+// mempoolTransaction returns the *RosettaTypes.Transaction of a
+// transaction in the TxPool. The fee is estimated as the maximum
+// the sender could pay and operations do not have a status. It
+// returns an error if the sender of the transaction is missing.
+func (ec *Client) mempoolTransaction(
+	tx *rpcTransaction,
+	pool string,
+) (*RosettaTypes.Transaction, error) {
+	if tx.From == nil {
+		return nil, fmt.Errorf("sender of transaction %s is missing", tx.tx.Hash().Hex())
+	}
+
+	from := MustChecksum(tx.From.Hex())
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.tx.Gas()), tx.tx.GasPrice())
+	ops := []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: 0,
+			},
+			Type: FeeOpType,
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: from,
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    new(big.Int).Neg(fee).String(),
+				Currency: Currency,
+			},
+		},
+	}
+
+	opType := CallOpType
+	var to common.Address
+	if tx.tx.To() == nil {
+		opType = CreateOpType
+		to = crypto.CreateAddress(*tx.From, tx.tx.Nonce())
+	} else {
+		to = *tx.tx.To()
+	}
+
+	if tx.tx.Value().Sign() > 0 {
+		ops = append(ops, transferOps(
+			opType,
+			from,
+			MustChecksum(to.Hex()),
+			tx.tx.Value(),
+			Currency,
+			len(ops),
+		)...)
+	}
+
+	// Token transfers are estimated from the transfer calldata.
+	if currency, ok := ec.tokens.Currency(to); ok {
+		recipient, amount, ok := ParseERC20TransferData(tx.tx.Data())
+		if ok && amount.Sign() > 0 {
+			ops = append(ops, transferOps(
+				ERC20TransferOpType,
+				from,
+				MustChecksum(recipient.Hex()),
+				amount,
+				currency,
+				len(ops),
+			)...)
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"from":      from,
+		"gas_limit": hexutil.EncodeUint64(tx.tx.Gas()),
+		"gas_price": hexutil.EncodeBig(tx.tx.GasPrice()),
+		"nonce":     hexutil.EncodeUint64(tx.tx.Nonce()),
+		"pool":      pool,
+	}
+	if tx.tx.Type() == eip1559TxType {
+		metadata["max_priority_fee_per_gas"] = hexutil.EncodeBig(tx.tx.GasTipCap())
+		metadata["max_fee_per_gas"] = hexutil.EncodeBig(tx.tx.GasFeeCap())
+	}
+
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: tx.tx.Hash().Hex(),
+		},
+		Operations: ops,
+		Metadata:   metadata,
+	}, nil
+}
+
+// transferOps returns the debit and credit *RosettaTypes.Operation
+// of a transfer without a status, starting at startIndex.
+func transferOps(
+	opType string,
+	from string,
+	to string,
+	amount *big.Int,
+	currency *RosettaTypes.Currency,
+	startIndex int,
+) []*RosettaTypes.Operation {
+	fromIndex := int64(startIndex)
+	return []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: fromIndex,
+			},
+			Type: opType,
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: from,
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    new(big.Int).Neg(amount).String(),
+				Currency: currency,
+			},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: fromIndex + 1,
+			},
+			RelatedOperations: []*RosettaTypes.OperationIdentifier{
+				{
+					Index: fromIndex,
+				},
+			},
+			Type: opType,
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: to,
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    amount.String(),
+				Currency: currency,
+			},
+		},
+	}
+}
@@ -18,11 +18,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"reflect"
 	"sort"
+	"sync/atomic"
 	"testing"
 
 	mocks "github.com/coinbase/rosetta-ethereum/mocks/ethereum"
@@ -32,7 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -96,11 +97,11 @@ func TestStatus_NotSyncing(t *testing.T) {
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			header := args.Get(1).(**types.Header)
+			header := args.Get(1).(**rpcHeader)
 			file, err := ioutil.ReadFile("testdata/basic_header.json")
 			assert.NoError(t, err)
 
-			*header = new(types.Header)
+			*header = new(rpcHeader)
 
 			assert.NoError(t, (*header).UnmarshalJSON(file))
 		},
@@ -217,11 +218,11 @@ func TestStatus_NotSyncing_SkipAdminCalls(t *testing.T) {
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			header := args.Get(1).(**types.Header)
+			header := args.Get(1).(**rpcHeader)
 			file, err := ioutil.ReadFile("testdata/basic_header.json")
 			assert.NoError(t, err)
 
-			*header = new(types.Header)
+			*header = new(rpcHeader)
 
 			assert.NoError(t, (*header).UnmarshalJSON(file))
 		},
@@ -293,11 +294,11 @@ func TestStatus_Syncing(t *testing.T) {
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			header := args.Get(1).(**types.Header)
+			header := args.Get(1).(**rpcHeader)
 			file, err := ioutil.ReadFile("testdata/basic_header.json")
 			assert.NoError(t, err)
 
-			*header = new(types.Header)
+			*header = new(rpcHeader)
 
 			assert.NoError(t, (*header).UnmarshalJSON(file))
 		},
@@ -419,11 +420,11 @@ func TestStatus_Syncing_SkipAdminCalls(t *testing.T) {
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			header := args.Get(1).(**types.Header)
+			header := args.Get(1).(**rpcHeader)
 			file, err := ioutil.ReadFile("testdata/basic_header.json")
 			assert.NoError(t, err)
 
-			*header = new(types.Header)
+			*header = new(rpcHeader)
 
 			assert.NoError(t, (*header).UnmarshalJSON(file))
 		},
@@ -518,6 +519,7 @@ func TestBalance(t *testing.T) {
 			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
 		},
 		nil,
+		nil,
 	)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
@@ -541,26 +543,36 @@ func TestBalance(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBalance_Historical_Hash(t *testing.T) {
+func TestBalance_Token(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	tokens, err := NewTokenRegistry([]*Token{
+		{
+			Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	})
+	assert.NoError(t, err)
+
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
+		tokens:         tokens,
 	}
 
 	ctx := context.Background()
 	result, err := ioutil.ReadFile(
-		"testdata/account_balance_0x4cfc400fed52f9681b42454c2db4b18ab98f8de1.json",
+		"testdata/account_balance_token_0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55.json",
 	)
 	assert.NoError(t, err)
 	mockGraphQL.On(
 		"Query",
 		ctx,
 		`{
-			block(hash: "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda"){
+			block(){
 				hash
 				number
 				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
@@ -568,6 +580,10 @@ func TestBalance_Historical_Hash(t *testing.T) {
 					transactionCount
 					code
 				}
+				token1: call(data:{to:"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",data:"0x70a082310000000000000000000000002f93b2f047e05cdf602820ac4b3178efc2b43d55"}){
+					data
+					status
+				}
 			}
 		}`,
 	).Return(
@@ -575,17 +591,14 @@ func TestBalance_Historical_Hash(t *testing.T) {
 		nil,
 	).Once()
 
+	usdc := tokens.Currencies()[0]
 	resp, err := c.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
 			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
 		},
-		&RosettaTypes.PartialBlockIdentifier{
-			Hash: RosettaTypes.String(
-				"0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
-			),
-			Index: RosettaTypes.Int64(8165),
-		},
+		nil,
+		nil,
 	)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
@@ -597,6 +610,10 @@ func TestBalance_Historical_Hash(t *testing.T) {
 				Value:    "10372550232136640000000",
 				Currency: Currency,
 			},
+			{
+				Value:    "1000000",
+				Currency: usdc,
+			},
 		},
 		Metadata: map[string]interface{}{
 			"code":  "0x",
@@ -609,49 +626,95 @@ func TestBalance_Historical_Hash(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBalance_Historical_Index(t *testing.T) {
+func TestBalance_RPC(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	tokens, err := NewTokenRegistry([]*Token{
+		{
+			Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	})
+	assert.NoError(t, err)
+
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
+		balanceAPI:     RPCBalanceAPI,
 		traceSemaphore: semaphore.NewWeighted(100),
+		tokens:         tokens,
 	}
 
 	ctx := context.Background()
-	result, err := ioutil.ReadFile(
-		"testdata/account_balance_0x4cfc400fed52f9681b42454c2db4b18ab98f8de1.json",
-	)
-	assert.NoError(t, err)
-	mockGraphQL.On(
-		"Query",
+	blockHash := common.HexToHash("0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda")
+	mockJSONRPC.On(
+		"CallContext",
 		ctx,
-		`{
-			block(number: 8165){
-				hash
-				number
-				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
-					balance
-					transactionCount
-					code
-				}
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x1fe5",
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(**rpcBlockIdentifier)
+
+			*r = &rpcBlockIdentifier{
+				Hash:   blockHash,
+				Number: 8165,
 			}
-		}`,
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
 	).Return(
-		string(result),
 		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 4)
+			address := common.HexToAddress("0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55")
+			blockArg := &eip1898Block{
+				BlockHash:        blockHash,
+				RequireCanonical: true,
+			}
+			for i, method := range []string{
+				"eth_getBalance",
+				"eth_getTransactionCount",
+				"eth_getCode",
+			} {
+				assert.Equal(t, method, r[i].Method)
+				assert.Equal(t, []interface{}{address, blockArg}, r[i].Args)
+			}
+			assert.Equal(t, "eth_call", r[3].Method)
+			assert.Equal(t, blockArg, r[3].Args[1])
+
+			*(r[0].Result.(*hexutil.Big)) = *(*hexutil.Big)(big.NewInt(1000000000000000000))
+			*(r[1].Result.(*hexutil.Uint64)) = 12
+			*(r[2].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+			*(r[3].Result.(*hexutil.Bytes)) = common.LeftPadBytes(big.NewInt(2500000).Bytes(), 32)
+		},
 	).Once()
 
+	usdc := tokens.Currencies()[0]
+	index := int64(8165)
 	resp, err := c.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
 			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
 		},
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(8165),
+			Index: &index,
 		},
+		[]*RosettaTypes.Currency{Currency, usdc},
 	)
+	assert.NoError(t, err)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
 			Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
@@ -659,91 +722,170 @@ func TestBalance_Historical_Index(t *testing.T) {
 		},
 		Balances: []*RosettaTypes.Amount{
 			{
-				Value:    "10372550232136640000000",
+				Value:    "1000000000000000000",
 				Currency: Currency,
 			},
+			{
+				Value:    "2500000",
+				Currency: usdc,
+			},
 		},
 		Metadata: map[string]interface{}{
 			"code":  "0x",
-			"nonce": int64(0),
+			"nonce": int64(12),
 		},
 	}, resp)
-	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBalance_InvalidAddress(t *testing.T) {
-	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
+func TestBalance_RPC_Pinned(t *testing.T) {
+	mockJSONRPC1 := &mocks.JSONRPC{}
+	mockJSONRPC2 := &mocks.JSONRPC{}
+	pool := newNodePool([]*node{
+		{url: "http://node-1:8545", c: mockJSONRPC1},
+		{url: "http://node-2:8545", c: mockJSONRPC2},
+	})
+	pool.nodes[1].setHealthy(false)
 
 	c := &Client{
-		c:              mockJSONRPC,
-		g:              mockGraphQL,
+		c:              pool,
+		nodes:          pool,
+		balanceAPI:     RPCBalanceAPI,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
-	result, err := ioutil.ReadFile("testdata/account_balance_invalid.json")
-	assert.NoError(t, err)
-	mockGraphQL.On(
-		"Query",
-		ctx,
-		`{
-			block(){
-				hash
-				number
-				account(address:"0x4cfc400fed52f9681b42454c2db4b18ab98f8de"){
-					balance
-					transactionCount
-					code
+	address := common.HexToAddress("0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55")
+	staleHash := common.HexToHash("0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda")
+	blockHash := common.HexToHash("0x48269a339ce1489cff6bab70eff432289c4f490b81dbd00ff1f81c68de06b842")
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	mockBlock := func(m *mocks.JSONRPC, hash common.Hash) {
+		m.On(
+			"CallContext",
+			mock.Anything,
+			mock.Anything,
+			"eth_getBlockByNumber",
+			"latest",
+			false,
+		).Return(
+			nil,
+		).Run(
+			func(args mock.Arguments) {
+				r := args.Get(1).(**rpcBlockIdentifier)
+
+				*r = &rpcBlockIdentifier{
+					Hash:   hash,
+					Number: 8165,
 				}
-			}
-		}`,
+			},
+		).Once()
+	}
+
+	// The batch fails on the first node after the block lookup
+	// succeeded, so both are retried on the second node
+	mockBlock(mockJSONRPC1, staleHash)
+	mockJSONRPC1.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		unreachable,
+	).Once()
+	mockBlock(mockJSONRPC2, blockHash)
+	mockJSONRPC2.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
 	).Return(
-		string(result),
 		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 3)
+			for i := range r {
+				assert.Equal(t, []interface{}{address, &eip1898Block{
+					BlockHash:        blockHash,
+					RequireCanonical: true,
+				}}, r[i].Args)
+			}
+
+			*(r[0].Result.(*hexutil.Big)) = *(*hexutil.Big)(big.NewInt(1000000000000000000))
+			*(r[1].Result.(*hexutil.Uint64)) = 12
+			*(r[2].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+		},
 	).Once()
 
 	resp, err := c.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
-			Address: "0x4cfc400fed52f9681b42454c2db4b18ab98f8de",
+			Address: address.Hex(),
 		},
 		nil,
+		nil,
 	)
-	assert.Nil(t, resp)
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  blockHash.Hex(),
+			Index: 8165,
+		},
+		Balances: []*RosettaTypes.Amount{
+			{
+				Value:    "1000000000000000000",
+				Currency: Currency,
+			},
+		},
+		Metadata: map[string]interface{}{
+			"code":  "0x",
+			"nonce": int64(12),
+		},
+	}, resp)
+	assert.False(t, pool.nodes[0].isHealthy())
 
-	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
+	mockJSONRPC1.AssertExpectations(t)
+	mockJSONRPC2.AssertExpectations(t)
 }
 
-func TestBalance_InvalidHash(t *testing.T) {
+func TestBalance_SubAccount(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	tokens, err := NewTokenRegistry([]*Token{
+		{
+			Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	})
+	assert.NoError(t, err)
+
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
+		tokens:         tokens,
 	}
 
 	ctx := context.Background()
-	result, err := ioutil.ReadFile("testdata/account_balance_invalid_block.json")
+	result, err := ioutil.ReadFile(
+		"testdata/account_balance_storage_0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55.json",
+	)
 	assert.NoError(t, err)
 	mockGraphQL.On(
 		"Query",
 		ctx,
 		`{
-			block(hash: "0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626"){
+			block(){
 				hash
 				number
 				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
 					balance
 					transactionCount
 					code
+					storage(slot:"0x0000000000000000000000000000000000000000000000000000000000000003")
 				}
 			}
 		}`,
@@ -752,111 +894,188 @@ func TestBalance_InvalidHash(t *testing.T) {
 		nil,
 	).Once()
 
+	usdc := tokens.Currencies()[0]
 	resp, err := c.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
 			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+			SubAccount: &RosettaTypes.SubAccountIdentifier{
+				Address: "0x0000000000000000000000000000000000000000000000000000000000000003",
+			},
 		},
-		&RosettaTypes.PartialBlockIdentifier{
-			Hash: RosettaTypes.String(
-				"0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626",
-			),
-		},
+		nil,
+		[]*RosettaTypes.Currency{usdc},
 	)
-	assert.Nil(t, resp)
-	assert.Error(t, err)
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+			Index: 8165,
+		},
+		Balances: []*RosettaTypes.Amount{
+			{
+				Value:    "1000000",
+				Currency: usdc,
+			},
+		},
+		Metadata: map[string]interface{}{
+			"code":  "0x",
+			"nonce": int64(0),
+		},
+	}, resp)
+	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_GetBlockByNumber(t *testing.T) {
+func TestBalance_SubAccount_RPC(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
+		balanceAPI:     RPCBalanceAPI,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
-
+	blockHash := common.HexToHash("0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda")
+	slot := common.HexToHash("0x3")
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x2af0",
+		"latest",
 		false,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*map[string]interface{})
-
-			file, err := ioutil.ReadFile("testdata/block_10992.json")
-			assert.NoError(t, err)
+			r := args.Get(1).(**rpcBlockIdentifier)
 
-			err = json.Unmarshal(file, r)
-			assert.NoError(t, err)
+			*r = &rpcBlockIdentifier{
+				Hash:   blockHash,
+				Number: 8165,
+			}
 		},
 	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_10992.json")
-	assert.NoError(t, err)
-	var correct map[string]interface{}
-	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
+			assert.Len(t, r, 4)
+			assert.Equal(t, "eth_getStorageAt", r[3].Method)
+			assert.Equal(t, []interface{}{
+				common.HexToAddress("0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"),
+				&slot,
+				&eip1898Block{
+					BlockHash:        blockHash,
+					RequireCanonical: true,
+				},
+			}, r[3].Args)
 
-	resp, err := c.Call(
+			*(r[0].Result.(*hexutil.Big)) = *(*hexutil.Big)(big.NewInt(1000000000000000000))
+			*(r[1].Result.(*hexutil.Uint64)) = 12
+			*(r[2].Result.(*hexutil.Bytes)) = hexutil.Bytes{0x60}
+			*(r[3].Result.(*hexutil.Bytes)) = common.LeftPadBytes(big.NewInt(2500000).Bytes(), 32)
+		},
+	).Once()
+
+	resp, err := c.Balance(
 		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_getBlockByNumber",
-			Parameters: map[string]interface{}{
-				"index":                    RosettaTypes.Int64(10992),
-				"show_transaction_details": false,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+			SubAccount: &RosettaTypes.SubAccountIdentifier{
+				Address: slot.Hex(),
 			},
 		},
+		nil,
+		nil,
 	)
-	assert.Equal(t, &RosettaTypes.CallResponse{
-		Result:     correct,
-		Idempotent: false,
-	}, resp)
 	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+			Index: 8165,
+		},
+		Balances: []*RosettaTypes.Amount{
+			{
+				Value:    "2500000",
+				Currency: Currency,
+			},
+		},
+		Metadata: map[string]interface{}{
+			"code":  "0x60",
+			"nonce": int64(12),
+		},
+	}, resp)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_GetBlockByNumber_InvalidArgs(t *testing.T) {
+func TestBalance_InvalidSubAccount(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	tokens, err := NewTokenRegistry([]*Token{
+		{
+			Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	})
+	assert.NoError(t, err)
+
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
+		tokens:         tokens,
 	}
 
 	ctx := context.Background()
-	resp, err := c.Call(
+	resp, err := c.Balance(
 		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_getBlockByNumber",
-			Parameters: map[string]interface{}{
-				"index":                    "a string",
-				"show_transaction_details": false,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+			SubAccount: &RosettaTypes.SubAccountIdentifier{
+				Address: "0x3",
 			},
 		},
+		nil,
+		nil,
 	)
 	assert.Nil(t, resp)
-	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+	assert.True(t, errors.Is(err, ErrInvalidSubAccount))
+
+	resp, err = c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+			SubAccount: &RosettaTypes.SubAccountIdentifier{
+				Address: "0x0000000000000000000000000000000000000000000000000000000000000003",
+			},
+		},
+		nil,
+		[]*RosettaTypes.Currency{Currency, tokens.Currencies()[0]},
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrInvalidSubAccount))
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_GetTransactionReceipt(t *testing.T) {
+func TestBalance_UnsupportedCurrency(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -867,61 +1086,27 @@ func TestCall_GetTransactionReceipt(t *testing.T) {
 	}
 
 	ctx := context.Background()
-
-	mockJSONRPC.On(
-		"CallContext",
+	resp, err := c.Balance(
 		ctx,
-		mock.Anything,
-		"eth_getTransactionReceipt",
-		common.HexToHash("0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d"),
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(**types.Receipt)
-
-			file, err := ioutil.ReadFile(
-				"testdata/call_0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d.json",
-			)
-			assert.NoError(t, err)
-
-			*r = new(types.Receipt)
-
-			assert.NoError(t, (*r).UnmarshalJSON(file))
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
 		},
-	).Once()
-	resp, err := c.Call(
-		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_getTransactionReceipt",
-			Parameters: map[string]interface{}{
-				"tx_hash": "0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d",
+		nil,
+		[]*RosettaTypes.Currency{
+			{
+				Symbol:   "USDC",
+				Decimals: 6,
 			},
 		},
 	)
-	assert.Equal(t, &RosettaTypes.CallResponse{
-		Result: map[string]interface{}{
-			"blockHash":         "0x928b4d7d1ab8fcb2f62ffa7bba7a1a52251a1145ffc0faec3e009535ba4a2669",
-			"blockNumber":       "0x7edcff",
-			"contractAddress":   "0x0000000000000000000000000000000000000000",
-			"cumulativeGasUsed": "0x744f1b",
-			"gasUsed":           "0x5208",
-			"logs":              []interface{}{},
-			"logsBloom":         "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", // nolint
-			"root":              "0x",
-			"status":            "0x1",
-			"transactionHash":   "0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d",
-			"transactionIndex":  "0x21",
-		},
-		Idempotent: false,
-	}, resp)
-	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCurrencyNotSupported))
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_GetTransactionReceipt_InvalidArgs(t *testing.T) {
+func TestBalance_Historical_Hash(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -932,20 +1117,65 @@ func TestCall_GetTransactionReceipt_InvalidArgs(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	resp, err := c.Call(
+	result, err := ioutil.ReadFile(
+		"testdata/account_balance_0x4cfc400fed52f9681b42454c2db4b18ab98f8de1.json",
+	)
+	assert.NoError(t, err)
+	mockGraphQL.On(
+		"Query",
 		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_getTransactionReceipt",
+		`{
+			block(hash: "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda"){
+				hash
+				number
+				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`,
+	).Return(
+		string(result),
+		nil,
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+		},
+		&RosettaTypes.PartialBlockIdentifier{
+			Hash: RosettaTypes.String(
+				"0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+			),
+			Index: RosettaTypes.Int64(8165),
 		},
+		nil,
 	)
-	assert.Nil(t, resp)
-	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+			Index: 8165,
+		},
+		Balances: []*RosettaTypes.Amount{
+			{
+				Value:    "10372550232136640000000",
+				Currency: Currency,
+			},
+		},
+		Metadata: map[string]interface{}{
+			"code":  "0x",
+			"nonce": int64(0),
+		},
+	}, resp)
+	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_Call(t *testing.T) {
+func TestBalance_Historical_Index(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -956,53 +1186,54 @@ func TestCall_Call(t *testing.T) {
 	}
 
 	ctx := context.Background()
-
-	mockJSONRPC.On(
-		"CallContext",
+	result, err := ioutil.ReadFile(
+		"testdata/account_balance_0x4cfc400fed52f9681b42454c2db4b18ab98f8de1.json",
+	)
+	assert.NoError(t, err)
+	mockGraphQL.On(
+		"Query",
 		ctx,
-		mock.Anything,
-		"eth_call",
-		map[string]string{
-			"to":   "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
-			"data": "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
-		},
-		toBlockNumArg(big.NewInt(11408349)),
+		`{
+			block(number: 8165){
+				hash
+				number
+				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`,
 	).Return(
+		string(result),
 		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*string)
-
-			var expected map[string]interface{}
-			file, err := ioutil.ReadFile("testdata/call_balance_11408349.json")
-			assert.NoError(t, err)
-
-			err = json.Unmarshal(file, &expected)
-			assert.NoError(t, err)
-
-			*r = expected["data"].(string)
-		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/call_balance_11408349.json")
-	assert.NoError(t, err)
-	var correct map[string]interface{}
-	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
-
-	resp, err := c.Call(
+	resp, err := c.Balance(
 		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_call",
-			Parameters: map[string]interface{}{
-				"index": 11408349,
-				"to":    "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
-				"data":  "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
-			},
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+		},
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(8165),
 		},
+		nil,
 	)
-	assert.Equal(t, &RosettaTypes.CallResponse{
-		Result:     correct,
-		Idempotent: false,
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+			Index: 8165,
+		},
+		Balances: []*RosettaTypes.Amount{
+			{
+				Value:    "10372550232136640000000",
+				Currency: Currency,
+			},
+		},
+		Metadata: map[string]interface{}{
+			"code":  "0x",
+			"nonce": int64(0),
+		},
 	}, resp)
 	assert.NoError(t, err)
 
@@ -1010,7 +1241,7 @@ func TestCall_Call(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_Call_InvalidArgs(t *testing.T) {
+func TestBalance_InvalidAddress(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1021,26 +1252,43 @@ func TestCall_Call_InvalidArgs(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	resp, err := c.Call(
+	result, err := ioutil.ReadFile("testdata/account_balance_invalid.json")
+	assert.NoError(t, err)
+	mockGraphQL.On(
+		"Query",
 		ctx,
-		&RosettaTypes.CallRequest{
-			Method: "eth_call",
-			Parameters: map[string]interface{}{
-				"index": 11408349,
-				"Hash":  "0x73fc065bc04f16c98247f8ec1e990f581ec58723bcd8059de85f93ab18706448",
-				"to":    "not valid  ",
-				"data":  "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
-			},
+		`{
+			block(){
+				hash
+				number
+				account(address:"0x4cfc400fed52f9681b42454c2db4b18ab98f8de"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`,
+	).Return(
+		string(result),
+		nil,
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x4cfc400fed52f9681b42454c2db4b18ab98f8de",
 		},
+		nil,
+		nil,
 	)
 	assert.Nil(t, resp)
-	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+	assert.Error(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_EstimateGas(t *testing.T) {
+func TestBalance_InvalidHash(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1051,36 +1299,80 @@ func TestCall_EstimateGas(t *testing.T) {
 	}
 
 	ctx := context.Background()
-
-	mockJSONRPC.On(
-		"CallContext",
+	result, err := ioutil.ReadFile("testdata/account_balance_invalid_block.json")
+	assert.NoError(t, err)
+	mockGraphQL.On(
+		"Query",
 		ctx,
-		mock.Anything,
-		"eth_estimateGas",
-		map[string]string{
-			"from": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
-			"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
-			"data": "0xa9059cbb000000000000000000000000ae7e48ee0f758cd706b76cf7e2175d982800879a" +
-				"00000000000000000000000000000000000000000000000000521c5f98b8ea00",
-		},
+		`{
+			block(hash: "0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626"){
+				hash
+				number
+				account(address:"0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`,
+	).Return(
+		string(result),
+		nil,
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{
+			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
+		},
+		&RosettaTypes.PartialBlockIdentifier{
+			Hash: RosettaTypes.String(
+				"0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626",
+			),
+		},
+		nil,
+	)
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetBlockByNumber(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x2af0",
+		false,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*string)
+			r := args.Get(1).(*map[string]interface{})
 
-			var expected map[string]interface{}
-			file, err := ioutil.ReadFile("testdata/estimate_gas_0xaD6D458402F60fD3Bd25163575031ACDce07538D.json")
+			file, err := ioutil.ReadFile("testdata/block_10992.json")
 			assert.NoError(t, err)
 
-			err = json.Unmarshal(file, &expected)
+			err = json.Unmarshal(file, r)
 			assert.NoError(t, err)
-
-			*r = expected["data"].(string)
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/estimate_gas_0xaD6D458402F60fD3Bd25163575031ACDce07538D.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_10992.json")
 	assert.NoError(t, err)
 	var correct map[string]interface{}
 	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
@@ -1088,12 +1380,10 @@ func TestCall_EstimateGas(t *testing.T) {
 	resp, err := c.Call(
 		ctx,
 		&RosettaTypes.CallRequest{
-			Method: "eth_estimateGas",
+			Method: "eth_getBlockByNumber",
 			Parameters: map[string]interface{}{
-				"from": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
-				"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
-				"data": "0xa9059cbb000000000000000000000000ae7e48ee0f758cd706b76cf7e2175d982800879a" +
-					"00000000000000000000000000000000000000000000000000521c5f98b8ea00",
+				"index":                    RosettaTypes.Int64(10992),
+				"show_transaction_details": false,
 			},
 		},
 	)
@@ -1107,7 +1397,7 @@ func TestCall_EstimateGas(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
+func TestCall_GetBlockByNumber_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1121,10 +1411,10 @@ func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	resp, err := c.Call(
 		ctx,
 		&RosettaTypes.CallRequest{
-			Method: "eth_estimateGas",
+			Method: "eth_getBlockByNumber",
 			Parameters: map[string]interface{}{
-				"From": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
-				"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
+				"index":                    "a string",
+				"show_transaction_details": false,
 			},
 		},
 	)
@@ -1135,7 +1425,7 @@ func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestCall_InvalidMethod(t *testing.T) {
+func TestCall_GetTransactionReceipt(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1146,340 +1436,363 @@ func TestCall_InvalidMethod(t *testing.T) {
 	}
 
 	ctx := context.Background()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionReceipt",
+		common.HexToHash("0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d"),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(**types.Receipt)
+
+			file, err := ioutil.ReadFile(
+				"testdata/call_0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d.json",
+			)
+			assert.NoError(t, err)
+
+			*r = new(types.Receipt)
+
+			assert.NoError(t, (*r).UnmarshalJSON(file))
+		},
+	).Once()
 	resp, err := c.Call(
 		ctx,
 		&RosettaTypes.CallRequest{
-			Method: "blah",
+			Method: "eth_getTransactionReceipt",
+			Parameters: map[string]interface{}{
+				"tx_hash": "0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d",
+			},
 		},
 	)
-	assert.Nil(t, resp)
-	assert.True(t, errors.Is(err, ErrCallMethodInvalid))
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"blockHash":         "0x928b4d7d1ab8fcb2f62ffa7bba7a1a52251a1145ffc0faec3e009535ba4a2669",
+			"blockNumber":       "0x7edcff",
+			"contractAddress":   "0x0000000000000000000000000000000000000000",
+			"cumulativeGasUsed": "0x744f1b",
+			"gasUsed":           "0x5208",
+			"logs":              []interface{}{},
+			"logsBloom":         "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", // nolint
+			"root":              "0x",
+			"status":            "0x1",
+			"transactionHash":   "0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d",
+			"transactionIndex":  "0x21",
+		},
+		Idempotent: false,
+	}, resp)
+	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func testTraceConfig() (*tracers.TraceConfig, error) {
-	loadedFile, err := ioutil.ReadFile("call_tracer.js")
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not load tracer file", err)
-	}
-
-	loadedTracer := string(loadedFile)
-	return &tracers.TraceConfig{
-		Timeout: &tracerTimeout,
-		Tracer:  &loadedTracer,
-	}, nil
-}
-
-func TestBlock_Current(t *testing.T) {
+func TestCall_GetTransactionReceipt_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
-	tc, err := testTraceConfig()
-	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
-		tc:             tc,
-		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
-	mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"eth_getBlockByNumber",
-		"latest",
-		true,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := ioutil.ReadFile("testdata/block_10992.json")
-			assert.NoError(t, err)
-
-			*r = json.RawMessage(file)
-		},
-	).Once()
-	mockJSONRPC.On(
-		"CallContext",
+	resp, err := c.Call(
 		ctx,
-		mock.Anything,
-		"debug_traceBlockByHash",
-		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
-		tc,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
-			) // nolint
-			assert.NoError(t, err)
-
-			*r = json.RawMessage(file)
+		&RosettaTypes.CallRequest{
+			Method: "eth_getTransactionReceipt",
 		},
-	).Once()
-
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
-	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
-
-	resp, err := c.Block(
-		ctx,
-		nil,
 	)
-	assert.Equal(t, correct.Block, resp)
-	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBlock_Hash(t *testing.T) {
+func TestCall_Call(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
-	tc, err := testTraceConfig()
-	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
-		tc:             tc,
-		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
+
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getBlockByHash",
-		"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
-		true,
+		"eth_call",
+		map[string]string{
+			"to":   "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
+			"data": "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+		},
+		toBlockNumArg(big.NewInt(11408349)),
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
+			r := args.Get(1).(*string)
 
-			file, err := ioutil.ReadFile("testdata/block_10992.json")
+			var expected map[string]interface{}
+			file, err := ioutil.ReadFile("testdata/call_balance_11408349.json")
 			assert.NoError(t, err)
 
-			*r = json.RawMessage(file)
-		},
-	).Once()
-	mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"debug_traceBlockByHash",
-		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
-		tc,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
-			) // nolint
+			err = json.Unmarshal(file, &expected)
 			assert.NoError(t, err)
 
-			*r = json.RawMessage(file)
+			*r = expected["data"].(string)
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
+	correctRaw, err := ioutil.ReadFile("testdata/call_balance_11408349.json")
 	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockResponse
+	var correct map[string]interface{}
 	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
-	resp, err := c.Block(
+	resp, err := c.Call(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Hash: RosettaTypes.String(
-				"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
-			),
+		&RosettaTypes.CallRequest{
+			Method: "eth_call",
+			Parameters: map[string]interface{}{
+				"index": 11408349,
+				"to":    "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
+				"data":  "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+			},
 		},
 	)
-	assert.Equal(t, correct.Block, resp)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result:     correct,
+		Idempotent: false,
+	}, resp)
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBlock_Index(t *testing.T) {
+func TestCall_Call_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
-	tc, err := testTraceConfig()
-	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
-		tc:             tc,
-		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
-	mockJSONRPC.On(
-		"CallContext",
+	resp, err := c.Call(
 		ctx,
-		mock.Anything,
-		"eth_getBlockByNumber",
-		"0x2af0",
-		true,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
+		&RosettaTypes.CallRequest{
+			Method: "eth_call",
+			Parameters: map[string]interface{}{
+				"index": 11408349,
+				"Hash":  "0x73fc065bc04f16c98247f8ec1e990f581ec58723bcd8059de85f93ab18706448",
+				"to":    "not valid  ",
+				"data":  "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+			},
+		},
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
-			file, err := ioutil.ReadFile("testdata/block_10992.json")
-			assert.NoError(t, err)
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_EstimateGas(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
 
-			*r = json.RawMessage(file)
-		},
-	).Once()
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"debug_traceBlockByHash",
-		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
-		tc,
+		"eth_estimateGas",
+		map[string]string{
+			"from": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
+			"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
+			"data": "0xa9059cbb000000000000000000000000ae7e48ee0f758cd706b76cf7e2175d982800879a" +
+				"00000000000000000000000000000000000000000000000000521c5f98b8ea00",
+		},
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
+			r := args.Get(1).(*string)
 
-			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
-			) // nolint
+			var expected map[string]interface{}
+			file, err := ioutil.ReadFile("testdata/estimate_gas_0xaD6D458402F60fD3Bd25163575031ACDce07538D.json")
 			assert.NoError(t, err)
 
-			*r = json.RawMessage(file)
+			err = json.Unmarshal(file, &expected)
+			assert.NoError(t, err)
+
+			*r = expected["data"].(string)
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
+	correctRaw, err := ioutil.ReadFile("testdata/estimate_gas_0xaD6D458402F60fD3Bd25163575031ACDce07538D.json")
 	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockResponse
+	var correct map[string]interface{}
 	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
-	resp, err := c.Block(
+	resp, err := c.Call(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(10992),
+		&RosettaTypes.CallRequest{
+			Method: "eth_estimateGas",
+			Parameters: map[string]interface{}{
+				"from": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
+				"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
+				"data": "0xa9059cbb000000000000000000000000ae7e48ee0f758cd706b76cf7e2175d982800879a" +
+					"00000000000000000000000000000000000000000000000000521c5f98b8ea00",
+			},
 		},
 	)
-	assert.Equal(t, correct.Block, resp)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result:     correct,
+		Idempotent: false,
+	}, resp)
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestBlock_FirstBlock(t *testing.T) {
+func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
-	tc, err := testTraceConfig()
-	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
-		tc:             tc,
-		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
 	ctx := context.Background()
-	mockJSONRPC.On(
-		"CallContext",
+	resp, err := c.Call(
 		ctx,
-		mock.Anything,
-		"eth_getBlockByNumber",
-		"0x0",
-		true,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
+		&RosettaTypes.CallRequest{
+			Method: "eth_estimateGas",
+			Parameters: map[string]interface{}{
+				"From": "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
+				"to":   "0xaD6D458402F60fD3Bd25163575031ACDce07538D",
+			},
+		},
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
-			file, err := ioutil.ReadFile("testdata/block_0.json")
-			assert.NoError(t, err)
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
 
-			*r = file
-		},
-	).Once()
+func TestCall_InvalidMethod(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_0.json")
-	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
 
-	resp, err := c.Block(
+	ctx := context.Background()
+	resp, err := c.Call(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(0),
+		&RosettaTypes.CallRequest{
+			Method: "blah",
 		},
 	)
-	assert.Equal(t, correct.Block, resp)
-	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallMethodInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func jsonifyTransaction(b *RosettaTypes.Transaction) (*RosettaTypes.Transaction, error) {
-	bytes, err := json.Marshal(b)
-	if err != nil {
-		return nil, err
-	}
-
-	var tx RosettaTypes.Transaction
-	if err := json.Unmarshal(bytes, &tx); err != nil {
-		return nil, err
-	}
-
-	return &tx, nil
+func testTraceConfig() (*TraceConfig, error) {
+	return LoadJSTraceConfig("call_tracer.js")
 }
 
-func jsonifyBlock(b *RosettaTypes.Block) (*RosettaTypes.Block, error) {
-	bytes, err := json.Marshal(b)
-	if err != nil {
-		return nil, err
-	}
+func TestCall_UnmarshalJSON_NativeTracer(t *testing.T) {
+	raw := `{
+		"from": "0x1b0ff2e0e1b4d4e5e1f35e6ba1f02ce8e4a2f95a",
+		"gas": "0x30d40",
+		"gasUsed": "0x1d4c0",
+		"to": "0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a",
+		"input": "0x",
+		"value": "0x64",
+		"type": "CALL",
+		"calls": [
+			{
+				"from": "0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a",
+				"gas": "0x2710",
+				"gasUsed": "0x2710",
+				"to": "0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d",
+				"input": "0x",
+				"error": "execution reverted",
+				"type": "DELEGATECALL",
+				"logs": [
+					{
+						"address": "0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d",
+						"topics": [],
+						"data": "0x"
+					}
+				]
+			}
+		]
+	}`
+
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(raw), &call))
+	assert.Equal(t, "CALL", call.Type)
+	assert.Equal(t, big.NewInt(100), call.Value)
+	assert.Equal(t, big.NewInt(120000), call.GasUsed)
+	assert.False(t, call.Revert)
+	assert.Len(t, call.Calls, 1)
+
+	delegateCall := call.Calls[0]
+	assert.Equal(t, "DELEGATECALL", delegateCall.Type)
+	assert.Equal(t, 0, delegateCall.Value.Sign())
+	assert.True(t, delegateCall.Revert)
+	assert.Equal(t, "execution reverted", delegateCall.ErrorMessage)
+}
 
-	var bo RosettaTypes.Block
-	if err := json.Unmarshal(bytes, &bo); err != nil {
-		return nil, err
-	}
+func TestNativeTraceConfig(t *testing.T) {
+	tc, err := json.Marshal(NativeTraceConfig(nil))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"tracer":"callTracer","timeout":"120s"}`, string(tc))
 
-	return &bo, nil
+	tc, err = json.Marshal(NativeTraceConfig(&CallTracerConfig{WithLog: true}))
+	assert.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{"tracer":"callTracer","timeout":"120s","tracerConfig":{"withLog":true}}`,
+		string(tc),
+	)
 }
 
-func TestTransaction_Hash(t *testing.T) {
+func TestBlock_Current(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
-	txHash := "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4"
-	blockHash := "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d"
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
@@ -1496,76 +1809,99 @@ func TestTransaction_Hash(t *testing.T) {
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getTransactionByHash",
-		txHash,
+		"eth_getBlockByNumber",
+		"latest",
+		true,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile(
-				"testdata/transaction_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
-			) // nolint
+			file, err := ioutil.ReadFile("testdata/block_10992.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
 		},
 	).Once()
-
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getBlockByHash",
-		blockHash,
-		false,
+		"debug_traceBlockByHash",
+		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
+		tc,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(**types.Header)
+			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d.json",
+				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
 			) // nolint
 			assert.NoError(t, err)
 
-			*r = new(types.Header)
-
-			assert.NoError(t, (*r).UnmarshalJSON(file))
+			*r = json.RawMessage(file)
 		},
 	).Once()
 
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
+	assert.NoError(t, err)
+	var correct *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
+
+	resp, err := c.Block(
+		ctx,
+		nil,
+	)
+	assert.Equal(t, correct.Block, resp.Block)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestBlock_Hash(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getTransactionReceipt",
-		common.HexToHash(txHash),
+		"eth_getBlockByHash",
+		"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+		true,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(**types.Receipt)
+			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile(
-				"testdata/tx_receipt_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
-			) // nolint
+			file, err := ioutil.ReadFile("testdata/block_10992.json")
 			assert.NoError(t, err)
 
-			*r = new(types.Receipt)
-
-			assert.NoError(t, (*r).UnmarshalJSON(file))
+			*r = json.RawMessage(file)
 		},
 	).Once()
-
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"debug_traceTransaction",
-		common.HexToHash(txHash),
+		"debug_traceBlockByHash",
+		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
 		tc,
 	).Return(
 		nil,
@@ -1574,7 +1910,7 @@ func TestTransaction_Hash(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/transaction_trace_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
+				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -1582,34 +1918,27 @@ func TestTransaction_Hash(t *testing.T) {
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile(
-		"testdata/transaction_response_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
-	) // nolint
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
 	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockTransactionResponse
+	var correct *RosettaTypes.BlockResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
-	resp, err := c.Transaction(
+	resp, err := c.Block(
 		ctx,
-		&RosettaTypes.BlockIdentifier{
-			Hash: "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d",
-		},
-		&RosettaTypes.TransactionIdentifier{
-			Hash: "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4",
+		&RosettaTypes.PartialBlockIdentifier{
+			Hash: RosettaTypes.String(
+				"0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae",
+			),
 		},
 	)
+	assert.Equal(t, correct.Block, resp.Block)
 	assert.NoError(t, err)
 
-	jsonResp, err := jsonifyTransaction(resp)
-	assert.NoError(t, err)
-	assert.Equal(t, correct.Transaction, jsonResp)
-
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with transaction
-func TestBlock_10994(t *testing.T) {
+func TestBlock_Index(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1629,7 +1958,7 @@ func TestBlock_10994(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x2af2",
+		"0x2af0",
 		true,
 	).Return(
 		nil,
@@ -1637,7 +1966,7 @@ func TestBlock_10994(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_10994.json")
+			file, err := ioutil.ReadFile("testdata/block_10992.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -1648,7 +1977,7 @@ func TestBlock_10994(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50"),
+		common.HexToHash("0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae"),
 		tc,
 	).Return(
 		nil,
@@ -1657,67 +1986,118 @@ func TestBlock_10994(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50.json",
+				"testdata/block_trace_0xba9ded5ca1ec9adb9451bf062c9de309d9552fa0f0254a7b982d3daf7ae436ae.json",
 			) // nolint
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
 		},
 	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10992.json")
+	assert.NoError(t, err)
+	var correct *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(10992),
+		},
+	)
+	assert.Equal(t, correct.Block, resp.Block)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestBlock_FirstBlock(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
 	mockJSONRPC.On(
-		"BatchCallContext",
+		"CallContext",
 		ctx,
 		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x0",
+		true,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).([]rpc.BatchElem)
-
-			assert.Len(t, r, 1)
-			assert.Equal(
-				t,
-				"0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e",
-				r[0].Args[0],
-			)
+			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile(
-				"testdata/tx_receipt_0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e.json",
-			) // nolint
+			file, err := ioutil.ReadFile("testdata/block_0.json")
 			assert.NoError(t, err)
 
-			receipt := new(types.Receipt)
-			assert.NoError(t, receipt.UnmarshalJSON(file))
-			*(r[0].Result.(**types.Receipt)) = receipt
+			*r = file
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_10994.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_0.json")
 	assert.NoError(t, err)
-	var correctResp *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+	var correct *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
 	resp, err := c.Block(
 		ctx,
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(10994),
+			Index: RosettaTypes.Int64(0),
 		},
 	)
+	assert.Equal(t, correct.Block, resp.Block)
 	assert.NoError(t, err)
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
-	assert.NoError(t, err)
-	assert.Equal(t, correctResp.Block, jsonResp)
-
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with uncle
-func TestBlock_10991(t *testing.T) {
+func jsonifyTransaction(b *RosettaTypes.Transaction) (*RosettaTypes.Transaction, error) {
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx RosettaTypes.Transaction
+	if err := json.Unmarshal(bytes, &tx); err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+func jsonifyBlock(b *RosettaTypes.Block) (*RosettaTypes.Block, error) {
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var bo RosettaTypes.Block
+	if err := json.Unmarshal(bytes, &bo); err != nil {
+		return nil, err
+	}
+
+	return &bo, nil
+}
+
+func TestTransaction_Hash(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
+	txHash := "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4"
+	blockHash := "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d"
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
@@ -1734,93 +2114,120 @@ func TestBlock_10991(t *testing.T) {
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getBlockByNumber",
-		"0x2aef",
-		true,
+		"eth_getTransactionByHash",
+		txHash,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_10991.json")
+			file, err := ioutil.ReadFile(
+				"testdata/transaction_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
+			) // nolint
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
 		},
 	).Once()
+
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"debug_traceBlockByHash",
-		common.HexToHash("0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95"),
-		tc,
+		"eth_getBlockByHash",
+		blockHash,
+		false,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
+			r := args.Get(1).(**types.Header)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95.json",
+				"testdata/block_0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d.json",
 			) // nolint
 			assert.NoError(t, err)
 
-			*r = json.RawMessage(file)
+			*r = new(types.Header)
+
+			assert.NoError(t, (*r).UnmarshalJSON(file))
 		},
 	).Once()
+
 	mockJSONRPC.On(
-		"BatchCallContext",
+		"CallContext",
 		ctx,
 		mock.Anything,
+		"eth_getTransactionReceipt",
+		common.HexToHash(txHash),
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).([]rpc.BatchElem)
+			r := args.Get(1).(**types.Receipt)
 
-			assert.Len(t, r, 1)
-			assert.Equal(
-				t,
-				common.HexToHash(
-					"0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95",
-				),
-				r[0].Args[0],
-			)
-			assert.Equal(t, "0x0", r[0].Args[1])
+			file, err := ioutil.ReadFile(
+				"testdata/tx_receipt_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = new(types.Receipt)
+
+			assert.NoError(t, (*r).UnmarshalJSON(file))
+		},
+	).Once()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceTransaction",
+		common.HexToHash(txHash),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/uncle_0x8e585e32e6beb4b1f60377d53210a521ace5c30395c34398d535ea56edcf8899.json",
+				"testdata/transaction_trace_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
 			) // nolint
 			assert.NoError(t, err)
 
-			header := new(types.Header)
-			assert.NoError(t, header.UnmarshalJSON(file))
-			*(r[0].Result.(**types.Header)) = header
+			*r = json.RawMessage(file)
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_10991.json")
+	correctRaw, err := ioutil.ReadFile(
+		"testdata/transaction_response_0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4.json",
+	) // nolint
 	assert.NoError(t, err)
-	var correct *RosettaTypes.BlockResponse
+	var correct *RosettaTypes.BlockTransactionResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
-	resp, err := c.Block(
+	resp, err := c.Transaction(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(10991),
+		&RosettaTypes.BlockIdentifier{
+			Hash: "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d",
+		},
+		&RosettaTypes.TransactionIdentifier{
+			Hash: "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4",
 		},
 	)
-	assert.Equal(t, correct.Block, resp)
 	assert.NoError(t, err)
 
+	jsonResp, err := jsonifyTransaction(resp)
+	assert.NoError(t, err)
+	assert.Equal(t, correct.Transaction, jsonResp)
+
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with partial success transaction
-func TestBlock_239782(t *testing.T) {
+// Block with transaction
+func TestBlock_10994(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -1840,7 +2247,7 @@ func TestBlock_239782(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x3a8a6",
+		"0x2af2",
 		true,
 	).Return(
 		nil,
@@ -1848,7 +2255,7 @@ func TestBlock_239782(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_239782.json")
+			file, err := ioutil.ReadFile("testdata/block_10994.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -1859,7 +2266,7 @@ func TestBlock_239782(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0xc4487850a40d85b79cf5e5b69db38284fbd39efcf902ca8a6d9f2ba89c538ea3"),
+		common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50"),
 		tc,
 	).Return(
 		nil,
@@ -1868,7 +2275,7 @@ func TestBlock_239782(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xc4487850a40d85b79cf5e5b69db38284fbd39efcf902ca8a6d9f2ba89c538ea3.json",
+				"testdata/block_trace_0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -1888,12 +2295,12 @@ func TestBlock_239782(t *testing.T) {
 			assert.Len(t, r, 1)
 			assert.Equal(
 				t,
-				"0x05613760334d347e771fad61b1815c8c817b8dd5f0fcbba57c3f2df67dec33d6",
+				"0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e",
 				r[0].Args[0],
 			)
 
 			file, err := ioutil.ReadFile(
-				"testdata/tx_receipt_0x05613760334d347e771fad61b1815c8c817b8dd5f0fcbba57c3f2df67dec33d6.json",
+				"testdata/tx_receipt_0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -1903,7 +2310,7 @@ func TestBlock_239782(t *testing.T) {
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_239782.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10994.json")
 	assert.NoError(t, err)
 	var correctResp *RosettaTypes.BlockResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
@@ -1911,13 +2318,13 @@ func TestBlock_239782(t *testing.T) {
 	resp, err := c.Block(
 		ctx,
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(239782),
+			Index: RosettaTypes.Int64(10994),
 		},
 	)
 	assert.NoError(t, err)
 
 	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
+	jsonResp, err := jsonifyBlock(resp.Block)
 	assert.NoError(t, err)
 	assert.Equal(t, correctResp.Block, jsonResp)
 
@@ -1925,11 +2332,13 @@ func TestBlock_239782(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with transfer to destroyed contract
-func TestBlock_363415(t *testing.T) {
+func TestBlock_Cache(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	storage, err := NewFileBlockStorage(t.TempDir())
+	assert.NoError(t, err)
+
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
@@ -1938,6 +2347,7 @@ func TestBlock_363415(t *testing.T) {
 		tc:             tc,
 		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
+		cache:          NewBlockCache(storage, 64).forTraces(traceKey(DebugTraceAPI, tc)),
 	}
 
 	ctx := context.Background()
@@ -1946,7 +2356,7 @@ func TestBlock_363415(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x58b97",
+		"0x2af2",
 		true,
 	).Return(
 		nil,
@@ -1954,18 +2364,18 @@ func TestBlock_363415(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_363415.json")
+			file, err := ioutil.ReadFile("testdata/block_10994.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
 		},
-	).Once()
+	).Twice()
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e"),
+		common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50"),
 		tc,
 	).Return(
 		nil,
@@ -1974,7 +2384,7 @@ func TestBlock_363415(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e.json",
+				"testdata/block_trace_0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -1991,57 +2401,64 @@ func TestBlock_363415(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
 
-			assert.Len(t, r, 2)
+			assert.Len(t, r, 1)
+			file, err := ioutil.ReadFile(
+				"testdata/tx_receipt_0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e.json",
+			) // nolint
+			assert.NoError(t, err)
 
-			for i, txHash := range []string{
-				"0x9e0f7c64a5bf1fc9f3d7b7963cf23f74e3d2c0b2b3f35f26df031954e5581179",
-				"0x0046a7c3ca126864a3e851235ca6bf030300f9138f035f5f190e59ff9a4b22ff",
-			} {
-				assert.Equal(
-					t,
-					txHash,
-					r[i].Args[0],
-				)
-
-				file, err := ioutil.ReadFile(
-					"testdata/tx_receipt_" + txHash + ".json",
-				) // nolint
-				assert.NoError(t, err)
+			receipt := new(types.Receipt)
+			assert.NoError(t, receipt.UnmarshalJSON(file))
+			*(r[0].Result.(**types.Receipt)) = receipt
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
 
-				receipt := new(types.Receipt)
-				assert.NoError(t, receipt.UnmarshalJSON(file))
-				*(r[i].Result.(**types.Receipt)) = receipt
-			}
+			*r = hexutil.Uint64(12000)
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_363415.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10994.json")
 	assert.NoError(t, err)
 	var correctResp *RosettaTypes.BlockResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
 
-	resp, err := c.Block(
-		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(363415),
-		},
-	)
-	assert.NoError(t, err)
+	// The second request is served from the cache
+	// without fetching receipts or traces.
+	for i := 0; i < 2; i++ {
+		resp, err := c.Block(
+			ctx,
+			&RosettaTypes.PartialBlockIdentifier{
+				Index: RosettaTypes.Int64(10994),
+			},
+		)
+		assert.NoError(t, err)
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
-	assert.NoError(t, err)
-	assert.Equal(t, correctResp.Block, jsonResp)
+		jsonResp, err := jsonifyBlock(resp.Block)
+		assert.NoError(t, err)
+		assert.Equal(t, correctResp.Block, jsonResp)
+	}
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with transfer to precompiled
-func TestBlock_363753(t *testing.T) {
+func TestBlock_CacheUnreadable(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
+	storage, err := NewFileBlockStorage(t.TempDir())
+	assert.NoError(t, err)
+
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
@@ -2050,15 +2467,21 @@ func TestBlock_363753(t *testing.T) {
 		tc:             tc,
 		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
+		cache:          NewBlockCache(storage, 64).forTraces(traceKey(DebugTraceAPI, tc)),
 	}
 
+	// A corrupt cache entry is ignored and the block
+	// is fetched from geth instead.
+	hash := common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50")
+	assert.NoError(t, storage.Set(c.cache.key(hash), []byte("{")))
+
 	ctx := context.Background()
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x58ce9",
+		"0x2af2",
 		true,
 	).Return(
 		nil,
@@ -2066,7 +2489,7 @@ func TestBlock_363753(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_363753.json")
+			file, err := ioutil.ReadFile("testdata/block_10994.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -2077,7 +2500,7 @@ func TestBlock_363753(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0x3defb56cc49cf7603e08749516a003baae0944596e4555b0d868ec225ff2bcd3"),
+		common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50"),
 		tc,
 	).Return(
 		nil,
@@ -2086,7 +2509,7 @@ func TestBlock_363753(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0x3defb56cc49cf7603e08749516a003baae0944596e4555b0d868ec225ff2bcd3.json",
+				"testdata/block_trace_0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -2103,31 +2526,27 @@ func TestBlock_363753(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
 
-			assert.Len(t, r, 2)
-
-			for i, txHash := range []string{
-				"0x586d0a158f29da3d0e8fa4d24596d1a9f6ded03b5ccdb68f40e9372980488fc8",
-				"0x80fb7e6bfa8dae67cf79f21b9e68c5af727ba52f3ab1e5a5be5c8048a9758f56",
-			} {
-				assert.Equal(
-					t,
-					txHash,
-					r[i].Args[0],
-				)
-
-				file, err := ioutil.ReadFile(
-					"testdata/tx_receipt_" + txHash + ".json",
-				) // nolint
-				assert.NoError(t, err)
+			assert.Len(t, r, 1)
+			file, err := ioutil.ReadFile(
+				"testdata/tx_receipt_0xd83b1dcf7d47c4115d78ce0361587604e8157591b118bd64ada02e86c9d5ca7e.json",
+			) // nolint
+			assert.NoError(t, err)
 
-				receipt := new(types.Receipt)
-				assert.NoError(t, receipt.UnmarshalJSON(file))
-				*(r[i].Result.(**types.Receipt)) = receipt
-			}
+			receipt := new(types.Receipt)
+			assert.NoError(t, receipt.UnmarshalJSON(file))
+			*(r[0].Result.(**types.Receipt)) = receipt
 		},
 	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		errors.New("connection refused"),
+	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_363753.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10994.json")
 	assert.NoError(t, err)
 	var correctResp *RosettaTypes.BlockResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
@@ -2135,22 +2554,97 @@ func TestBlock_363753(t *testing.T) {
 	resp, err := c.Block(
 		ctx,
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(363753),
+			Index: RosettaTypes.Int64(10994),
 		},
 	)
 	assert.NoError(t, err)
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
+	jsonResp, err := jsonifyBlock(resp.Block)
 	assert.NoError(t, err)
 	assert.Equal(t, correctResp.Block, jsonResp)
 
+	// The block is not cached when the current
+	// block number is unknown.
+	value, ok, err := storage.Get(c.cache.key(hash))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("{"), value)
+
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with complex self-destruct
-func TestBlock_468179(t *testing.T) {
+func TestBlock_CacheNotFinal(t *testing.T) {
+	storage, err := NewFileBlockStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	cache := NewBlockCache(storage, 64)
+	assert.False(t, cache.final(10994, 11000))
+	assert.True(t, cache.final(10994, 11058))
+
+	var nilCache *BlockCache
+	assert.False(t, nilCache.final(10994, 20000))
+
+	block, err := cache.get(common.HexToHash("0x01"))
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+}
+
+func TestBlock_CacheTraceSettings(t *testing.T) {
+	storage, err := NewFileBlockStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	hash := common.HexToHash("0xb6a2558c2e54bfb11247d0764311143af48d122f29fc408d9519f47d70aa2d50")
+	block := &cachedBlock{
+		Receipts: []*types.Receipt{},
+		Traces:   json.RawMessage(`[{"result":{"type":"CALL"}}]`),
+	}
+
+	cache := NewBlockCache(storage, 64).forTraces(traceKey(DebugTraceAPI, tc))
+	assert.NoError(t, cache.set(hash, block))
+
+	cached, err := cache.get(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, block, cached)
+
+	// Blocks are not served to caches that
+	// trace with a different tracer.
+	other := NewBlockCache(storage, 64).forTraces(traceKey(DebugTraceAPI, &TraceConfig{
+		Tracer:  "{result: function() { return {}; }}",
+		Timeout: tc.Timeout,
+	}))
+	cached, err = other.get(hash)
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+
+	// Blocks traced with trace_block are not served
+	// to caches that trace with the tracer, and the
+	// tracer is not used with trace_block.
+	assert.NotEqual(t, cache.traceKey, traceKey(ParityTraceAPI, tc))
+	assert.Equal(t, traceKey(ParityTraceAPI, nil), traceKey(ParityTraceAPI, tc))
+
+	// The config of the native callTracer
+	// changes the traces.
+	nativeKey := traceKey(DebugTraceAPI, NativeTraceConfig(nil))
+	assert.NotEqual(t, nativeKey, traceKey(DebugTraceAPI, NativeTraceConfig(&CallTracerConfig{
+		OnlyTopCall: true,
+	})))
+	assert.Equal(t, nativeKey, traceKey(DebugTraceAPI, NativeTraceConfig(&CallTracerConfig{})))
+
+	// The timeout does not change the traces.
+	assert.Equal(t, cache.traceKey, traceKey(DebugTraceAPI, &TraceConfig{
+		Tracer:  tc.Tracer,
+		Timeout: "240s",
+	}))
+
+	var nilCache *BlockCache
+	assert.Nil(t, nilCache.forTraces(cache.traceKey))
+}
+
+// Block with uncle
+func TestBlock_10991(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -2170,7 +2664,7 @@ func TestBlock_468179(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x724d3",
+		"0x2aef",
 		true,
 	).Return(
 		nil,
@@ -2178,7 +2672,7 @@ func TestBlock_468179(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_468179.json")
+			file, err := ioutil.ReadFile("testdata/block_10991.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -2189,7 +2683,7 @@ func TestBlock_468179(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0xd88e8376ec3eef899d9fbc6349e8330ebfc102b245fef784a999ac854091cb64"),
+		common.HexToHash("0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95"),
 		tc,
 	).Return(
 		nil,
@@ -2198,7 +2692,7 @@ func TestBlock_468179(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xd88e8376ec3eef899d9fbc6349e8330ebfc102b245fef784a999ac854091cb64.json",
+				"testdata/block_trace_0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -2215,54 +2709,47 @@ func TestBlock_468179(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
 
-			assert.Len(t, r, 2)
-
-			for i, txHash := range []string{
-				"0x712f7aed1ac12f8a38b4caefea8e7c1940c88add78e110b194c653c9efb3a75d",
-				"0x99b723ac54002b16049143474d80f8e6358d14dec2250d873511d091de74977d",
-			} {
-				assert.Equal(
-					t,
-					txHash,
-					r[i].Args[0],
-				)
+			assert.Len(t, r, 1)
+			assert.Equal(
+				t,
+				common.HexToHash(
+					"0x4cd21f49705529e2628f8ae1a248bcd0e3cafd21bf6d741bdee2820af82cff95",
+				),
+				r[0].Args[0],
+			)
+			assert.Equal(t, "0x0", r[0].Args[1])
 
-				file, err := ioutil.ReadFile(
-					"testdata/tx_receipt_" + txHash + ".json",
-				) // nolint
-				assert.NoError(t, err)
+			file, err := ioutil.ReadFile(
+				"testdata/uncle_0x8e585e32e6beb4b1f60377d53210a521ace5c30395c34398d535ea56edcf8899.json",
+			) // nolint
+			assert.NoError(t, err)
 
-				receipt := new(types.Receipt)
-				assert.NoError(t, receipt.UnmarshalJSON(file))
-				*(r[i].Result.(**types.Receipt)) = receipt
-			}
+			header := new(types.Header)
+			assert.NoError(t, header.UnmarshalJSON(file))
+			*(r[0].Result.(**types.Header)) = header
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_468179.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_10991.json")
 	assert.NoError(t, err)
-	var correctResp *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+	var correct *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correct))
 
 	resp, err := c.Block(
 		ctx,
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(468179),
+			Index: RosettaTypes.Int64(10991),
 		},
 	)
+	assert.Equal(t, correct.Block, resp.Block)
 	assert.NoError(t, err)
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
-	assert.NoError(t, err)
-	assert.Equal(t, correctResp.Block, jsonResp)
-
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with complex resurrection
-func TestBlock_363366(t *testing.T) {
+// Block with partial success transaction
+func TestBlock_239782(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -2282,7 +2769,7 @@ func TestBlock_363366(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x58b66",
+		"0x3a8a6",
 		true,
 	).Return(
 		nil,
@@ -2290,7 +2777,7 @@ func TestBlock_363366(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_363366.json")
+			file, err := ioutil.ReadFile("testdata/block_239782.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -2301,7 +2788,7 @@ func TestBlock_363366(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0x5f7c67c2eb0e828b0f4a0e64d5fbae0ed66b70c9ae752e6175c9ef62402502df"),
+		common.HexToHash("0xc4487850a40d85b79cf5e5b69db38284fbd39efcf902ca8a6d9f2ba89c538ea3"),
 		tc,
 	).Return(
 		nil,
@@ -2310,7 +2797,7 @@ func TestBlock_363366(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0x5f7c67c2eb0e828b0f4a0e64d5fbae0ed66b70c9ae752e6175c9ef62402502df.json",
+				"testdata/block_trace_0xc4487850a40d85b79cf5e5b69db38284fbd39efcf902ca8a6d9f2ba89c538ea3.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -2327,32 +2814,25 @@ func TestBlock_363366(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
 
-			assert.Len(t, r, 3)
-
-			for i, txHash := range []string{
-				"0x3f11ca203c7fd814751725c2c5a3efa00bebbbd5e89f406a28b4a36559393b6f",
-				"0x4cc86d845b6ee5c12db00cc75c42e98f8bbf62060bc925942c5ff6a36878549b",
-				"0xf8b84ff00db596c9db15de1a44c939cce36c0dfd60ef6171db6951b11d7d015d",
-			} {
-				assert.Equal(
-					t,
-					txHash,
-					r[i].Args[0],
-				)
+			assert.Len(t, r, 1)
+			assert.Equal(
+				t,
+				"0x05613760334d347e771fad61b1815c8c817b8dd5f0fcbba57c3f2df67dec33d6",
+				r[0].Args[0],
+			)
 
-				file, err := ioutil.ReadFile(
-					"testdata/tx_receipt_" + txHash + ".json",
-				) // nolint
-				assert.NoError(t, err)
+			file, err := ioutil.ReadFile(
+				"testdata/tx_receipt_0x05613760334d347e771fad61b1815c8c817b8dd5f0fcbba57c3f2df67dec33d6.json",
+			) // nolint
+			assert.NoError(t, err)
 
-				receipt := new(types.Receipt)
-				assert.NoError(t, receipt.UnmarshalJSON(file))
-				*(r[i].Result.(**types.Receipt)) = receipt
-			}
+			receipt := new(types.Receipt)
+			assert.NoError(t, receipt.UnmarshalJSON(file))
+			*(r[0].Result.(**types.Receipt)) = receipt
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_363366.json")
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_239782.json")
 	assert.NoError(t, err)
 	var correctResp *RosettaTypes.BlockResponse
 	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
@@ -2360,13 +2840,13 @@ func TestBlock_363366(t *testing.T) {
 	resp, err := c.Block(
 		ctx,
 		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(363366),
+			Index: RosettaTypes.Int64(239782),
 		},
 	)
 	assert.NoError(t, err)
 
 	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
+	jsonResp, err := jsonifyBlock(resp.Block)
 	assert.NoError(t, err)
 	assert.Equal(t, correctResp.Block, jsonResp)
 
@@ -2374,8 +2854,8 @@ func TestBlock_363366(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
-// Block with blackholed funds
-func TestBlock_468194(t *testing.T) {
+// Block with transfer to destroyed contract
+func TestBlock_363415(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -2395,7 +2875,7 @@ func TestBlock_468194(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"eth_getBlockByNumber",
-		"0x724e2",
+		"0x58b97",
 		true,
 	).Return(
 		nil,
@@ -2403,7 +2883,7 @@ func TestBlock_468194(t *testing.T) {
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
 
-			file, err := ioutil.ReadFile("testdata/block_468194.json")
+			file, err := ioutil.ReadFile("testdata/block_363415.json")
 			assert.NoError(t, err)
 
 			*r = json.RawMessage(file)
@@ -2414,7 +2894,7 @@ func TestBlock_468194(t *testing.T) {
 		ctx,
 		mock.Anything,
 		"debug_traceBlockByHash",
-		common.HexToHash("0xf0d9ab47473e38f98b195ba7a17934f68519168f5fdec9899b3c18180d8fbb54"),
+		common.HexToHash("0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e"),
 		tc,
 	).Return(
 		nil,
@@ -2423,7 +2903,7 @@ func TestBlock_468194(t *testing.T) {
 			r := args.Get(1).(*json.RawMessage)
 
 			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0xf0d9ab47473e38f98b195ba7a17934f68519168f5fdec9899b3c18180d8fbb54.json",
+				"testdata/block_trace_0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e.json",
 			) // nolint
 			assert.NoError(t, err)
 
@@ -2443,8 +2923,8 @@ func TestBlock_468194(t *testing.T) {
 			assert.Len(t, r, 2)
 
 			for i, txHash := range []string{
-				"0xbd54f0c5742a5c96ffb358680b88a0f6cfbf83d599dbd0b8fff66b59ed0d7f81",
-				"0xf3626ec6a7aba22137b012e8e68513dcaf8574d0412b97e4381513a3ca9ecfc0",
+				"0x9e0f7c64a5bf1fc9f3d7b7963cf23f74e3d2c0b2b3f35f26df031954e5581179",
+				"0x0046a7c3ca126864a3e851235ca6bf030300f9138f035f5f190e59ff9a4b22ff",
 			} {
 				assert.Equal(
 					t,
@@ -2464,44 +2944,2219 @@ func TestBlock_468194(t *testing.T) {
 		},
 	).Once()
 
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_468194.json")
-	assert.NoError(t, err)
-	var correctResp *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
-
-	resp, err := c.Block(
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_363415.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(363415),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestBlock_363415_TransactionLimit(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:                     mockJSONRPC,
+		g:                     mockGraphQL,
+		tc:                    tc,
+		p:                     params.RopstenChainConfig,
+		traceSemaphore:        semaphore.NewWeighted(100),
+		blockTransactionLimit: 1,
+		recentBlocks:          newRecentBlocks(),
+	}
+
+	ctx := context.Background()
+	blockHash := "0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e"
+	loadBlock := func(args mock.Arguments) {
+		r := args.Get(1).(*json.RawMessage)
+
+		file, err := ioutil.ReadFile("testdata/block_363415.json")
+		assert.NoError(t, err)
+
+		*r = json.RawMessage(file)
+	}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x58b97",
+		true,
+	).Return(
+		nil,
+	).Run(
+		loadBlock,
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByHash",
+		blockHash,
+		true,
+	).Return(
+		nil,
+	).Run(
+		loadBlock,
+	).Twice()
+
+	// Receipts and traces are only fetched once
+	// for all transactions of the block, with a
+	// context detached from the request.
+	mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash(blockHash),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	txHashes := []string{
+		"0x9e0f7c64a5bf1fc9f3d7b7963cf23f74e3d2c0b2b3f35f26df031954e5581179",
+		"0x0046a7c3ca126864a3e851235ca6bf030300f9138f035f5f190e59ff9a4b22ff",
+	}
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 2)
+
+			for i, txHash := range txHashes {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_363415.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(363415),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Only the block reward transaction is populated
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block.BlockIdentifier, jsonResp.BlockIdentifier)
+	assert.Equal(t, correctResp.Block.Transactions[:1], jsonResp.Transactions)
+	assert.Equal(t, []*RosettaTypes.TransactionIdentifier{
+		{Hash: txHashes[0]},
+		{Hash: txHashes[1]},
+	}, resp.OtherTransactions)
+
+	for i, txHash := range txHashes {
+		tx, err := c.Transaction(
+			ctx,
+			resp.Block.BlockIdentifier,
+			&RosettaTypes.TransactionIdentifier{Hash: txHash},
+		)
+		assert.NoError(t, err)
+
+		jsonTx, err := jsonifyTransaction(tx)
+		assert.NoError(t, err)
+		assert.Equal(t, correctResp.Block.Transactions[i+1], jsonTx)
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+type recentBlocksKey struct{}
+
+func TestRecentBlocks_Load(t *testing.T) {
+	r := newRecentBlocks()
+	hash := common.HexToHash("0xf0445269b02ba461af662d8c6aac50d9557a0cc9dbe580d3e180efd7879cc79e")
+	block := &cachedBlock{Receipts: []*types.Receipt{}}
+
+	var fetches int32
+	started := make(chan context.Context)
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (*cachedBlock, error) {
+		atomic.AddInt32(&fetches, 1)
+		started <- ctx
+		<-release
+		return block, nil
+	}
+
+	ctx, cancel := context.WithCancel(
+		context.WithValue(context.Background(), recentBlocksKey{}, "value"),
+	)
+	errs := make(chan error)
+	go func() {
+		_, err := r.load(ctx, hash, fetch)
+		errs <- err
+	}()
+	fetchCtx := <-started
+
+	// Canceling the context of the load that started the
+	// fetch stops it waiting, but does not cancel the fetch.
+	cancel()
+	assert.True(t, errors.Is(<-errs, context.Canceled))
+	assert.NoError(t, fetchCtx.Err())
+	assert.Equal(t, "value", fetchCtx.Value(recentBlocksKey{}))
+	_, ok := fetchCtx.Deadline()
+	assert.True(t, ok)
+
+	// Other loads are served by the fetch that was started.
+	close(release)
+	for i := 0; i < 2; i++ {
+		loaded, err := r.load(context.Background(), hash, fetch)
+		assert.NoError(t, err)
+		assert.Equal(t, block, loaded)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+// Block with transfer to precompiled
+func TestBlock_363753(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x58ce9",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile("testdata/block_363753.json")
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash("0x3defb56cc49cf7603e08749516a003baae0944596e4555b0d868ec225ff2bcd3"),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0x3defb56cc49cf7603e08749516a003baae0944596e4555b0d868ec225ff2bcd3.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 2)
+
+			for i, txHash := range []string{
+				"0x586d0a158f29da3d0e8fa4d24596d1a9f6ded03b5ccdb68f40e9372980488fc8",
+				"0x80fb7e6bfa8dae67cf79f21b9e68c5af727ba52f3ab1e5a5be5c8048a9758f56",
+			} {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_363753.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(363753),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+// Block with complex self-destruct
+func TestBlock_468179(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x724d3",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile("testdata/block_468179.json")
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash("0xd88e8376ec3eef899d9fbc6349e8330ebfc102b245fef784a999ac854091cb64"),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0xd88e8376ec3eef899d9fbc6349e8330ebfc102b245fef784a999ac854091cb64.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 2)
+
+			for i, txHash := range []string{
+				"0x712f7aed1ac12f8a38b4caefea8e7c1940c88add78e110b194c653c9efb3a75d",
+				"0x99b723ac54002b16049143474d80f8e6358d14dec2250d873511d091de74977d",
+			} {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_468179.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(468179),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+// Block with complex resurrection
+func TestBlock_363366(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x58b66",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile("testdata/block_363366.json")
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash("0x5f7c67c2eb0e828b0f4a0e64d5fbae0ed66b70c9ae752e6175c9ef62402502df"),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0x5f7c67c2eb0e828b0f4a0e64d5fbae0ed66b70c9ae752e6175c9ef62402502df.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 3)
+
+			for i, txHash := range []string{
+				"0x3f11ca203c7fd814751725c2c5a3efa00bebbbd5e89f406a28b4a36559393b6f",
+				"0x4cc86d845b6ee5c12db00cc75c42e98f8bbf62060bc925942c5ff6a36878549b",
+				"0xf8b84ff00db596c9db15de1a44c939cce36c0dfd60ef6171db6951b11d7d015d",
+			} {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_363366.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(363366),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+// Block with blackholed funds
+func TestBlock_468194(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x724e2",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile("testdata/block_468194.json")
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash("0xf0d9ab47473e38f98b195ba7a17934f68519168f5fdec9899b3c18180d8fbb54"),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0xf0d9ab47473e38f98b195ba7a17934f68519168f5fdec9899b3c18180d8fbb54.json",
+			) // nolint
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 2)
+
+			for i, txHash := range []string{
+				"0xbd54f0c5742a5c96ffb358680b88a0f6cfbf83d599dbd0b8fff66b59ed0d7f81",
+				"0xf3626ec6a7aba22137b012e8e68513dcaf8574d0412b97e4381513a3ca9ecfc0",
+			} {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_468194.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(468194),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+// Block with EIP-1559 base fee & txs. This block taken from mainnet:
+//
+//	https://etherscan.io/block/0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2
+//
+// This block has 7 transactions, all EIP-1559 type except the last.
+func TestBlock_13998626(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.RopstenChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0xd59a22",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile("testdata/block_13998626.json")
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash("0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2"),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/block_trace_0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2.json") // nolint
+			assert.NoError(t, err)
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			assert.Len(t, r, 7)
+			for i, txHash := range []string{
+				"0xf121c8c07ed51b6ac2d11fe3f0892bff2221ec9168280d12581ea8ff45e71421",
+				"0xef0748860f1c1ba28a5ae3ae9d2d1133940f7c8090fc862acf48de42b00ae2b5",
+				"0xb240b922161bb0aeaa5ebe67e6cf77311092bd945b9582b8deba61e2ebdde74f",
+				"0xfac8149f95c20f62264991fe15dc74ca77c92ad6e4329496548277fb4d520509",
+				"0x0a4cd36d72c2ed4767c1d228a7aa0638c3e46397f48b6b09f35ed455c851bb04",
+				"0x9ee03d5922b2a901e3fc05d8a6351165b9f211162363c790c98746ef229e395c",
+				"0x0d4a4f924858a5b19f6b931a914701d4258e73fa738da3d38eb3be1d1e862a7a",
+			} {
+				assert.Equal(
+					t,
+					txHash,
+					r[i].Args[0],
+				)
+
+				file, err := ioutil.ReadFile(
+					"testdata/tx_receipt_" + txHash + ".json",
+				) // nolint
+				assert.NoError(t, err)
+
+				receipt := new(types.Receipt)
+				assert.NoError(t, receipt.UnmarshalJSON(file))
+				*(r[i].Result.(**types.Receipt)) = receipt
+			}
+		},
+	).Once()
+
+	correctRaw, err := ioutil.ReadFile("testdata/block_response_13998626.json")
+	assert.NoError(t, err)
+	var correctResp *RosettaTypes.BlockResponse
+	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(13998626),
+		},
+	)
+	assert.NoError(t, err)
+
+	// Ensure types match
+	jsonResp, err := jsonifyBlock(resp.Block)
+
+	assert.NoError(t, err)
+	assert.Equal(t, correctResp.Block, jsonResp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestBlock_ProofOfStake(t *testing.T) {
+	c := &Client{
+		p: params.MainnetChainConfig,
+	}
+	if c.p.TerminalTotalDifficulty == nil {
+		config := *c.p
+		config.TerminalTotalDifficulty = MainnetTerminalTotalDifficulty
+		c.p = &config
+	}
+
+	coinbase := common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5")
+	blockIdentifier := &RosettaTypes.BlockIdentifier{
+		Hash:  "0x01",
+		Index: 15537394,
+	}
+
+	// Proof-of-stake blocks have a difficulty of 0
+	// and do not pay any block reward.
+	posBlock := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(15537394),
+		Coinbase:   coinbase,
+		Difficulty: big.NewInt(0),
+	})
+	transactions, err := c.populateTransactions(blockIdentifier, posBlock, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 0)
+
+	// The last proof-of-work block still pays
+	// a block reward.
+	powBlock := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(15537393),
+		Coinbase:   coinbase,
+		Difficulty: big.NewInt(11055787484078698),
+	})
+	transactions, err = c.populateTransactions(blockIdentifier, powBlock, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, MinerRewardOpType, transactions[0].Operations[0].Type)
+	assert.Equal(t, "2000000000000000000", transactions[0].Operations[0].Amount.Value)
+}
+
+func TestBlock_Withdrawals(t *testing.T) {
+	c := &Client{
+		p: params.MainnetChainConfig,
+	}
+	if c.p.TerminalTotalDifficulty == nil {
+		config := *c.p
+		config.TerminalTotalDifficulty = MainnetTerminalTotalDifficulty
+		c.p = &config
+	}
+
+	var body rpcBlock
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"hash": "0x01",
+		"transactions": [],
+		"uncles": [],
+		"withdrawals": [
+			{
+				"index": "0xf5e7",
+				"validatorIndex": "0x4b8d0",
+				"address": "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f",
+				"amount": "0xd9c6c0"
+			},
+			{
+				"index": "0xf5e8",
+				"validatorIndex": "0x4b8d1",
+				"address": "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f",
+				"amount": "0x0"
+			}
+		]
+	}`), &body))
+
+	blockIdentifier := &RosettaTypes.BlockIdentifier{
+		Hash:  "0x01",
+		Index: 17034870,
+	}
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(17034870),
+		Coinbase:   common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5"),
+		Difficulty: big.NewInt(0),
+	})
+	transactions, err := c.populateTransactions(blockIdentifier, block, nil, body.Withdrawals)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.Transaction{
+		{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+				Hash: "0xc68784705b63350ead90c96ffc020adce8a0bc4527f461f660a449e60f76b19b",
+			},
+			Operations: []*RosettaTypes.Operation{
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: 0,
+					},
+					Type:   WithdrawalOpType,
+					Status: RosettaTypes.String(SuccessStatus),
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: "0xB9D7934878B5FB9610B3fE8A5e441e8fad7E293f",
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    "14272192000000000",
+						Currency: Currency,
+					},
+					Metadata: map[string]interface{}{
+						"index":           "0xf5e7",
+						"validator_index": "0x4b8d0",
+					},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: 1,
+					},
+					Type:   WithdrawalOpType,
+					Status: RosettaTypes.String(SuccessStatus),
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: "0xB9D7934878B5FB9610B3fE8A5e441e8fad7E293f",
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    "0",
+						Currency: Currency,
+					},
+					Metadata: map[string]interface{}{
+						"index":           "0xf5e8",
+						"validator_index": "0x4b8d1",
+					},
+				},
+			},
+		},
+	}, transactions)
+
+	// Without a terminal total difficulty, the block also has a
+	// reward transaction identified by the hash of the block.
+	c.p = params.MainnetChainConfig
+	if c.p.TerminalTotalDifficulty != nil {
+		config := *c.p
+		config.TerminalTotalDifficulty = nil
+		c.p = &config
+	}
+	transactions, err = c.populateTransactions(blockIdentifier, block, nil, body.Withdrawals)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "0x01", transactions[0].TransactionIdentifier.Hash)
+	assert.Equal(t, MinerRewardOpType, transactions[0].Operations[0].Type)
+	assert.Equal(
+		t,
+		"0xc68784705b63350ead90c96ffc020adce8a0bc4527f461f660a449e60f76b19b",
+		transactions[1].TransactionIdentifier.Hash,
+	)
+	assert.Equal(t, WithdrawalOpType, transactions[1].Operations[0].Type)
+}
+
+func TestBlock_Shanghai(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	tc, err := testTraceConfig()
+	assert.NoError(t, err)
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		tc:             tc,
+		p:              params.MainnetChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+	if c.p.TerminalTotalDifficulty == nil {
+		config := *c.p
+		config.TerminalTotalDifficulty = MainnetTerminalTotalDifficulty
+		c.p = &config
+	}
+
+	file, err := ioutil.ReadFile("testdata/block_shanghai.json")
+	assert.NoError(t, err)
+
+	// go-ethereum's header does not include withdrawalsRoot, so
+	// the hash it computes differs from the hash of the block.
+	blockHash := "0x954e6088457f2e839ace2c32826ec63a6526dba2c7c6e8e75ca76a71583c6cab"
+	var header types.Header
+	assert.NoError(t, header.UnmarshalJSON(file))
+	assert.NotEqual(t, blockHash, header.Hash().Hex())
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"0x103ee77",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		common.HexToHash(blockHash),
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage("[]")
+		},
+	).Once()
+
+	resp, err := c.Block(
+		ctx,
+		&RosettaTypes.PartialBlockIdentifier{
+			Index: RosettaTypes.Int64(17034871),
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  blockHash,
+		Index: 17034871,
+	}, resp.Block.BlockIdentifier)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  "0x2e5e2a2b6d4eb6a0b5c7e3f1d4a8c9b0e1f2a3b4c5d6e7f8091a2b3c4d5e6f70",
+		Index: 17034870,
+	}, resp.Block.ParentBlockIdentifier)
+
+	// Withdrawals are included in a transaction identified
+	// by a hash derived from the hash of the block.
+	assert.Len(t, resp.Block.Transactions, 1)
+	assert.Equal(
+		t,
+		"0x2fbe3ff55ab613663d1f36091d5d05b6818281f6840c967184c248a937ef0347",
+		resp.Block.Transactions[0].TransactionIdentifier.Hash,
+	)
+	assert.Len(t, resp.Block.Transactions[0].Operations, 2)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestNodePool(t *testing.T) {
+	mockJSONRPC1 := &mocks.JSONRPC{}
+	mockJSONRPC2 := &mocks.JSONRPC{}
+	pool := newNodePool([]*node{
+		{url: "http://node-1:8545", c: mockJSONRPC1},
+		{url: "http://node-2:8545", c: mockJSONRPC2},
+	})
+	ctx := context.Background()
+
+	// Unreachable nodes are failed over and marked unhealthy
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	mockJSONRPC1.On("CallContext", ctx, mock.Anything, "eth_gasPrice").Return(unreachable).Once()
+	mockJSONRPC2.On("CallContext", ctx, mock.Anything, "eth_gasPrice").Return(unreachable).Once()
+	err := pool.CallContext(ctx, nil, "eth_gasPrice")
+	assert.True(t, errors.Is(err, unreachable))
+	assert.False(t, pool.nodes[0].isHealthy())
+	assert.False(t, pool.nodes[1].isHealthy())
+
+	// Nodes recover once they pass a health check
+	// and lagging or syncing nodes are unhealthy
+	mockJSONRPC1.On("CallContext", ctx, mock.Anything, "eth_syncing").Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = json.RawMessage("false")
+		},
+	).Twice()
+	mockJSONRPC1.On("CallContext", ctx, mock.Anything, "eth_blockNumber").Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*hexutil.Uint64)) = 100
+		},
+	).Twice()
+	mockJSONRPC2.On("CallContext", ctx, mock.Anything, "eth_syncing").Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = json.RawMessage("false")
+		},
+	).Once()
+	mockJSONRPC2.On("CallContext", ctx, mock.Anything, "eth_blockNumber").Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*hexutil.Uint64)) = 90
+		},
+	).Once()
+	pool.checkHealth(ctx)
+	assert.True(t, pool.nodes[0].isHealthy())
+	assert.False(t, pool.nodes[1].isHealthy())
+
+	mockJSONRPC2.On("CallContext", ctx, mock.Anything, "eth_syncing").Return(nil).Run(
+		func(args mock.Arguments) {
+			*(args.Get(1).(*json.RawMessage)) = json.RawMessage(`{"currentBlock":"0x5a"}`)
+		},
+	).Once()
+	pool.checkHealth(ctx)
+	assert.True(t, pool.nodes[0].isHealthy())
+	assert.False(t, pool.nodes[1].isHealthy())
+
+	// Healthy nodes are tried first
+	mockJSONRPC1.On("CallContext", ctx, mock.Anything, "eth_gasPrice").Return(nil).Once()
+	assert.NoError(t, pool.CallContext(ctx, nil, "eth_gasPrice"))
+
+	// Errors returned by geth are not failed over
+	reverted := errors.New("execution reverted")
+	mockJSONRPC1.On("CallContext", ctx, mock.Anything, "eth_call").Return(reverted).Once()
+	assert.Equal(t, reverted, pool.CallContext(ctx, nil, "eth_call"))
+	assert.True(t, pool.nodes[0].isHealthy())
+
+	// Pinned calls are only sent to a single node, and
+	// the pinned request is retried on the next node
+	attempts := 0
+	mockJSONRPC1.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber").Return(
+		unreachable,
+	).Once()
+	mockJSONRPC2.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber").Return(
+		nil,
+	).Once()
+	mockJSONRPC2.On("CallContext", mock.Anything, mock.Anything, "eth_getBlockReceipts").Return(
+		nil,
+	).Once()
+	err = pool.pin(ctx, func(ctx context.Context) error {
+		attempts++
+		if err := pool.CallContext(ctx, nil, "eth_getBlockByNumber"); err != nil {
+			return err
+		}
+
+		return pool.CallContext(ctx, nil, "eth_getBlockReceipts")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.False(t, pool.nodes[0].isHealthy())
+
+	mockJSONRPC1.AssertExpectations(t)
+	mockJSONRPC2.AssertExpectations(t)
+}
+
+func TestBlockTraces_Parity(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceAPI:       ParityTraceAPI,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	blockHash := common.HexToHash("0x01")
+	file, err := ioutil.ReadFile("testdata/parity_block_trace_0x01.json")
+	assert.NoError(t, err)
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"trace_block",
+		"0x1",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*[]*parityTrace)
+
+			assert.NoError(t, json.Unmarshal(file, r))
+		},
+	).Twice()
+
+	raw, err := c.getBlockTraces(ctx, blockHash, big.NewInt(1), 2)
+	assert.NoError(t, err)
+
+	traces, rawTraces, err := decodeBlockTraces(raw)
+	assert.NoError(t, err)
+	assert.Len(t, traces, 2)
+	assert.Len(t, rawTraces, 2)
+
+	// Traces are converted into the format of the call tracer
+	assert.JSONEq(
+		t,
+		`{"type":"CALL","from":"0x1b0ff2e0e1b4d4e5e1f35e6ba1f02ce8e4a2f95a","to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d","value":"0xde0b6b3a7640000","gasUsed":"0x0"}`, // nolint
+		string(rawTraces[0].Result),
+	)
+	assert.JSONEq(
+		t,
+		`{"type":"CALL","from":"0x1b0ff2e0e1b4d4e5e1f35e6ba1f02ce8e4a2f95a","to":"0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a","value":"0x0","gasUsed":"0x1d4c0","calls":[{"type":"CREATE2","from":"0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a","to":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","value":"0x64","gasUsed":"0x7530","calls":[{"type":"SELFDESTRUCT","from":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d","value":"0x64","gasUsed":"0x0"}]},{"type":"DELEGATECALL","from":"0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a","to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d","value":"0x0","gasUsed":"0x0","error":"Reverted"}]}`, // nolint
+		string(rawTraces[1].Result),
+	)
+
+	flattened := flattenTraces(traces[1].Result, []*flatCall{})
+	assert.Len(t, flattened, 4)
+	assert.Equal(t, Create2OpType, flattened[1].Type)
+	assert.Equal(t, SelfDestructOpType, flattened[2].Type)
+	assert.Equal(t, "DELEGATECALL", flattened[3].Type)
+	assert.True(t, flattened[3].Revert)
+	assert.Equal(t, big.NewInt(100), flattened[1].Value)
+
+	// The traces of an orphaned block are rejected
+	raw, err = c.getBlockTraces(ctx, common.HexToHash("0x02"), big.NewInt(1), 2)
+	assert.Nil(t, raw)
+	assert.True(t, errors.Is(err, ErrBlockOrphaned))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestTraceOps_ZeroValueCalls(t *testing.T) {
+	var trace Call
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"type":"CALL",
+		"from":"0x1b0ff2e0e1b4d4e5e1f35e6ba1f02ce8e4a2f95a",
+		"to":"0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a",
+		"value":"0x0",
+		"gasUsed":"0x1d4c0",
+		"input":"0xa9059cbb000000000000000000000000000000000000000000000000000000000000000a",
+		"calls":[{
+			"type":"DELEGATECALL",
+			"from":"0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a",
+			"to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d",
+			"gasUsed":"0x7530",
+			"input":"0x"
+		}]
+	}`), &trace))
+
+	flattened := flattenTraces(&trace, []*flatCall{})
+	assert.Len(t, flattened, 2)
+	assert.Equal(t, 0, flattened[0].Depth)
+	assert.Equal(t, 1, flattened[1].Depth)
+
+	// Zero-value calls are skipped by default
+	assert.Len(t, traceOps(flattened, 1, false), 0)
+
+	ops := traceOps(flattened, 1, true)
+	assert.Len(t, ops, 4)
+	for i, op := range ops {
+		assert.Equal(t, int64(i+1), op.OperationIdentifier.Index)
+		assert.Nil(t, op.Amount)
+		assert.Equal(t, SuccessStatus, *op.Status)
+	}
+
+	assert.Equal(t, CallOpType, ops[0].Type)
+	assert.Equal(t, "0x1B0FF2e0E1B4D4e5E1f35E6Ba1f02cE8e4a2f95A", ops[0].Account.Address)
+	assert.Equal(t, map[string]interface{}{
+		"call_depth":     0,
+		"gas_used":       "0x1d4c0",
+		"input_selector": "0xa9059cbb",
+	}, ops[1].Metadata)
+
+	assert.Equal(t, DelegateCallOpType, ops[2].Type)
+	assert.Equal(t, "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d", ops[3].Account.Address)
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 3}}, ops[3].RelatedOperations)
+	assert.Equal(t, map[string]interface{}{
+		"call_depth": 1,
+		"gas_used":   "0x7530",
+	}, ops[3].Metadata)
+}
+
+func TestEventRegistry(t *testing.T) {
+	r, err := LoadEventRegistry("testdata/abis")
+	assert.NoError(t, err)
+
+	transferTopic := common.HexToHash(
+		"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+	)
+	from := common.HexToAddress("0x1b0ff2e0e1b4d4e5e1f35e6ba1f02ce8e4a2f95a")
+	to := common.HexToAddress("0x2fce4754d7d852405c8accb2f8f64fccea8b5f1a")
+	contract := common.HexToAddress("0x07865c6e87b9f70255377e024ace6630c1eaa37f")
+
+	// ERC-20 and ERC-721 Transfer events are told
+	// apart by their number of topics
+	receipt := &types.Receipt{
+		Logs: []*types.Log{
+			{
+				Address: contract,
+				Topics: []common.Hash{
+					transferTopic,
+					common.BytesToHash(from.Bytes()),
+					common.BytesToHash(to.Bytes()),
+				},
+				Data:  common.LeftPadBytes(big.NewInt(1000).Bytes(), common.HashLength),
+				Index: 3,
+			},
+			{
+				Address: contract,
+				Topics: []common.Hash{
+					common.HexToHash("0x01"),
+				},
+				Index: 4,
+			},
+			{
+				Address: contract,
+				Topics: []common.Hash{
+					transferTopic,
+					common.BytesToHash(from.Bytes()),
+					common.BytesToHash(to.Bytes()),
+					common.BigToHash(big.NewInt(42)),
+				},
+				Index: 5,
+			},
+			{
+				Address: contract,
+				Topics: []common.Hash{
+					common.HexToHash(
+						"0x17307eab39ab6107e8899845ad3d59bd9653f200f220920489ca2b5937696c31",
+					),
+					common.BytesToHash(from.Bytes()),
+					common.BytesToHash(to.Bytes()),
+				},
+				Data:  common.LeftPadBytes([]byte{1}, common.HashLength),
+				Index: 6,
+			},
+		},
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"event":     "Transfer",
+			"signature": "Transfer(address,address,uint256)",
+			"contract":  "0x07865c6E87B9F70255377e024ace6630C1Eaa37F",
+			"log_index": uint(3),
+			"args": map[string]interface{}{
+				"from":  "0x1B0FF2e0E1B4D4e5E1f35E6Ba1f02cE8e4a2f95A",
+				"to":    "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+				"value": "1000",
+			},
+		},
+		{
+			"event":     "Transfer",
+			"signature": "Transfer(address,address,uint256)",
+			"contract":  "0x07865c6E87B9F70255377e024ace6630C1Eaa37F",
+			"log_index": uint(5),
+			"args": map[string]interface{}{
+				"from":    "0x1B0FF2e0E1B4D4e5E1f35E6Ba1f02cE8e4a2f95A",
+				"to":      "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+				"tokenId": "42",
+			},
+		},
+		{
+			"event":     "ApprovalForAll",
+			"signature": "ApprovalForAll(address,address,bool)",
+			"contract":  "0x07865c6E87B9F70255377e024ace6630C1Eaa37F",
+			"log_index": uint(6),
+			"args": map[string]interface{}{
+				"owner":    "0x1B0FF2e0E1B4D4e5E1f35E6Ba1f02cE8e4a2f95A",
+				"operator": "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+				"approved": true,
+			},
+		},
+	}, r.DecodeReceipt(receipt))
+
+	// A nil *EventRegistry decodes nothing
+	var nilRegistry *EventRegistry
+	assert.Nil(t, nilRegistry.DecodeReceipt(receipt))
+
+	r, err = LoadEventRegistry("testdata/missing_abis")
+	assert.Nil(t, r)
+	assert.Error(t, err)
+}
+
+func TestPendingNonceAt(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionCount",
+		common.HexToAddress("0xfFC614eE978630D7fB0C06758DeB580c152154d3"),
+		"pending",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
+
+			*r = hexutil.Uint64(10)
+		},
+	).Once()
+	resp, err := c.PendingNonceAt(
+		ctx,
+		common.HexToAddress("0xfFC614eE978630D7fB0C06758DeB580c152154d3"),
+	)
+	assert.Equal(t, uint64(10), resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestSuggestGasPrice(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_gasPrice",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Big)
+
+			*r = *(*hexutil.Big)(big.NewInt(100000))
+		},
+	).Once()
+	resp, err := c.SuggestGasPrice(
+		ctx,
+	)
+	assert.Equal(t, big.NewInt(100000), resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestSuggestGasPrice_Metrics(t *testing.T) {
+	metrics.Enabled = true
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry)
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              &metricsJSONRPC{JSONRPC: mockJSONRPC, m: m},
+		g:              &metricsGraphQL{GraphQL: mockGraphQL, m: m},
+		traceSemaphore: semaphore.NewWeighted(100),
+		metrics:        m,
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_gasPrice",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Big)
+
+			*r = *(*hexutil.Big)(big.NewInt(100000))
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_gasPrice",
+	).Return(
+		errors.New("unavailable"),
+	).Once()
+
+	resp, err := c.SuggestGasPrice(ctx)
+	assert.Equal(t, big.NewInt(100000), resp)
+	assert.NoError(t, err)
+
+	resp, err = c.SuggestGasPrice(ctx)
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+
+	timer, ok := registry.Get("geth/rpc/eth_gasPrice").(metrics.Timer)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), timer.Count())
+
+	errs, ok := registry.Get("geth/rpc/eth_gasPrice/errors").(metrics.Counter)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), errs.Count())
+
+	assert.NoError(t, c.acquireTraceSemaphore(ctx))
+	queue, ok := registry.Get("geth/trace/queue").(metrics.Gauge)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), queue.Value())
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestSuggestGasTipCap(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_maxPriorityFeePerGas",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Big)
+
+			*r = *(*hexutil.Big)(big.NewInt(1500000000))
+		},
+	).Once()
+	resp, err := c.SuggestGasTipCap(
+		ctx,
+	)
+	assert.Equal(t, big.NewInt(1500000000), resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestFeeHistory(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_feeHistory",
+		hexutil.Uint64(2),
+		"latest",
+		[]float64{10, 90},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*feeHistoryResult)
+
+			assert.NoError(t, json.Unmarshal([]byte(`{"oldestBlock":"0x64","reward":[["0x3b9aca00","0x77359400"],["0x3b9aca00","0x3b9aca00"]],"baseFeePerGas":["0x2cb417800","0x2cb417800","0x29e8d6080"],"gasUsedRatio":[0.5,0.25]}`), r)) // nolint
+		},
+	).Once()
+	resp, err := c.FeeHistory(
+		ctx,
+		2,
+		nil,
+		[]float64{10, 90},
+	)
+	assert.Equal(t, &FeeHistory{
+		OldestBlock: big.NewInt(100),
+		Reward: [][]*big.Int{
+			{big.NewInt(1000000000), big.NewInt(2000000000)},
+			{big.NewInt(1000000000), big.NewInt(1000000000)},
+		},
+		BaseFee: []*big.Int{
+			big.NewInt(12000000000),
+			big.NewInt(12000000000),
+			big.NewInt(11250000000),
+		},
+		GasUsedRatio: []float64{0.5, 0.25},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestSendTransaction(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_sendRawTransaction",
+		"0xf86a80843b9aca00825208941ff502f9fe838cd772874cb67d0d96b93fd1d6d78725d4b6199a415d8029a01d110bf9fd468f7d00b3ce530832e99818835f45e9b08c66f8d9722264bb36c7a02711f47ec99f9ac585840daef41b7118b52ec72f02fcb30d874d36b10b668b59", // nolint
+	).Return(
+		nil,
+	).Once()
+
+	rawTx, err := ioutil.ReadFile("testdata/submitted_tx.json")
+	assert.NoError(t, err)
+
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalJSON(rawTx))
+
+	assert.NoError(t, c.SendTransaction(
+		ctx,
+		tx,
+	))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestGetMempool(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+	ctx := context.Background()
+	expectedMempool := &RosettaTypes.MempoolResponse{
+		TransactionIdentifiers: []*RosettaTypes.TransactionIdentifier{
+			{Hash: "0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4"},
+			{Hash: "0x0859cb844087a280fa031ce2a0879f4f9832f431d6de67f57d0ca32e90dd9e21"},
+			{Hash: "0xa6de13e0a4465c9b55726d0826d020ed179fa1bda882a00e90aa467266af1815"},
+			{Hash: "0x94e28f01121fd56dead7b89c46c8d6ba32bb79dad5f7e29c1d523224479f10f4"},
+			{Hash: "0xf98b8a6589fa27f66545f31d443140c16eaa3da3896e66c4f05a03defa12cda4"},
+			{Hash: "0x4c652b9e779277f06e17b6a4c4db6658ac012f7c93f5eeab80e7802cce3ff556"},
+			{Hash: "0xa81f636c4b47831efd324dce5c48fe3a3d7a4f0020fae063887e8c2765ff1088"},
+			{Hash: "0x3bc19098a49974002ba933ac8f9c753ce5af538ab93fc68586849e8b8b0dce80"},
+			{Hash: "0x3401ec802cbe4b02d5be18717b53bb8177bd746f95a54da4674d7c27620facda"},
+			{Hash: "0x3d1c58eced8f15f3224e9b2579420d078dbd2adba8e825cee91fd306b0943f89"},
+			{Hash: "0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb"},
+			{Hash: "0xb39652e66c57a6693e44b92b5c471952c26cabf9442a9a76c372f8690658cb4c"},
+			{Hash: "0x1e8700bf7215b2da0cfadcf34717f387577254e0871d828e5453a0593ce8060f"},
+			{Hash: "0x83811383fb7840a03c25a7cbae7e9af138b17853563eb9e212727be2d0b9667f"},
+			{Hash: "0x1e53751e1312cae3324a6b36c67dc95bfec993d7b4939c0de8c0dc761a0afd31"},
+			{Hash: "0xc1052f9378db5a779c42ae2de9a0b94c8a6357c815446d6ba55485dcc1b187ef"},
+		},
+	}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "txpool_content",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r, ok := args.Get(1).(*txPoolContentResponse)
+			assert.True(t, ok)
+
+			file, err := ioutil.ReadFile("testdata/txpool_content.json")
+			assert.NoError(t, err)
+
+			err = json.Unmarshal(file, r)
+			assert.NoError(t, err)
+		},
+	).Once()
+
+	actualMempool, err := c.GetMempool(ctx)
+	assert.NoError(t, err)
+
+	assert.Len(t, actualMempool.TransactionIdentifiers, len(expectedMempool.TransactionIdentifiers))
+
+	// Sort both slices to compare later
+	sort.Slice(expectedMempool.TransactionIdentifiers, func(i, j int) bool {
+		return expectedMempool.TransactionIdentifiers[i].Hash < expectedMempool.TransactionIdentifiers[j].Hash
+	})
+
+	sort.Slice(actualMempool.TransactionIdentifiers, func(i, j int) bool {
+		return actualMempool.TransactionIdentifiers[i].Hash < actualMempool.TransactionIdentifiers[j].Hash
+	})
+
+	assert.True(t, reflect.DeepEqual(actualMempool, expectedMempool))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestGetMempoolTransaction(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+	ctx := context.Background()
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "txpool_content",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r, ok := args.Get(1).(*txPoolContentResponse)
+			assert.True(t, ok)
+
+			file, err := ioutil.ReadFile("testdata/txpool_content.json")
+			assert.NoError(t, err)
+
+			err = json.Unmarshal(file, r)
+			assert.NoError(t, err)
+		},
+	).Times(3)
+
+	t.Run("pending", func(t *testing.T) {
+		tx, err := c.GetMempoolTransaction(
+			ctx,
+			"0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4",
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, &RosettaTypes.Transaction{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+				Hash: "0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4",
+			},
+			Operations: []*RosettaTypes.Operation{
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: 0,
+					},
+					Type: FeeOpType,
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: "0x0297215e64d312d3A239995345E574F73Ef59B02",
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    "-840000000000000",
+						Currency: Currency,
+					},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: 1,
+					},
+					Type: CallOpType,
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: "0x0297215e64d312d3A239995345E574F73Ef59B02",
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    "-2176430000000000",
+						Currency: Currency,
+					},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: 2,
+					},
+					RelatedOperations: []*RosettaTypes.OperationIdentifier{
+						{
+							Index: 1,
+						},
+					},
+					Type: CallOpType,
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: MustChecksum("0x6eff3372fa352b239bb24ff91b423a572347000d"),
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    "2176430000000000",
+						Currency: Currency,
+					},
+				},
+			},
+			Metadata: map[string]interface{}{
+				"from":      "0x0297215e64d312d3A239995345E574F73Ef59B02",
+				"gas_limit": "0x5208",
+				"gas_price": "0x9502f9000",
+				"nonce":     "0x3",
+				"pool":      "pending",
+			},
+		}, tx)
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		tx, err := c.GetMempoolTransaction(
+			ctx,
+			"0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb",
+		)
+		assert.NoError(t, err)
+		assert.Len(t, tx.Operations, 3)
+		assert.Equal(t, "-1849233854076000", tx.Operations[0].Amount.Value)
+		assert.Equal(t, "6349136062952123", tx.Operations[2].Amount.Value)
+		assert.Equal(t, "queued", tx.Metadata["pool"])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		tx, err := c.GetMempoolTransaction(
+			ctx,
+			"0x0000000000000000000000000000000000000000000000000000000000000000",
+		)
+		assert.Nil(t, tx)
+		assert.True(t, errors.Is(err, ethereum.NotFound))
+	})
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestMempoolTransaction_MissingSender(t *testing.T) {
+	c := &Client{}
+	tx := &rpcTransaction{
+		tx: types.NewTransaction(
+			0,
+			common.HexToAddress("0x6efF3372fa352b239Bb24ff91b423A572347000D"),
+			big.NewInt(1),
+			21000,
+			big.NewInt(1),
+			nil,
+		),
+	}
+
+	mempoolTx, err := c.mempoolTransaction(tx, txPoolPending)
+	assert.Nil(t, mempoolTx)
+	assert.Error(t, err)
+}
+
+func TestAccountCoins(t *testing.T) {
+	tests := map[string]struct {
+		address      string
+		balance      string
+		nonce        string
+		pendingNonce uint64
+
+		expectedState *AccountState
+	}{
+		"sender": {
+			address:      "0x0297215e64d312d3A239995345E574F73Ef59B02",
+			balance:      "0xde0b6b3a7640000",
+			nonce:        "0x3",
+			pendingNonce: 4,
+			expectedState: &AccountState{
+				Balances: []*RosettaTypes.Amount{
+					{
+						Value:    "1000000000000000000",
+						Currency: Currency,
+					},
+				},
+				Nonce: 3,
+				PendingBalanceChanges: []*RosettaTypes.Amount{
+					{
+						Value:    "-3016430000000000",
+						Currency: Currency,
+					},
+				},
+				PendingNonce: 4,
+				PendingTransactions: []string{
+					"0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4",
+				},
+				QueuedTransactions: []string{},
+			},
+		},
+		"recipient": {
+			address:      "0x6efF3372fa352b239Bb24ff91b423A572347000D",
+			balance:      "0xde0b6b3a7640000",
+			nonce:        "0x1",
+			pendingNonce: 1,
+			expectedState: &AccountState{
+				Balances: []*RosettaTypes.Amount{
+					{
+						Value:    "1000000000000000000",
+						Currency: Currency,
+					},
+				},
+				Nonce: 1,
+				PendingBalanceChanges: []*RosettaTypes.Amount{
+					{
+						Value:    "21038480000000000",
+						Currency: Currency,
+					},
+				},
+				PendingNonce:        1,
+				PendingTransactions: []string{},
+				QueuedTransactions:  []string{},
+			},
+		},
+		"nonce gap": {
+			address:      "0x4DE23f3f0Fb3318287378AdbdE030cf61714b2f3",
+			balance:      "0xde0b6b3a7640000",
+			nonce:        "0xe9",
+			pendingNonce: 233,
+			expectedState: &AccountState{
+				Balances: []*RosettaTypes.Amount{
+					{
+						Value:    "1000000000000000000",
+						Currency: Currency,
+					},
+				},
+				Nonce: 233,
+				PendingBalanceChanges: []*RosettaTypes.Amount{
+					{
+						Value:    "0",
+						Currency: Currency,
+					},
+				},
+				PendingNonce:        233,
+				PendingTransactions: []string{},
+				QueuedTransactions: []string{
+					"0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb",
+					"0xb39652e66c57a6693e44b92b5c471952c26cabf9442a9a76c372f8690658cb4c",
+					"0x1e8700bf7215b2da0cfadcf34717f387577254e0871d828e5453a0593ce8060f",
+					"0x83811383fb7840a03c25a7cbae7e9af138b17853563eb9e212727be2d0b9667f",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			mockGraphQL := &mocks.GraphQL{}
+
+			c := &Client{
+				c:              mockJSONRPC,
+				g:              mockGraphQL,
+				traceSemaphore: semaphore.NewWeighted(100),
+			}
+
+			ctx := context.Background()
+			mockGraphQL.On(
+				"Query",
+				ctx,
+				`{
+			block(){
+				hash
+				number
+				account(address:"`+test.address+`"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`,
+			).Return(
+				`{"data":{"block":{"hash":"0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda","number":8165,"account":{"balance":"`+test.balance+`","transactionCount":"`+test.nonce+`","code":"0x"}}}}`,
+				nil,
+			).Once()
+			mockJSONRPC.On(
+				"CallContext",
+				ctx,
+				mock.Anything,
+				"eth_getTransactionCount",
+				common.HexToAddress(test.address),
+				"pending",
+			).Return(
+				nil,
+			).Run(
+				func(args mock.Arguments) {
+					r := args.Get(1).(*hexutil.Uint64)
+
+					*r = hexutil.Uint64(test.pendingNonce)
+				},
+			).Once()
+			mockJSONRPC.On(
+				"CallContext", ctx, mock.Anything, "txpool_content",
+			).Return(
+				nil,
+			).Run(
+				func(args mock.Arguments) {
+					r, ok := args.Get(1).(*txPoolContentResponse)
+					assert.True(t, ok)
+
+					file, err := ioutil.ReadFile("testdata/txpool_content.json")
+					assert.NoError(t, err)
+
+					err = json.Unmarshal(file, r)
+					assert.NoError(t, err)
+				},
+			).Once()
+
+			resp, err := c.AccountCoins(
+				ctx,
+				&RosettaTypes.AccountIdentifier{
+					Address: test.address,
+				},
+				nil,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, &RosettaTypes.BlockIdentifier{
+				Hash:  "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda",
+				Index: 8165,
+			}, resp.BlockIdentifier)
+			assert.Equal(t, []*RosettaTypes.Coin{}, resp.Coins)
+
+			var state AccountState
+			assert.NoError(t, RosettaTypes.UnmarshalMap(resp.Metadata, &state))
+			assert.Equal(t, test.expectedState, &state)
+
+			mockJSONRPC.AssertExpectations(t)
+			mockGraphQL.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountCoins_Pinned(t *testing.T) {
+	mockJSONRPC1 := &mocks.JSONRPC{}
+	mockJSONRPC2 := &mocks.JSONRPC{}
+	pool := newNodePool([]*node{
+		{url: "http://node-1:8545", c: mockJSONRPC1},
+		{url: "http://node-2:8545", c: mockJSONRPC2},
+	})
+	pool.nodes[1].setHealthy(false)
+
+	c := &Client{
+		c:              pool,
+		nodes:          pool,
+		balanceAPI:     RPCBalanceAPI,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	address := common.HexToAddress("0x6efF3372fa352b239Bb24ff91b423A572347000D")
+	staleHash := common.HexToHash("0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda")
+	blockHash := common.HexToHash("0x48269a339ce1489cff6bab70eff432289c4f490b81dbd00ff1f81c68de06b842")
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	mockAccount := func(m *mocks.JSONRPC, hash common.Hash, pendingNonce uint64) {
+		m.On(
+			"CallContext",
+			mock.Anything,
+			mock.Anything,
+			"eth_getBlockByNumber",
+			"latest",
+			false,
+		).Return(
+			nil,
+		).Run(
+			func(args mock.Arguments) {
+				r := args.Get(1).(**rpcBlockIdentifier)
+
+				*r = &rpcBlockIdentifier{
+					Hash:   hash,
+					Number: 8165,
+				}
+			},
+		).Once()
+		m.On(
+			"BatchCallContext",
+			mock.Anything,
+			mock.Anything,
+		).Return(
+			nil,
+		).Run(
+			func(args mock.Arguments) {
+				r := args.Get(1).([]rpc.BatchElem)
+
+				*(r[0].Result.(*hexutil.Big)) = *(*hexutil.Big)(big.NewInt(1000000000000000000))
+				*(r[1].Result.(*hexutil.Uint64)) = 1
+				*(r[2].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+			},
+		).Once()
+		m.On(
+			"CallContext",
+			mock.Anything,
+			mock.Anything,
+			"eth_getTransactionCount",
+			address,
+			"pending",
+		).Return(
+			nil,
+		).Run(
+			func(args mock.Arguments) {
+				r := args.Get(1).(*hexutil.Uint64)
+
+				*r = hexutil.Uint64(pendingNonce)
+			},
+		).Once()
+	}
+
+	// The TxPool cannot be fetched from the first node after
+	// the balance and pending nonce were, so all of them are
+	// fetched again from the second node
+	mockAccount(mockJSONRPC1, staleHash, 2)
+	mockJSONRPC1.On(
+		"CallContext", mock.Anything, mock.Anything, "txpool_content",
+	).Return(
+		unreachable,
+	).Once()
+	mockAccount(mockJSONRPC2, blockHash, 1)
+	mockJSONRPC2.On(
+		"CallContext", mock.Anything, mock.Anything, "txpool_content",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r, ok := args.Get(1).(*txPoolContentResponse)
+			assert.True(t, ok)
+
+			file, err := ioutil.ReadFile("testdata/txpool_content.json")
+			assert.NoError(t, err)
+
+			err = json.Unmarshal(file, r)
+			assert.NoError(t, err)
+		},
+	).Once()
+
+	resp, err := c.AccountCoins(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(468194),
+		&RosettaTypes.AccountIdentifier{
+			Address: address.Hex(),
 		},
+		nil,
 	)
 	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  blockHash.Hex(),
+		Index: 8165,
+	}, resp.BlockIdentifier)
+
+	var state AccountState
+	assert.NoError(t, RosettaTypes.UnmarshalMap(resp.Metadata, &state))
+	assert.Equal(t, int64(1), state.Nonce)
+	assert.Equal(t, int64(1), state.PendingNonce)
+	assert.False(t, pool.nodes[0].isHealthy())
+
+	mockJSONRPC1.AssertExpectations(t)
+	mockJSONRPC2.AssertExpectations(t)
+}
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
-	assert.NoError(t, err)
-	assert.Equal(t, correctResp.Block, jsonResp)
+func TestUserOperation_Hash(t *testing.T) {
+	op := &UserOperation{
+		Sender: common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"),
+		Nonce:  (*hexutil.Big)(big.NewInt(0)),
+		InitCode: AccountFactoryInitCode(
+			common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454"),
+			common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+		),
+		CallData: SmartAccountExecuteData(
+			common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"),
+			big.NewInt(10000000000000000),
+			[]byte{},
+		),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(300000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2000000000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1000000000)),
+	}
 
-	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
+	assert.Equal(
+		t,
+		"0x9406cc6185a346906296840746125a0e449764545fbfb9cf0000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf0000000000000000000000000000000000000000000000000000000000000000", // nolint
+		hexutil.Encode(op.InitCode),
+	)
+	assert.Equal(
+		t,
+		"0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000002386f26fc1000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000", // nolint
+		hexutil.Encode(op.CallData),
+	)
+	assert.Equal(
+		t,
+		"0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad",
+		op.Hash(common.HexToAddress(DefaultEntryPoint), big.NewInt(1)).Hex(),
+	)
+
+	// The signature is not part of the hash.
+	op.Signature = []byte{0x01}
+	assert.Equal(
+		t,
+		"0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad",
+		op.Hash(common.HexToAddress(DefaultEntryPoint), big.NewInt(1)).Hex(),
+	)
+	assert.NotEqual(
+		t,
+		"0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad",
+		op.Hash(common.HexToAddress(DefaultEntryPoint), big.NewInt(5)).Hex(),
+	)
 }
 
-// Block with EIP-1559 base fee & txs. This block taken from mainnet:
-//
-//	https://etherscan.io/block/0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2
-//
-// This block has 7 transactions, all EIP-1559 type except the last.
-func TestBlock_13998626(t *testing.T) {
+func TestParseSmartAccountExecuteData(t *testing.T) {
+	to := common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD")
+	transferData := ERC20TransferData(
+		common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+		big.NewInt(5),
+	)
+
+	executeData := SmartAccountExecuteData(to, big.NewInt(0), transferData)
+	assert.Equal(
+		t,
+		"0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000044a9059cbb0000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf000000000000000000000000000000000000000000000000000000000000000500000000000000000000000000000000000000000000000000000000", // nolint
+		hexutil.Encode(executeData),
+	)
+
+	parsedTo, value, data, ok := ParseSmartAccountExecuteData(executeData)
+	assert.True(t, ok)
+	assert.Equal(t, to, parsedTo)
+	assert.Equal(t, big.NewInt(0), value)
+	assert.Equal(t, transferData, data)
+
+	// Calldata that is not an execute call is not parsed.
+	_, _, _, ok = ParseSmartAccountExecuteData(transferData)
+	assert.False(t, ok)
+
+	// The length of the calldata cannot exceed the data.
+	_, _, _, ok = ParseSmartAccountExecuteData(executeData[:len(executeData)-64])
+	assert.False(t, ok)
+}
+
+func TestCounterfactualSmartAccountAddress(t *testing.T) {
+	proxyCode := hexutil.MustDecode(
+		"0x60806040526040516100b83803806100b8833981016040819052610022916100a1565b600080546001600160a01b0319166001600160a01b039390931692909217909155005b", // nolint
+	)
+	factory := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	implementation := common.HexToAddress("0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF")
+	owner := common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+
+	assert.Equal(
+		t,
+		common.HexToAddress("0xBd28f98CFbBed987B01DABB31d2dF3505b9536a0"),
+		CounterfactualSmartAccountAddress(factory, implementation, proxyCode, owner),
+	)
+
+	// Each owner has a different smart account
+	assert.NotEqual(
+		t,
+		CounterfactualSmartAccountAddress(factory, implementation, proxyCode, owner),
+		CounterfactualSmartAccountAddress(factory, implementation, proxyCode, implementation),
+	)
+}
+
+func TestSmartAccountAddress(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
-	tc, err := testTraceConfig()
-	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
 		g:              mockGraphQL,
-		tc:             tc,
-		p:              params.RopstenChainConfig,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -2510,101 +5165,39 @@ func TestBlock_13998626(t *testing.T) {
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getBlockByNumber",
-		"0xd59a22",
-		true,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := ioutil.ReadFile("testdata/block_13998626.json")
-			assert.NoError(t, err)
-
-			*r = json.RawMessage(file)
-		},
-	).Once()
-	mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"debug_traceBlockByHash",
-		common.HexToHash("0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2"),
-		tc,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := ioutil.ReadFile(
-				"testdata/block_trace_0x68985b6b06bb5c6012393145729babb983fc16c50ec5207972ddda02de02f7e2.json") // nolint
-			assert.NoError(t, err)
-			*r = json.RawMessage(file)
+		"eth_call",
+		map[string]interface{}{
+			"to": common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454"),
+			"data": hexutil.Bytes(hexutil.MustDecode(
+				"0x8cb84e180000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf0000000000000000000000000000000000000000000000000000000000000000", // nolint
+			)),
 		},
-	).Once()
-	mockJSONRPC.On(
-		"BatchCallContext",
-		ctx,
-		mock.Anything,
+		"latest",
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).([]rpc.BatchElem)
-			assert.Len(t, r, 7)
-			for i, txHash := range []string{
-				"0xf121c8c07ed51b6ac2d11fe3f0892bff2221ec9168280d12581ea8ff45e71421",
-				"0xef0748860f1c1ba28a5ae3ae9d2d1133940f7c8090fc862acf48de42b00ae2b5",
-				"0xb240b922161bb0aeaa5ebe67e6cf77311092bd945b9582b8deba61e2ebdde74f",
-				"0xfac8149f95c20f62264991fe15dc74ca77c92ad6e4329496548277fb4d520509",
-				"0x0a4cd36d72c2ed4767c1d228a7aa0638c3e46397f48b6b09f35ed455c851bb04",
-				"0x9ee03d5922b2a901e3fc05d8a6351165b9f211162363c790c98746ef229e395c",
-				"0x0d4a4f924858a5b19f6b931a914701d4258e73fa738da3d38eb3be1d1e862a7a",
-			} {
-				assert.Equal(
-					t,
-					txHash,
-					r[i].Args[0],
-				)
+			r := args.Get(1).(*hexutil.Bytes)
 
-				file, err := ioutil.ReadFile(
-					"testdata/tx_receipt_" + txHash + ".json",
-				) // nolint
-				assert.NoError(t, err)
-
-				receipt := new(types.Receipt)
-				assert.NoError(t, receipt.UnmarshalJSON(file))
-				*(r[i].Result.(**types.Receipt)) = receipt
-			}
+			*r = common.LeftPadBytes(
+				common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E").Bytes(),
+				common.HashLength,
+			)
 		},
 	).Once()
-
-	correctRaw, err := ioutil.ReadFile("testdata/block_response_13998626.json")
-	assert.NoError(t, err)
-	var correctResp *RosettaTypes.BlockResponse
-	assert.NoError(t, json.Unmarshal(correctRaw, &correctResp))
-
-	resp, err := c.Block(
+	resp, err := c.SmartAccountAddress(
 		ctx,
-		&RosettaTypes.PartialBlockIdentifier{
-			Index: RosettaTypes.Int64(13998626),
-		},
+		common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454"),
+		common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
 	)
+	assert.Equal(t, common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"), resp)
 	assert.NoError(t, err)
 
-	// Ensure types match
-	jsonResp, err := jsonifyBlock(resp)
-
-	assert.NoError(t, err)
-	assert.Equal(t, correctResp.Block, jsonResp)
-
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestPendingNonceAt(t *testing.T) {
+func TestSmartAccountOwner(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -2619,30 +5212,36 @@ func TestPendingNonceAt(t *testing.T) {
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_getTransactionCount",
-		common.HexToAddress("0xfFC614eE978630D7fB0C06758DeB580c152154d3"),
-		"pending",
+		"eth_call",
+		map[string]interface{}{
+			"to":   common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"),
+			"data": hexutil.Bytes(hexutil.MustDecode("0x8da5cb5b")),
+		},
+		"latest",
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*hexutil.Uint64)
+			r := args.Get(1).(*hexutil.Bytes)
 
-			*r = hexutil.Uint64(10)
+			*r = common.LeftPadBytes(
+				common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf").Bytes(),
+				common.HashLength,
+			)
 		},
 	).Once()
-	resp, err := c.PendingNonceAt(
+	resp, err := c.SmartAccountOwner(
 		ctx,
-		common.HexToAddress("0xfFC614eE978630D7fB0C06758DeB580c152154d3"),
+		common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"),
 	)
-	assert.Equal(t, uint64(10), resp)
+	assert.Equal(t, common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"), resp)
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestSuggestGasPrice(t *testing.T) {
+func TestUserOperationNonce(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
 
@@ -2657,29 +5256,39 @@ func TestSuggestGasPrice(t *testing.T) {
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_gasPrice",
+		"eth_call",
+		map[string]interface{}{
+			"to": common.HexToAddress(DefaultEntryPoint),
+			"data": hexutil.Bytes(hexutil.MustDecode(
+				"0x35567e1a0000000000000000000000002f7a8d7bd2b4a53f3c2b3e1b5e2d9f1c2a4b6d8e0000000000000000000000000000000000000000000000000000000000000000", // nolint
+			)),
+		},
+		"latest",
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*hexutil.Big)
+			r := args.Get(1).(*hexutil.Bytes)
 
-			*r = *(*hexutil.Big)(big.NewInt(100000))
+			*r = common.LeftPadBytes([]byte{0x03}, common.HashLength)
 		},
 	).Once()
-	resp, err := c.SuggestGasPrice(
+	resp, err := c.UserOperationNonce(
 		ctx,
+		common.HexToAddress(DefaultEntryPoint),
+		common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"),
 	)
-	assert.Equal(t, big.NewInt(100000), resp)
+	assert.Equal(t, big.NewInt(3), resp)
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
 }
 
-func TestSendTransaction(t *testing.T) {
+func TestUserOperationBundler(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
+	mockBundler := &mocks.JSONRPC{}
 
 	c := &Client{
 		c:              mockJSONRPC,
@@ -2688,55 +5297,115 @@ func TestSendTransaction(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	mockJSONRPC.On(
+	entryPoint := common.HexToAddress(DefaultEntryPoint)
+	op := &UserOperation{
+		Sender:   common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"),
+		Nonce:    (*hexutil.Big)(big.NewInt(0)),
+		CallData: SmartAccountExecuteData(common.Address{}, big.NewInt(1), []byte{}),
+	}
+
+	// Without a bundler, user operations cannot be
+	// estimated or sent.
+	gas, err := c.EstimateUserOperationGas(ctx, op, entryPoint)
+	assert.Nil(t, gas)
+	assert.True(t, errors.Is(err, ErrBundlerNotConfigured))
+
+	hash, err := c.SendUserOperation(ctx, op, entryPoint)
+	assert.Equal(t, common.Hash{}, hash)
+	assert.True(t, errors.Is(err, ErrBundlerNotConfigured))
+
+	c.bundler = mockBundler
+	mockBundler.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_sendRawTransaction",
-		"0xf86a80843b9aca00825208941ff502f9fe838cd772874cb67d0d96b93fd1d6d78725d4b6199a415d8029a01d110bf9fd468f7d00b3ce530832e99818835f45e9b08c66f8d9722264bb36c7a02711f47ec99f9ac585840daef41b7118b52ec72f02fcb30d874d36b10b668b59", // nolint
+		"eth_estimateUserOperationGas",
+		op,
+		entryPoint,
 	).Return(
 		nil,
-	).Once()
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*UserOperationGas)
 
-	rawTx, err := ioutil.ReadFile("testdata/submitted_tx.json")
+			r.PreVerificationGas = (*hexutil.Big)(big.NewInt(50000))
+			r.VerificationGasLimit = (*hexutil.Big)(big.NewInt(300000))
+			r.CallGasLimit = (*hexutil.Big)(big.NewInt(100000))
+		},
+	).Once()
+	gas, err = c.EstimateUserOperationGas(ctx, op, entryPoint)
+	assert.Equal(t, &UserOperationGas{
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(300000)),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+	}, gas)
 	assert.NoError(t, err)
 
-	tx := new(types.Transaction)
-	assert.NoError(t, tx.UnmarshalJSON(rawTx))
-
-	assert.NoError(t, c.SendTransaction(
+	mockBundler.On(
+		"CallContext",
 		ctx,
-		tx,
-	))
+		mock.Anything,
+		"eth_sendUserOperation",
+		op,
+		entryPoint,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*common.Hash)
+
+			*r = common.HexToHash("0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad")
+		},
+	).Once()
+	hash, err = c.SendUserOperation(ctx, op, entryPoint)
+	assert.Equal(
+		t,
+		common.HexToHash("0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad"),
+		hash,
+	)
+	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
+	mockBundler.AssertExpectations(t)
 }
 
-func TestGetMempool(t *testing.T) {
+func TestSafeTransaction(t *testing.T) {
+	tx := &SafeTransaction{
+		Safe:      common.HexToAddress("0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901"),
+		To:        common.HexToAddress("0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"),
+		Value:     (*hexutil.Big)(big.NewInt(1000000000000000000)),
+		SafeTxGas: (*hexutil.Big)(big.NewInt(0)),
+		BaseGas:   (*hexutil.Big)(big.NewInt(0)),
+		GasPrice:  (*hexutil.Big)(big.NewInt(0)),
+		Nonce:     (*hexutil.Big)(big.NewInt(7)),
+		Signatures: hexutil.MustDecode(
+			"0xf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c5804653519a06481edfc236e7f722f3b35cb15624d24054015117ca9fc7913e1601bf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c58267c25c4d13d1bdcdbb9c252d5de3850497f663387d6585ee32c0b95109104701b", // nolint
+		),
+	}
+
+	// The signatures are not part of the hash.
+	assert.Equal(
+		t,
+		"0xf1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7",
+		tx.Hash(big.NewInt(1)).Hex(),
+	)
+	assert.NotEqual(
+		t,
+		"0xf1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7",
+		tx.Hash(big.NewInt(5)).Hex(),
+	)
+
+	assert.Equal(
+		t,
+		"0x6a7612020000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad0000000000000000000000000000000000000000000000000de0b6b3a76400000000000000000000000000000000000000000000000000000000000000000140000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000016000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000082f973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c5804653519a06481edfc236e7f722f3b35cb15624d24054015117ca9fc7913e1601bf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c58267c25c4d13d1bdcdbb9c252d5de3850497f663387d6585ee32c0b95109104701b000000000000000000000000000000000000000000000000000000000000", // nolint
+		hexutil.Encode(tx.ExecTransactionData()),
+	)
+}
+
+func TestSafeState(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
-	ctx := context.Background()
-	expectedMempool := &RosettaTypes.MempoolResponse{
-		TransactionIdentifiers: []*RosettaTypes.TransactionIdentifier{
-			{Hash: "0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4"},
-			{Hash: "0x0859cb844087a280fa031ce2a0879f4f9832f431d6de67f57d0ca32e90dd9e21"},
-			{Hash: "0xa6de13e0a4465c9b55726d0826d020ed179fa1bda882a00e90aa467266af1815"},
-			{Hash: "0x94e28f01121fd56dead7b89c46c8d6ba32bb79dad5f7e29c1d523224479f10f4"},
-			{Hash: "0xf98b8a6589fa27f66545f31d443140c16eaa3da3896e66c4f05a03defa12cda4"},
-			{Hash: "0x4c652b9e779277f06e17b6a4c4db6658ac012f7c93f5eeab80e7802cce3ff556"},
-			{Hash: "0xa81f636c4b47831efd324dce5c48fe3a3d7a4f0020fae063887e8c2765ff1088"},
-			{Hash: "0x3bc19098a49974002ba933ac8f9c753ce5af538ab93fc68586849e8b8b0dce80"},
-			{Hash: "0x3401ec802cbe4b02d5be18717b53bb8177bd746f95a54da4674d7c27620facda"},
-			{Hash: "0x3d1c58eced8f15f3224e9b2579420d078dbd2adba8e825cee91fd306b0943f89"},
-			{Hash: "0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb"},
-			{Hash: "0xb39652e66c57a6693e44b92b5c471952c26cabf9442a9a76c372f8690658cb4c"},
-			{Hash: "0x1e8700bf7215b2da0cfadcf34717f387577254e0871d828e5453a0593ce8060f"},
-			{Hash: "0x83811383fb7840a03c25a7cbae7e9af138b17853563eb9e212727be2d0b9667f"},
-			{Hash: "0x1e53751e1312cae3324a6b36c67dc95bfec993d7b4939c0de8c0dc761a0afd31"},
-			{Hash: "0xc1052f9378db5a779c42ae2de9a0b94c8a6357c815446d6ba55485dcc1b187ef"},
-		},
-	}
 
 	c := &Client{
 		c:              mockJSONRPC,
@@ -2744,38 +5413,80 @@ func TestGetMempool(t *testing.T) {
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
+	ctx := context.Background()
+	safe := common.HexToAddress("0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901")
+	owners := []common.Address{
+		common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+		common.HexToAddress("0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"),
+	}
 	mockJSONRPC.On(
-		"CallContext", ctx, mock.Anything, "txpool_content",
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r, ok := args.Get(1).(*txPoolContentResponse)
-			assert.True(t, ok)
+			r := args.Get(1).([]rpc.BatchElem)
 
-			file, err := ioutil.ReadFile("testdata/txpool_content.json")
-			assert.NoError(t, err)
+			assert.Len(t, r, 3)
+			for i, methodID := range []string{"0xaffed0e0", "0xa0e67e2b", "0xe75235b8"} {
+				assert.Equal(t, "eth_call", r[i].Method)
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{
+						"to":   safe,
+						"data": hexutil.Bytes(hexutil.MustDecode(methodID)),
+					},
+					"latest",
+				}, r[i].Args)
+			}
 
-			err = json.Unmarshal(file, r)
-			assert.NoError(t, err)
+			ownersResult := append(
+				common.LeftPadBytes([]byte{0x20}, common.HashLength),
+				common.LeftPadBytes([]byte{0x02}, common.HashLength)...,
+			)
+			for _, owner := range owners {
+				ownersResult = append(
+					ownersResult,
+					common.LeftPadBytes(owner.Bytes(), common.HashLength)...,
+				)
+			}
+
+			*(r[0].Result.(*hexutil.Bytes)) = common.LeftPadBytes([]byte{0x07}, common.HashLength)
+			*(r[1].Result.(*hexutil.Bytes)) = ownersResult
+			*(r[2].Result.(*hexutil.Bytes)) = common.LeftPadBytes([]byte{0x02}, common.HashLength)
 		},
 	).Once()
 
-	actualMempool, err := c.GetMempool(ctx)
+	state, err := c.SafeState(ctx, safe)
 	assert.NoError(t, err)
+	assert.Equal(t, &SafeState{
+		Nonce:     big.NewInt(7),
+		Owners:    owners,
+		Threshold: 2,
+	}, state)
 
-	assert.Len(t, actualMempool.TransactionIdentifiers, len(expectedMempool.TransactionIdentifiers))
-
-	// Sort both slices to compare later
-	sort.Slice(expectedMempool.TransactionIdentifiers, func(i, j int) bool {
-		return expectedMempool.TransactionIdentifiers[i].Hash < expectedMempool.TransactionIdentifiers[j].Hash
-	})
+	// Accounts without the Safe methods return no data.
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
 
-	sort.Slice(actualMempool.TransactionIdentifiers, func(i, j int) bool {
-		return actualMempool.TransactionIdentifiers[i].Hash < actualMempool.TransactionIdentifiers[j].Hash
-	})
+			for i := range r {
+				*(r[i].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+			}
+		},
+	).Once()
 
-	assert.True(t, reflect.DeepEqual(actualMempool, expectedMempool))
+	state, err = c.SafeState(ctx, safe)
+	assert.Nil(t, state)
+	assert.EqualError(t, err, "0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901 is not a Safe")
 
 	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
 }
@@ -0,0 +1,159 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// methodIDLength is the length of the method ID
+// at the start of contract calldata.
+const methodIDLength = 4
+
+// ContractCallData returns the calldata of a call to the method
+// with the provided signature (i.e. "transfer(address,uint256)").
+// Each argument is provided as a string: addresses and bytes are
+// hex encoded, integers are decimal or hex encoded, and booleans
+// are "true" or "false".
+func ContractCallData(methodSignature string, methodArgs []string) ([]byte, error) {
+	methodSignature = strings.ReplaceAll(methodSignature, " ", "")
+	argTypes, err := parseMethodSignature(methodSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(argTypes) != len(methodArgs) {
+		return nil, fmt.Errorf(
+			"%s expects %d arguments but got %d",
+			methodSignature,
+			len(argTypes),
+			len(methodArgs),
+		)
+	}
+
+	arguments := make(abi.Arguments, len(argTypes))
+	values := make([]interface{}, len(argTypes))
+	for i, argType := range argTypes {
+		t, err := abi.NewType(argType, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid argument type %s", err, argType)
+		}
+
+		value, err := contractCallArg(t, methodArgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid argument %d", err, i)
+		}
+
+		arguments[i] = abi.Argument{Type: t}
+		values[i] = value
+	}
+
+	packed, err := arguments.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to pack arguments", err)
+	}
+
+	methodID := crypto.Keccak256([]byte(methodSignature))[:methodIDLength]
+	return append(methodID, packed...), nil
+}
+
+// parseMethodSignature returns the argument types
+// of a method signature.
+func parseMethodSignature(methodSignature string) ([]string, error) {
+	open := strings.Index(methodSignature, "(")
+	if open <= 0 || !strings.HasSuffix(methodSignature, ")") {
+		return nil, fmt.Errorf("%s is not a valid method signature", methodSignature)
+	}
+
+	rawArgs := methodSignature[open+1 : len(methodSignature)-1]
+	if strings.ContainsAny(rawArgs, "()") {
+		return nil, fmt.Errorf("%s contains unsupported tuple arguments", methodSignature)
+	}
+
+	if len(rawArgs) == 0 {
+		return []string{}, nil
+	}
+
+	return strings.Split(rawArgs, ","), nil
+}
+
+// contractCallArg converts a string argument into the
+// value expected by abi.Arguments.Pack for abi.Type t.
+func contractCallArg(t abi.Type, raw string) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		address, ok := ChecksumAddress(raw)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a valid address", raw)
+		}
+
+		return common.HexToAddress(address), nil
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(raw, 0)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a valid integer", raw)
+		}
+
+		if t.T == abi.UintTy && (n.Sign() < 0 || n.BitLen() > t.Size) {
+			return nil, fmt.Errorf("%s overflows %s", raw, t.String())
+		}
+
+		if t.T == abi.IntTy && n.BitLen() >= t.Size {
+			return nil, fmt.Errorf("%s overflows %s", raw, t.String())
+		}
+
+		// abi packs sizes of 8, 16, 32, and 64 bits
+		// from native Go integers.
+		if t.GetType() == reflect.TypeOf(n) {
+			return n, nil
+		}
+
+		if t.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(t.GetType()).Interface(), nil
+		}
+
+		return reflect.ValueOf(n.Int64()).Convert(t.GetType()).Interface(), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(raw)
+	case abi.StringTy:
+		return raw, nil
+	case abi.BytesTy:
+		return hexutil.Decode(raw)
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("%s is not %d bytes", raw, t.Size)
+		}
+
+		fixed := reflect.New(t.GetType()).Elem()
+		reflect.Copy(fixed, reflect.ValueOf(b))
+		return fixed.Interface(), nil
+	default:
+		return nil, fmt.Errorf("unsupported argument type %s", t.String())
+	}
+}
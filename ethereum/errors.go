@@ -19,7 +19,11 @@ import "errors"
 // Client errors
 var (
 	ErrBlockOrphaned         = errors.New("block orphaned")
+	ErrBundlerNotConfigured  = errors.New("bundler not configured")
 	ErrCallParametersInvalid = errors.New("call parameters invalid")
 	ErrCallOutputMarshal     = errors.New("call output marshal")
 	ErrCallMethodInvalid     = errors.New("call method invalid")
+	ErrCurrencyNotSupported  = errors.New("currency not supported")
+	ErrInvalidSubAccount     = errors.New("invalid sub-account")
+	ErrInvalidTrace          = errors.New("invalid trace")
 )
@@ -0,0 +1,260 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// abiFileExtension is the extension of the
+	// ABI files loaded into an *EventRegistry.
+	abiFileExtension = ".json"
+)
+
+// EventRegistry contains the events of all contract ABIs
+// supported by the implementation, indexed by event ID (the
+// first topic of their logs). A nil *EventRegistry contains
+// no events.
+type EventRegistry struct {
+	events map[common.Hash][]abi.Event
+}
+
+// NewEventRegistry creates an *EventRegistry from the events
+// of the provided ABIs. Anonymous events are not included, as
+// their logs do not contain an event ID.
+func NewEventRegistry(abis []*abi.ABI) *EventRegistry {
+	r := &EventRegistry{
+		events: map[common.Hash][]abi.Event{},
+	}
+
+	for _, contractABI := range abis {
+		for _, event := range contractABI.Events {
+			if event.Anonymous || r.contains(event) {
+				continue
+			}
+
+			r.events[event.ID] = append(r.events[event.ID], event)
+		}
+	}
+
+	return r
+}
+
+// LoadEventRegistry creates an *EventRegistry from all ABI
+// JSON files in the provided directory.
+func LoadEventRegistry(dir string) (*EventRegistry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read ABI directory", err)
+	}
+
+	// Files are returned sorted by name, so events
+	// are always loaded in the same order.
+	abis := []*abi.ABI{}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != abiFileExtension {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		contents, err := ioutil.ReadFile(path) // #nosec G304
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not load ABI %s", err, path)
+		}
+
+		contractABI, err := abi.JSON(strings.NewReader(string(contents)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not parse ABI %s", err, path)
+		}
+
+		abis = append(abis, &contractABI)
+	}
+
+	return NewEventRegistry(abis), nil
+}
+
+// contains returns a boolean indicating if an event with the
+// same signature and indexed arguments is already registered.
+func (r *EventRegistry) contains(event abi.Event) bool {
+	for _, registered := range r.events[event.ID] {
+		if reflect.DeepEqual(indexedArguments(registered), indexedArguments(event)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexedArguments returns a boolean for each
+// argument of the event indicating if it is indexed.
+func indexedArguments(event abi.Event) []bool {
+	indexed := make([]bool, len(event.Inputs))
+	for i, input := range event.Inputs {
+		indexed[i] = input.Indexed
+	}
+
+	return indexed
+}
+
+// Decode decodes the log of a registered event into its event
+// name, signature, contract, and named arguments. Events that
+// share a signature (i.e. ERC-20 and ERC-721 Transfer) are told
+// apart by their number of indexed arguments. If the log is not
+// of any registered event, it returns !ok.
+func (r *EventRegistry) Decode(log *types.Log) (map[string]interface{}, bool) {
+	if r == nil || len(log.Topics) == 0 {
+		return nil, false
+	}
+
+	for _, event := range r.events[log.Topics[0]] {
+		args, err := decodeEventArgs(event, log)
+		if err != nil {
+			continue
+		}
+
+		return map[string]interface{}{
+			"event":     event.Name,
+			"signature": event.Sig,
+			"contract":  MustChecksum(log.Address.Hex()),
+			"log_index": log.Index,
+			"args":      args,
+		}, true
+	}
+
+	return nil, false
+}
+
+// DecodeReceipt decodes all logs of registered
+// events in the receipt, in the order they were
+// emitted.
+func (r *EventRegistry) DecodeReceipt(receipt *types.Receipt) []map[string]interface{} {
+	if r == nil || receipt == nil {
+		return nil
+	}
+
+	var events []map[string]interface{}
+	for _, log := range receipt.Logs {
+		event, ok := r.Decode(log)
+		if !ok {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// decodeEventArgs decodes the indexed arguments of the event
+// from the topics of the log and all other arguments from its
+// data. Indexed arguments of dynamic types (i.e. strings) are
+// returned as the hash stored in their topic.
+func decodeEventArgs(event abi.Event, log *types.Log) (map[string]interface{}, error) {
+	topics := log.Topics[1:]
+	nonIndexed := event.Inputs.NonIndexed()
+	if len(topics) != len(event.Inputs)-len(nonIndexed) {
+		return nil, fmt.Errorf(
+			"%s expects %d indexed arguments but got %d topics",
+			event.Sig,
+			len(event.Inputs)-len(nonIndexed),
+			len(topics),
+		)
+	}
+
+	values, err := nonIndexed.Unpack(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to unpack data of %s", err, event.Sig)
+	}
+
+	args := map[string]interface{}{}
+	for i, input := range event.Inputs {
+		name := input.Name
+		if len(name) == 0 {
+			name = fmt.Sprintf("arg%d", i)
+		}
+
+		if !input.Indexed {
+			args[name] = eventValue(input.Type, reflect.ValueOf(values[0]))
+			values = values[1:]
+			continue
+		}
+
+		topic := topics[0]
+		topics = topics[1:]
+
+		switch input.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			args[name] = topic.Hex()
+		default:
+			value, err := abi.Arguments{{Type: input.Type}}.Unpack(topic.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to unpack topic of %s", err, name)
+			}
+
+			args[name] = eventValue(input.Type, reflect.ValueOf(value[0]))
+		}
+	}
+
+	return args, nil
+}
+
+// eventValue converts a decoded argument into a JSON value.
+// Integers are returned as decimal strings, addresses are
+// checksummed, and bytes are hex encoded.
+func eventValue(t abi.Type, v reflect.Value) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprint(v.Interface())
+	case abi.AddressTy:
+		return MustChecksum(v.Interface().(common.Address).Hex())
+	case abi.BytesTy:
+		return hexutil.Encode(v.Bytes())
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = eventValue(*t.Elem, v.Index(i))
+		}
+
+		return values
+	case abi.TupleTy:
+		values := map[string]interface{}{}
+		for i, elem := range t.TupleElems {
+			name := t.TupleRawNames[i]
+			if len(name) == 0 {
+				name = fmt.Sprintf("arg%d", i)
+			}
+
+			values[name] = eventValue(*elem, v.Field(i))
+		}
+
+		return values
+	default:
+		return v.Interface()
+	}
+}
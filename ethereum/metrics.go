@@ -0,0 +1,141 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// rpcMetricPrefix is the prefix of the metrics of
+	// each JSON-RPC method.
+	rpcMetricPrefix = "geth/rpc/"
+
+	// batchMetricPrefix is the prefix of the metrics of
+	// each batch of JSON-RPC calls.
+	batchMetricPrefix = "geth/batch/"
+
+	// graphQLMetric is the name of the GraphQL query metrics.
+	graphQLMetric = "geth/graphql"
+
+	// traceQueueMetric is the name of the gauge of the number
+	// of trace requests waiting for the trace semaphore.
+	traceQueueMetric = "geth/trace/queue"
+
+	// errorsMetricSuffix is the suffix of the counter of
+	// errors of a metric.
+	errorsMetricSuffix = "/errors"
+)
+
+// Metrics records the latency and errors of the calls made by
+// the Client to geth. A nil *Metrics records nothing.
+type Metrics struct {
+	registry metrics.Registry
+}
+
+// NewMetrics creates a *Metrics that records into the
+// provided metrics.Registry.
+func NewMetrics(registry metrics.Registry) *Metrics {
+	return &Metrics{registry: registry}
+}
+
+// observe records the duration since start and, if err is
+// not nil, an error for the metric with the provided name.
+func (m *Metrics) observe(name string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	metrics.GetOrRegisterTimer(name, m.registry).UpdateSince(start)
+	if err != nil {
+		metrics.GetOrRegisterCounter(name+errorsMetricSuffix, m.registry).Inc(1)
+	}
+}
+
+// updateTraceQueue adjusts the number of trace requests
+// waiting for the trace semaphore by delta.
+func (m *Metrics) updateTraceQueue(delta int64) {
+	if m == nil {
+		return
+	}
+
+	metrics.GetOrRegisterGauge(traceQueueMetric, m.registry).Inc(delta)
+}
+
+// metricsJSONRPC is a JSONRPC that records the latency and
+// errors of each method.
+type metricsJSONRPC struct {
+	JSONRPC
+
+	m *Metrics
+}
+
+// CallContext calls the method and records its latency.
+func (c *metricsJSONRPC) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	start := time.Now()
+	err := c.JSONRPC.CallContext(ctx, result, method, args...)
+	c.m.observe(rpcMetricPrefix+method, start, err)
+	return err
+}
+
+// BatchCallContext sends the batch and records its latency
+// under the method of its first call. All batches sent by the
+// Client only contain calls to a single method.
+func (c *metricsJSONRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	start := time.Now()
+	err := c.JSONRPC.BatchCallContext(ctx, b)
+	if len(b) == 0 {
+		return err
+	}
+
+	// Errors of individual calls are returned in b
+	// instead of by BatchCallContext.
+	observedErr := err
+	for _, elem := range b {
+		if observedErr != nil {
+			break
+		}
+
+		observedErr = elem.Error
+	}
+
+	c.m.observe(batchMetricPrefix+b[0].Method, start, observedErr)
+	return err
+}
+
+// metricsGraphQL is a GraphQL that records the latency
+// and errors of each query.
+type metricsGraphQL struct {
+	GraphQL
+
+	m *Metrics
+}
+
+// Query sends the query and records its latency.
+func (g *metricsGraphQL) Query(ctx context.Context, input string) (string, error) {
+	start := time.Now()
+	result, err := g.GraphQL.Query(ctx, input)
+	g.m.observe(graphQLMetric, start, err)
+	return result, err
+}
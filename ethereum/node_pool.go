@@ -0,0 +1,297 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// nodeHealthCheckInterval is the interval at which
+	// the health of each node is checked.
+	nodeHealthCheckInterval = 10 * time.Second
+
+	// nodeHealthCheckTimeout is the maximum duration
+	// of the health check of a node.
+	nodeHealthCheckTimeout = 5 * time.Second
+
+	// maxNodeHeadLag is the number of blocks a node can be
+	// behind the highest head of all nodes and still be
+	// considered healthy.
+	maxNodeHeadLag = 2
+)
+
+// node is a single geth node in a nodePool.
+type node struct {
+	url string
+	c   JSONRPC
+	g   GraphQL
+
+	mu      sync.RWMutex
+	healthy bool
+}
+
+func (n *node) isHealthy() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.healthy
+}
+
+func (n *node) setHealthy(healthy bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.healthy && !healthy {
+		log.Printf("geth node %s is unhealthy\n", n.url)
+	}
+	n.healthy = healthy
+}
+
+// pinnedNodeKey is the context key of the *node
+// all calls of a request are routed to.
+type pinnedNodeKey struct{}
+
+// nodePool is a JSONRPC and GraphQL that load balances calls
+// across multiple geth nodes. Calls are routed to healthy
+// nodes first and fail over to the next node on errors that
+// are not returned by geth itself.
+type nodePool struct {
+	nodes []*node
+	next  uint32
+
+	done chan struct{}
+}
+
+// newNodePool creates a *nodePool of the provided nodes. All
+// nodes are considered healthy until they are checked.
+func newNodePool(nodes []*node) *nodePool {
+	for _, n := range nodes {
+		n.healthy = true
+	}
+
+	return &nodePool{
+		nodes: nodes,
+		done:  make(chan struct{}),
+	}
+}
+
+// start checks the health of all nodes at
+// nodeHealthCheckInterval until the pool is closed.
+func (p *nodePool) start() {
+	go func() {
+		ticker := time.NewTicker(nodeHealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), nodeHealthCheckTimeout)
+				p.checkHealth(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// checkHealth updates the health of all nodes. A node is healthy
+// if it is not syncing and its head is no more than maxNodeHeadLag
+// blocks behind the highest head of all nodes.
+func (p *nodePool) checkHealth(ctx context.Context) {
+	reachable := make([]bool, len(p.nodes))
+	heads := make([]uint64, len(p.nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+
+			head, err := nodeHead(ctx, n)
+			if err != nil {
+				log.Printf("%s: health check of geth node %s failed\n", err.Error(), n.url)
+				return
+			}
+
+			reachable[i] = true
+			heads[i] = head
+		}(i, n)
+	}
+	wg.Wait()
+
+	var highestHead uint64
+	for i := range p.nodes {
+		if reachable[i] && heads[i] > highestHead {
+			highestHead = heads[i]
+		}
+	}
+
+	for i, n := range p.nodes {
+		n.setHealthy(reachable[i] && heads[i]+maxNodeHeadLag >= highestHead)
+	}
+}
+
+// nodeHead returns the head of the node. It
+// returns an error if the node is syncing.
+func nodeHead(ctx context.Context, n *node) (uint64, error) {
+	var syncing json.RawMessage
+	if err := n.c.CallContext(ctx, &syncing, "eth_syncing"); err != nil {
+		return 0, err
+	}
+
+	// geth returns false when the node is not syncing and
+	// an object with the sync progress while it is, which
+	// cannot be decoded into isSyncing.
+	var isSyncing bool
+	if err := json.Unmarshal(syncing, &isSyncing); err != nil || isSyncing {
+		return 0, errors.New("node is syncing")
+	}
+
+	var head hexutil.Uint64
+	if err := n.c.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+
+	return uint64(head), nil
+}
+
+// candidates returns all nodes in the order they should be
+// tried. Healthy nodes are returned first, in round-robin
+// order, followed by unhealthy nodes as a last resort.
+func (p *nodePool) candidates() []*node {
+	start := int(atomic.AddUint32(&p.next, 1))
+
+	healthy := make([]*node, 0, len(p.nodes))
+	unhealthy := make([]*node, 0, len(p.nodes))
+	for i := range p.nodes {
+		n := p.nodes[(start+i)%len(p.nodes)]
+		if n.isHealthy() {
+			healthy = append(healthy, n)
+		} else {
+			unhealthy = append(unhealthy, n)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+// shouldFailover returns a boolean indicating if a call that
+// returned err should be retried on another node. Only errors
+// reaching the node are retried, as any other node would return
+// the same result for errors returned by geth (i.e. a reverted
+// call or missing data).
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	return errors.As(err, &netErr) || errors.As(err, &httpErr)
+}
+
+// call calls fn on the node pinned in ctx or, if no node
+// is pinned, on each candidate node until it succeeds.
+func (p *nodePool) call(ctx context.Context, fn func(n *node) error) error {
+	if n, ok := ctx.Value(pinnedNodeKey{}).(*node); ok {
+		return fn(n)
+	}
+
+	var err error
+	for _, n := range p.candidates() {
+		err = fn(n)
+		if err == nil || !shouldFailover(ctx, err) {
+			return err
+		}
+
+		n.setHealthy(false)
+	}
+
+	return err
+}
+
+// pin calls fn with a context that routes all calls to a
+// single node, so that the data fetched by fn is consistent.
+// If fn fails, it is retried on the next candidate node.
+func (p *nodePool) pin(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pinnedNodeKey{}).(*node); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for _, n := range p.candidates() {
+		err = fn(context.WithValue(ctx, pinnedNodeKey{}, n))
+		if err == nil || !shouldFailover(ctx, err) {
+			return err
+		}
+
+		n.setHealthy(false)
+	}
+
+	return err
+}
+
+// CallContext calls the method on a healthy node.
+func (p *nodePool) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	return p.call(ctx, func(n *node) error {
+		return n.c.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext sends the batch to a healthy node.
+func (p *nodePool) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return p.call(ctx, func(n *node) error {
+		return n.c.BatchCallContext(ctx, b)
+	})
+}
+
+// Query sends the GraphQL query to a healthy node.
+func (p *nodePool) Query(ctx context.Context, input string) (string, error) {
+	var result string
+	err := p.call(ctx, func(n *node) error {
+		var err error
+		result, err = n.g.Query(ctx, input)
+		return err
+	})
+
+	return result, err
+}
+
+// Close stops checking the health of all
+// nodes and closes their connections.
+func (p *nodePool) Close() {
+	close(p.done)
+
+	for _, n := range p.nodes {
+		n.c.Close()
+	}
+}
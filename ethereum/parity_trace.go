@@ -0,0 +1,266 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TraceAPI is the API used to trace blocks and transactions.
+type TraceAPI string
+
+const (
+	// DebugTraceAPI traces with geth's debug_traceBlockByHash and
+	// debug_traceTransaction using the configured Tracer (geth's
+	// native callTracer by default).
+	DebugTraceAPI TraceAPI = "DEBUG"
+
+	// ParityTraceAPI traces with the OpenEthereum-style trace_block
+	// and trace_transaction supported by Erigon, Nethermind, and
+	// Besu.
+	ParityTraceAPI TraceAPI = "PARITY"
+)
+
+const (
+	parityCallTrace     = "call"
+	parityCreateTrace   = "create"
+	paritySuicideTrace  = "suicide"
+	parityCreate2Method = "create2"
+)
+
+// parityTrace is a single trace returned by trace_block
+// and trace_transaction. Each trace is a call, create, suicide,
+// or reward. The traces of a transaction are returned in the
+// order they are executed and are nested by traceAddress.
+type parityTrace struct {
+	Action struct {
+		CallType       string          `json:"callType"`
+		CreationMethod string          `json:"creationMethod"`
+		From           common.Address  `json:"from"`
+		To             *common.Address `json:"to"`
+		Value          *hexutil.Big    `json:"value"`
+		Input          hexutil.Bytes   `json:"input"`
+		Address        common.Address  `json:"address"`
+		RefundAddress  common.Address  `json:"refundAddress"`
+		Balance        *hexutil.Big    `json:"balance"`
+	} `json:"action"`
+	BlockHash *common.Hash `json:"blockHash"`
+	Result    *struct {
+		GasUsed *hexutil.Big    `json:"gasUsed"`
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+	Error               string  `json:"error"`
+	TraceAddress        []int   `json:"traceAddress"`
+	TransactionPosition *uint64 `json:"transactionPosition"`
+	Type                string  `json:"type"`
+}
+
+// callFrame is a trace in the format returned by geth's native
+// callTracer (a nested frame per call with a hex value, gasUsed,
+// and input), so that it can be decoded into a *Call.
+type callFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value"`
+	GasUsed *hexutil.Big   `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []*callFrame   `json:"calls,omitempty"`
+}
+
+// parityCallFrame converts a call, create, or suicide
+// *parityTrace into a *callFrame. It returns !ok for any
+// other trace (i.e. a block reward).
+func parityCallFrame(trace *parityTrace) (*callFrame, bool) {
+	frame := &callFrame{
+		From:    trace.Action.From,
+		Value:   trace.Action.Value,
+		GasUsed: (*hexutil.Big)(new(big.Int)),
+		Input:   trace.Action.Input,
+		Error:   trace.Error,
+	}
+
+	if trace.Result != nil && trace.Result.GasUsed != nil {
+		frame.GasUsed = trace.Result.GasUsed
+	}
+
+	switch trace.Type {
+	case parityCallTrace:
+		frame.Type = strings.ToUpper(trace.Action.CallType)
+		if trace.Action.To != nil {
+			frame.To = *trace.Action.To
+		}
+	case parityCreateTrace:
+		frame.Type = CreateOpType
+		if trace.Action.CreationMethod == parityCreate2Method {
+			frame.Type = Create2OpType
+		}
+
+		if trace.Result != nil && trace.Result.Address != nil {
+			frame.To = *trace.Result.Address
+		}
+	case paritySuicideTrace:
+		frame.Type = SelfDestructOpType
+		frame.From = trace.Action.Address
+		frame.To = trace.Action.RefundAddress
+		frame.Value = trace.Action.Balance
+	default:
+		return nil, false
+	}
+
+	if frame.Value == nil {
+		frame.Value = (*hexutil.Big)(new(big.Int))
+	}
+
+	return frame, true
+}
+
+// parityTransactionTrace nests the traces of a single transaction
+// into a *callFrame tree using the traceAddress of each trace.
+func parityTransactionTrace(traces []*parityTrace) (*callFrame, error) {
+	var root *callFrame
+
+	// Traces are ordered depth-first, so the parent of each
+	// trace is the last trace at the previous depth.
+	var parents []*callFrame
+	for _, trace := range traces {
+		frame, ok := parityCallFrame(trace)
+		if !ok {
+			continue
+		}
+
+		depth := len(trace.TraceAddress)
+		if depth == 0 {
+			if root != nil {
+				return nil, fmt.Errorf("%w: multiple root traces", ErrInvalidTrace)
+			}
+
+			root = frame
+			parents = []*callFrame{frame}
+			continue
+		}
+
+		if depth > len(parents) {
+			return nil, fmt.Errorf(
+				"%w: trace %v has no parent",
+				ErrInvalidTrace,
+				trace.TraceAddress,
+			)
+		}
+
+		parent := parents[depth-1]
+		parent.Calls = append(parent.Calls, frame)
+		parents = append(parents[:depth], frame)
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("%w: missing root trace", ErrInvalidTrace)
+	}
+
+	return root, nil
+}
+
+// parityBlockTraces converts the result of trace_block into the
+// format returned by debug_traceBlockByHash.
+func parityBlockTraces(
+	traces []*parityTrace,
+	blockHash common.Hash,
+	transactions int,
+) (json.RawMessage, error) {
+	transactionTraces := make([][]*parityTrace, transactions)
+	for _, trace := range traces {
+		// Block and uncle rewards are not
+		// part of any transaction.
+		if trace.TransactionPosition == nil {
+			continue
+		}
+
+		if trace.BlockHash != nil && *trace.BlockHash != blockHash {
+			return nil, fmt.Errorf(
+				"%w: expected block hash %s for trace but got %s",
+				ErrBlockOrphaned,
+				blockHash.Hex(),
+				trace.BlockHash.Hex(),
+			)
+		}
+
+		position := *trace.TransactionPosition
+		if position >= uint64(transactions) {
+			return nil, fmt.Errorf(
+				"%w: transaction position %d is out of range",
+				ErrInvalidTrace,
+				position,
+			)
+		}
+
+		transactionTraces[position] = append(transactionTraces[position], trace)
+	}
+
+	results := make([]*struct {
+		Result *callFrame `json:"result"`
+	}, transactions)
+	for i, traces := range transactionTraces {
+		frame, err := parityTransactionTrace(traces)
+		if err != nil {
+			return nil, fmt.Errorf("%w: transaction %d", err, i)
+		}
+
+		results[i] = &struct {
+			Result *callFrame `json:"result"`
+		}{Result: frame}
+	}
+
+	return json.Marshal(results)
+}
+
+func (ec *Client) getParityTransactionTraces(
+	ctx context.Context,
+	transactionHash common.Hash,
+) (json.RawMessage, error) {
+	var traces []*parityTrace
+	if err := ec.c.CallContext(ctx, &traces, "trace_transaction", transactionHash); err != nil {
+		return nil, err
+	}
+
+	frame, err := parityTransactionTrace(traces)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(frame)
+}
+
+func (ec *Client) getParityBlockTraces(
+	ctx context.Context,
+	blockHash common.Hash,
+	blockNumber *big.Int,
+	transactions int,
+) (json.RawMessage, error) {
+	var traces []*parityTrace
+	err := ec.c.CallContext(ctx, &traces, "trace_block", hexutil.EncodeBig(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	return parityBlockTraces(traces, blockHash, transactions)
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// recentBlocksSize is the number of blocks
+	// kept in a *recentBlocks.
+	recentBlocksSize = 8
+
+	// recentBlocksFetchTimeout is the timeout of a fetch
+	// shared by concurrent loads of the same block, which
+	// is not canceled with the context of any single load.
+	recentBlocksFetchTimeout = 2 * gethHTTPTimeout
+)
+
+// recentBlocks keeps the receipts and traces of the most recently
+// loaded blocks in memory. Concurrent loads of the same block are
+// deduplicated, so that requests for each transaction of a block
+// are served from a single block trace. A nil *recentBlocks
+// keeps nothing.
+type recentBlocks struct {
+	mu     sync.Mutex
+	blocks map[common.Hash]*cachedBlock
+	order  []common.Hash
+
+	group singleflight.Group
+}
+
+// newRecentBlocks creates an empty *recentBlocks.
+func newRecentBlocks() *recentBlocks {
+	return &recentBlocks{
+		blocks: map[common.Hash]*cachedBlock{},
+	}
+}
+
+// load returns the *cachedBlock with the provided hash, calling
+// fetch if the block is not kept. If the block is already being
+// fetched, load waits for that fetch instead. The fetch is shared
+// by all waiting loads, so it is called with a context that keeps
+// the values of ctx but is only canceled by its own timeout, and
+// each load stops waiting when its own ctx is canceled.
+func (r *recentBlocks) load(
+	ctx context.Context,
+	hash common.Hash,
+	fetch func(ctx context.Context) (*cachedBlock, error),
+) (*cachedBlock, error) {
+	if r == nil {
+		return fetch(ctx)
+	}
+
+	r.mu.Lock()
+	block, ok := r.blocks[hash]
+	r.mu.Unlock()
+	if ok {
+		return block, nil
+	}
+
+	ch := r.group.DoChan(hash.Hex(), func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(
+			detachedContext{ctx},
+			recentBlocksFetchTimeout,
+		)
+		defer cancel()
+
+		block, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.add(hash, block)
+		return block, nil
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*cachedBlock), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// add keeps the block, evicting the
+// oldest block if the cache is full.
+func (r *recentBlocks) add(hash common.Hash, block *cachedBlock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.blocks[hash]; ok {
+		return
+	}
+
+	if len(r.order) == recentBlocksSize {
+		delete(r.blocks, r.order[0])
+		r.order = r.order[1:]
+	}
+
+	r.blocks[hash] = block
+	r.order = append(r.order, hash)
+}
+
+// detachedContext is a context.Context with the values of
+// its parent that is never canceled and has no deadline.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns nil, as a detachedContext is never canceled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil, as a detachedContext is never canceled.
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// safeExecTransactionHeadWords is the number of 32-byte
+	// words in the head of the execTransaction arguments.
+	safeExecTransactionHeadWords = 10
+)
+
+var (
+	// safeDomainSeparatorTypeHash is the EIP-712 type hash of
+	// the domain of a Safe (v1.3.0 or later).
+	safeDomainSeparatorTypeHash = crypto.Keccak256(
+		[]byte("EIP712Domain(uint256 chainId,address verifyingContract)"),
+	)
+
+	// safeTxTypeHash is the EIP-712 type hash of a Safe transaction.
+	safeTxTypeHash = crypto.Keccak256([]byte(
+		"SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas," +
+			"uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver," +
+			"uint256 nonce)",
+	))
+
+	// safeExecTransactionMethodID is the method ID of the Safe
+	// execTransaction method, which executes a Safe transaction
+	// signed by its owners.
+	safeExecTransactionMethodID = crypto.Keccak256([]byte(
+		"execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)",
+	))[:methodIDLength]
+
+	safeNonceMethodID     = crypto.Keccak256([]byte("nonce()"))[:methodIDLength]
+	safeGetOwnersMethodID = crypto.Keccak256([]byte("getOwners()"))[:methodIDLength]
+	safeThresholdMethodID = crypto.Keccak256([]byte("getThreshold()"))[:methodIDLength]
+)
+
+// SafeTransaction is a transaction executed by a Safe once it
+// is signed by enough of its owners. Only calls (operation 0)
+// without gas refunds are constructed, so the executor of the
+// transaction pays for its gas.
+type SafeTransaction struct {
+	Safe           common.Address `json:"safe"`
+	To             common.Address `json:"to"`
+	Value          *hexutil.Big   `json:"value"`
+	Data           hexutil.Bytes  `json:"data"`
+	Operation      uint8          `json:"operation"`
+	SafeTxGas      *hexutil.Big   `json:"safeTxGas"`
+	BaseGas        *hexutil.Big   `json:"baseGas"`
+	GasPrice       *hexutil.Big   `json:"gasPrice"`
+	GasToken       common.Address `json:"gasToken"`
+	RefundReceiver common.Address `json:"refundReceiver"`
+	Nonce          *hexutil.Big   `json:"nonce"`
+	Signatures     hexutil.Bytes  `json:"signatures"`
+}
+
+// SafeState is the nonce, owners, and signature
+// threshold of a Safe.
+type SafeState struct {
+	Nonce     *big.Int
+	Owners    []common.Address
+	Threshold uint64
+}
+
+// Hash returns the EIP-712 hash of the Safe transaction (the
+// safeTxHash) signed by the owners of the Safe. The hash commits
+// to the Safe and chain ID, so a signed Safe transaction cannot
+// be replayed by another Safe or on another chain.
+func (tx *SafeTransaction) Hash(chainID *big.Int) common.Hash {
+	domainSeparator := crypto.Keccak256(
+		safeDomainSeparatorTypeHash,
+		uint256Word(chainID),
+		common.LeftPadBytes(tx.Safe.Bytes(), common.HashLength),
+	)
+
+	safeTxHash := crypto.Keccak256(
+		safeTxTypeHash,
+		common.LeftPadBytes(tx.To.Bytes(), common.HashLength),
+		uint256Word(tx.Value.ToInt()),
+		crypto.Keccak256(tx.Data),
+		uint256Word(big.NewInt(int64(tx.Operation))),
+		uint256Word(tx.SafeTxGas.ToInt()),
+		uint256Word(tx.BaseGas.ToInt()),
+		uint256Word(tx.GasPrice.ToInt()),
+		common.LeftPadBytes(tx.GasToken.Bytes(), common.HashLength),
+		common.LeftPadBytes(tx.RefundReceiver.Bytes(), common.HashLength),
+		uint256Word(tx.Nonce.ToInt()),
+	)
+
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, safeTxHash)
+}
+
+// ExecTransactionData returns the calldata of the execTransaction
+// call to the Safe that executes the signed Safe transaction.
+func (tx *SafeTransaction) ExecTransactionData() []byte {
+	data := abiBytes(tx.Data)
+	signatures := abiBytes(tx.Signatures)
+
+	// The data and signatures arguments are dynamic, so
+	// they are encoded after the head, starting with
+	// their offsets.
+	dataOffset := safeExecTransactionHeadWords * common.HashLength
+	signaturesOffset := dataOffset + len(data)
+
+	execData := make(
+		[]byte,
+		0,
+		methodIDLength+dataOffset+len(data)+len(signatures),
+	)
+	execData = append(execData, safeExecTransactionMethodID...)
+	execData = append(execData, common.LeftPadBytes(tx.To.Bytes(), common.HashLength)...)
+	execData = append(execData, uint256Word(tx.Value.ToInt())...)
+	execData = append(execData, uint256Word(big.NewInt(int64(dataOffset)))...)
+	execData = append(execData, uint256Word(big.NewInt(int64(tx.Operation)))...)
+	execData = append(execData, uint256Word(tx.SafeTxGas.ToInt())...)
+	execData = append(execData, uint256Word(tx.BaseGas.ToInt())...)
+	execData = append(execData, uint256Word(tx.GasPrice.ToInt())...)
+	execData = append(execData, common.LeftPadBytes(tx.GasToken.Bytes(), common.HashLength)...)
+	execData = append(execData, common.LeftPadBytes(tx.RefundReceiver.Bytes(), common.HashLength)...)
+	execData = append(execData, uint256Word(big.NewInt(int64(signaturesOffset)))...)
+	execData = append(execData, data...)
+	execData = append(execData, signatures...)
+	return execData
+}
+
+// SafeState returns the nonce, owners, and signature
+// threshold of the Safe at safe.
+func (ec *Client) SafeState(ctx context.Context, safe common.Address) (*SafeState, error) {
+	var nonce, owners, threshold hexutil.Bytes
+	reqs := []rpc.BatchElem{
+		safeCall(safe, safeNonceMethodID, &nonce),
+		safeCall(safe, safeGetOwnersMethodID, &owners),
+		safeCall(safe, safeThresholdMethodID, &threshold),
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, reqs[i].Error
+		}
+	}
+
+	if len(nonce) != common.HashLength || len(threshold) != common.HashLength {
+		return nil, fmt.Errorf("%s is not a Safe", safe.Hex())
+	}
+
+	state := &SafeState{
+		Nonce: new(big.Int).SetBytes(nonce),
+	}
+
+	thresholdValue := new(big.Int).SetBytes(threshold)
+	if !thresholdValue.IsUint64() {
+		return nil, fmt.Errorf("%s returned an invalid threshold", safe.Hex())
+	}
+	state.Threshold = thresholdValue.Uint64()
+
+	// getOwners returns a dynamic address[], which is
+	// encoded as its offset, length, and addresses.
+	if len(owners) < 2*common.HashLength {
+		return nil, fmt.Errorf("%s returned %d bytes for getOwners", safe.Hex(), len(owners))
+	}
+
+	count := new(big.Int).SetBytes(owners[common.HashLength : 2*common.HashLength])
+	if !count.IsUint64() || uint64(len(owners)) != uint64(2+count.Uint64())*common.HashLength {
+		return nil, fmt.Errorf("%s returned %d bytes for getOwners", safe.Hex(), len(owners))
+	}
+
+	for i := uint64(0); i < count.Uint64(); i++ {
+		start := (2 + i) * common.HashLength
+		state.Owners = append(
+			state.Owners,
+			common.BytesToAddress(owners[start:start+common.HashLength]),
+		)
+	}
+
+	return state, nil
+}
+
+// safeCall returns the rpc.BatchElem of an eth_call
+// of a Safe method without arguments.
+func safeCall(safe common.Address, methodID []byte, result *hexutil.Bytes) rpc.BatchElem {
+	return rpc.BatchElem{
+		Method: "eth_call",
+		Args: []interface{}{
+			map[string]interface{}{
+				"to":   safe,
+				"data": hexutil.Bytes(methodID),
+			},
+			"latest",
+		},
+		Result: result,
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// ContractAddressKey is the key in the metadata of a token
+	// *RosettaTypes.Currency that holds the contract address.
+	ContractAddressKey = "contract_address"
+
+	// erc20AmountLength is the length of an ABI-encoded uint256.
+	erc20AmountLength = 32
+)
+
+var (
+	// erc20TransferEventTopic is the topic of the ERC-20
+	// Transfer(address,address,uint256) event.
+	erc20TransferEventTopic = crypto.Keccak256Hash(
+		[]byte("Transfer(address,address,uint256)"),
+	)
+
+	// erc20TransferMethodID is the method ID of the ERC-20
+	// transfer(address,uint256) method.
+	erc20TransferMethodID = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+
+	// erc20BalanceOfMethodID is the method ID of the ERC-20
+	// balanceOf(address) method.
+	erc20BalanceOfMethodID = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+)
+
+// Token is an ERC-20 token supported by the implementation.
+type Token struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+// TokenRegistry contains all ERC-20 tokens supported by
+// the implementation, indexed by contract address. A nil
+// *TokenRegistry contains no tokens.
+type TokenRegistry struct {
+	currencies map[common.Address]*RosettaTypes.Currency
+}
+
+// NewTokenRegistry creates a *TokenRegistry from a list
+// of tokens.
+func NewTokenRegistry(tokens []*Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{
+		currencies: map[common.Address]*RosettaTypes.Currency{},
+	}
+
+	for _, token := range tokens {
+		address, ok := ChecksumAddress(token.Address)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a valid token address", token.Address)
+		}
+
+		if len(token.Symbol) == 0 {
+			return nil, fmt.Errorf("token %s is missing a symbol", address)
+		}
+
+		if token.Decimals < 0 {
+			return nil, fmt.Errorf("token %s has negative decimals", address)
+		}
+
+		contract := common.HexToAddress(address)
+		if _, ok := r.currencies[contract]; ok {
+			return nil, fmt.Errorf("token %s is duplicated", address)
+		}
+
+		r.currencies[contract] = &RosettaTypes.Currency{
+			Symbol:   token.Symbol,
+			Decimals: token.Decimals,
+			Metadata: map[string]interface{}{
+				ContractAddressKey: address,
+			},
+		}
+	}
+
+	return r, nil
+}
+
+// LoadTokenRegistry creates a *TokenRegistry from a JSON
+// file containing a list of tokens.
+func LoadTokenRegistry(path string) (*TokenRegistry, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not load token list", err)
+	}
+
+	var tokens []*Token
+	if err := json.Unmarshal(contents, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: could not parse token list", err)
+	}
+
+	return NewTokenRegistry(tokens)
+}
+
+// Currency returns the *RosettaTypes.Currency of the token
+// deployed at the provided contract address.
+func (r *TokenRegistry) Currency(contract common.Address) (*RosettaTypes.Currency, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	currency, ok := r.currencies[contract]
+	return currency, ok
+}
+
+// Contract returns the contract address of the token
+// represented by the provided *RosettaTypes.Currency.
+func (r *TokenRegistry) Contract(currency *RosettaTypes.Currency) (common.Address, bool) {
+	if r == nil || currency == nil {
+		return common.Address{}, false
+	}
+
+	rawAddress, ok := currency.Metadata[ContractAddressKey].(string)
+	if !ok {
+		return common.Address{}, false
+	}
+
+	address, ok := ChecksumAddress(rawAddress)
+	if !ok {
+		return common.Address{}, false
+	}
+
+	contract := common.HexToAddress(address)
+	registered, ok := r.currencies[contract]
+	if !ok {
+		return common.Address{}, false
+	}
+
+	if registered.Symbol != currency.Symbol || registered.Decimals != currency.Decimals {
+		return common.Address{}, false
+	}
+
+	return contract, true
+}
+
+// Currencies returns the *RosettaTypes.Currency of all
+// supported tokens, sorted by contract address.
+func (r *TokenRegistry) Currencies() []*RosettaTypes.Currency {
+	if r == nil {
+		return []*RosettaTypes.Currency{}
+	}
+
+	contracts := make([]common.Address, 0, len(r.currencies))
+	for contract := range r.currencies {
+		contracts = append(contracts, contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Hex() < contracts[j].Hex()
+	})
+
+	currencies := make([]*RosettaTypes.Currency, len(contracts))
+	for i, contract := range contracts {
+		currencies[i] = r.currencies[contract]
+	}
+
+	return currencies
+}
+
+// ERC20TransferData returns the calldata of an ERC-20
+// transfer(address,uint256) call.
+func ERC20TransferData(to common.Address, amount *big.Int) []byte {
+	data := make([]byte, 0, len(erc20TransferMethodID)+2*common.HashLength)
+	data = append(data, erc20TransferMethodID...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), common.HashLength)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), common.HashLength)...)
+	return data
+}
+
+// ParseERC20TransferData returns the recipient and amount
+// of an ERC-20 transfer(address,uint256) call. If the calldata
+// is not a transfer call, it returns !ok.
+func ParseERC20TransferData(data []byte) (common.Address, *big.Int, bool) {
+	if len(data) != len(erc20TransferMethodID)+2*common.HashLength {
+		return common.Address{}, nil, false
+	}
+
+	if !bytes.Equal(data[:len(erc20TransferMethodID)], erc20TransferMethodID) {
+		return common.Address{}, nil, false
+	}
+
+	args := data[len(erc20TransferMethodID):]
+	to := common.BytesToAddress(args[:common.HashLength])
+	amount := new(big.Int).SetBytes(args[common.HashLength:])
+	return to, amount, true
+}
+
+// erc20BalanceOfData returns the calldata of an ERC-20
+// balanceOf(address) call.
+func erc20BalanceOfData(owner common.Address) []byte {
+	data := make([]byte, 0, len(erc20BalanceOfMethodID)+common.HashLength)
+	data = append(data, erc20BalanceOfMethodID...)
+	data = append(data, common.LeftPadBytes(owner.Bytes(), common.HashLength)...)
+	return data
+}
+
+// parseERC20TransferLog returns the sender, recipient, and
+// amount of an ERC-20 Transfer event. ERC-721 Transfer events
+// share the same topic but index the token ID, so they are
+// skipped by requiring exactly 3 topics.
+func parseERC20TransferLog(log *types.Log) (common.Address, common.Address, *big.Int, bool) {
+	if len(log.Topics) != 3 || log.Topics[0] != erc20TransferEventTopic { // nolint:gomnd
+		return common.Address{}, common.Address{}, nil, false
+	}
+
+	if len(log.Data) != erc20AmountLength {
+		return common.Address{}, common.Address{}, nil, false
+	}
+
+	from := common.BytesToAddress(log.Topics[1].Bytes())
+	to := common.BytesToAddress(log.Topics[2].Bytes())
+	amount := new(big.Int).SetBytes(log.Data)
+	return from, to, amount, true
+}
@@ -17,29 +17,77 @@ package ethereum
 import (
 	"fmt"
 	"io/ioutil"
-
-	"github.com/ethereum/go-ethereum/eth/tracers"
 )
 
 // convert raw eth data from client to rosetta
 
 const (
-	tracerPath = "ethereum/call_tracer.js"
+	// nativeCallTracer is the name of geth's
+	// built-in call tracer.
+	nativeCallTracer = "callTracer"
 )
 
 var (
 	tracerTimeout = "120s"
 )
 
-func loadTraceConfig() (*tracers.TraceConfig, error) {
-	loadedFile, err := ioutil.ReadFile(tracerPath)
+// Tracer is the tracer used to trace blocks
+// and transactions with the DebugTraceAPI.
+type Tracer string
+
+const (
+	// NativeTracer is geth's native callTracer.
+	NativeTracer Tracer = "NATIVE"
+
+	// JSTracer is a JavaScript call tracer loaded
+	// from a file, for nodes that do not support
+	// the native callTracer.
+	JSTracer Tracer = "JS"
+)
+
+// TraceConfig is the config provided to debug_traceBlockByHash
+// and debug_traceTransaction. Both geth's native callTracer
+// and the JavaScript call tracer return traces that can be
+// decoded into a *Call.
+type TraceConfig struct {
+	Tracer       string            `json:"tracer"`
+	Timeout      string            `json:"timeout"`
+	TracerConfig *CallTracerConfig `json:"tracerConfig,omitempty"`
+}
+
+// CallTracerConfig configures geth's native callTracer.
+type CallTracerConfig struct {
+	// OnlyTopCall skips tracing the internal calls of each
+	// transaction. Balance changes of internal calls are
+	// not populated when it is set.
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+
+	// WithLog includes the logs emitted by each call.
+	WithLog bool `json:"withLog,omitempty"`
+}
+
+// NativeTraceConfig returns the *TraceConfig of geth's
+// native callTracer with the provided *CallTracerConfig.
+func NativeTraceConfig(config *CallTracerConfig) *TraceConfig {
+	return &TraceConfig{
+		Tracer:       nativeCallTracer,
+		Timeout:      tracerTimeout,
+		TracerConfig: config,
+	}
+}
+
+// LoadJSTraceConfig returns the *TraceConfig of the
+// JavaScript tracer in the file at path. The tracer must
+// return traces in the format of geth's native callTracer
+// so that they can be decoded into a *Call.
+func LoadJSTraceConfig(path string) (*TraceConfig, error) {
+	loadedFile, err := ioutil.ReadFile(path) // #nosec G304
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not load tracer file", err)
 	}
 
-	loadedTracer := string(loadedFile)
-	return &tracers.TraceConfig{
-		Timeout: &tracerTimeout,
-		Tracer:  &loadedTracer,
+	return &TraceConfig{
+		Tracer:  string(loadedFile),
+		Timeout: tracerTimeout,
 	}, nil
 }
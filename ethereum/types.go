@@ -17,6 +17,7 @@ package ethereum
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -66,6 +67,10 @@ const (
 	// an uncle block reward.
 	UncleRewardOpType = "UNCLE_REWARD"
 
+	// WithdrawalOpType is used to describe a withdrawal
+	// from the beacon chain.
+	WithdrawalOpType = "WITHDRAWAL"
+
 	// FeeOpType is used to represent fee operations.
 	FeeOpType = "FEE"
 
@@ -95,6 +100,10 @@ const (
 	// of a transaction.
 	DestructOpType = "DESTRUCT"
 
+	// ERC20TransferOpType is used to represent ERC-20 token
+	// transfers, as emitted by Transfer events.
+	ERC20TransferOpType = "ERC20_TRANSFER"
+
 	// SuccessStatus is the status of any
 	// Ethereum operation considered successful.
 	SuccessStatus = "SUCCESS"
@@ -171,6 +180,14 @@ var (
 		Index: GenesisBlockIndex,
 	}
 
+	// MainnetTerminalTotalDifficulty is the total difficulty at
+	// which Mainnet transitioned to proof-of-stake (the Merge).
+	MainnetTerminalTotalDifficulty, _ = new(big.Int).SetString("58750000000000000000000", 10) // nolint:gomnd
+
+	// GoerliTerminalTotalDifficulty is the total difficulty at
+	// which Goerli transitioned to proof-of-stake.
+	GoerliTerminalTotalDifficulty = big.NewInt(10790000) // nolint:gomnd
+
 	// Currency is the *types.Currency for all
 	// Ethereum networks.
 	Currency = &types.Currency{
@@ -182,6 +199,7 @@ var (
 	OperationTypes = []string{
 		MinerRewardOpType,
 		UncleRewardOpType,
+		WithdrawalOpType,
 		FeeOpType,
 		CallOpType,
 		CreateOpType,
@@ -191,6 +209,7 @@ var (
 		DelegateCallOpType,
 		StaticCallOpType,
 		DestructOpType,
+		ERC20TransferOpType,
 	}
 
 	// OperationStatuses are all supported operation statuses.
@@ -226,6 +245,20 @@ type GraphQL interface {
 	Query(ctx context.Context, input string) (string, error)
 }
 
+// BalanceAPI is the API used to fetch account balances.
+type BalanceAPI string
+
+const (
+	// GraphQLBalanceAPI fetches balances with a single
+	// query to geth's GraphQL endpoint.
+	GraphQLBalanceAPI BalanceAPI = "GRAPHQL"
+
+	// RPCBalanceAPI fetches balances with a batch of
+	// JSON-RPC calls made at the hash of the block
+	// (EIP-1898), for nodes that do not expose GraphQL.
+	RPCBalanceAPI BalanceAPI = "RPC"
+)
+
 // CallType returns a boolean indicating
 // if the provided trace type is a call type.
 func CallType(t string) bool {
@@ -0,0 +1,391 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// DefaultEntryPoint is the address of the ERC-4337 EntryPoint
+	// (v0.6), which is deployed at the same address on all networks.
+	DefaultEntryPoint = "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"
+
+	// userOperationHashFields is the number of 32-byte words
+	// hashed into the userOpHash of a user operation.
+	userOperationHashFields = 10
+)
+
+var (
+	// accountFactoryGetAddressMethodID is the method ID of the
+	// account factory getAddress(address,uint256) method, which
+	// returns the counterfactual address of a smart account.
+	accountFactoryGetAddressMethodID = crypto.Keccak256(
+		[]byte("getAddress(address,uint256)"),
+	)[:methodIDLength]
+
+	// accountFactoryCreateAccountMethodID is the method ID of the
+	// account factory createAccount(address,uint256) method.
+	accountFactoryCreateAccountMethodID = crypto.Keccak256(
+		[]byte("createAccount(address,uint256)"),
+	)[:methodIDLength]
+
+	// entryPointGetNonceMethodID is the method ID of the
+	// EntryPoint getNonce(address,uint192) method.
+	entryPointGetNonceMethodID = crypto.Keccak256(
+		[]byte("getNonce(address,uint192)"),
+	)[:methodIDLength]
+
+	// smartAccountInitializeMethodID is the method ID of the
+	// smart account initialize(address) method, which the proxy
+	// of a smart account calls when it is deployed.
+	smartAccountInitializeMethodID = crypto.Keccak256(
+		[]byte("initialize(address)"),
+	)[:methodIDLength]
+
+	// smartAccountExecuteMethodID is the method ID of the smart
+	// account execute(address,uint256,bytes) method.
+	smartAccountExecuteMethodID = crypto.Keccak256(
+		[]byte("execute(address,uint256,bytes)"),
+	)[:methodIDLength]
+
+	// smartAccountOwnerMethodID is the method ID of the
+	// smart account owner() method.
+	smartAccountOwnerMethodID = crypto.Keccak256(
+		[]byte("owner()"),
+	)[:methodIDLength]
+
+	// smartAccountSalt is the salt all smart accounts are created
+	// with, so that each owner has a single smart account.
+	smartAccountSalt = big.NewInt(0)
+
+	// userOperationNonceKey is the key of the EntryPoint
+	// nonce sequence used by all user operations.
+	userOperationNonceKey = big.NewInt(0)
+)
+
+// UserOperation is an ERC-4337 (EntryPoint v0.6) user operation,
+// in the format accepted by eth_sendUserOperation.
+type UserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// UserOperationGas is the gas a bundler
+// estimates for a user operation.
+type UserOperationGas struct {
+	PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+}
+
+// Hash returns the userOpHash the smart account owner signs. It
+// commits to all fields but the signature, to the EntryPoint, and
+// to the chain ID, so that a signed user operation cannot be
+// replayed with another EntryPoint or on another chain.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	packed := make([]byte, 0, userOperationHashFields*common.HashLength)
+	packed = append(packed, common.LeftPadBytes(op.Sender.Bytes(), common.HashLength)...)
+	packed = append(packed, uint256Word(op.Nonce.ToInt())...)
+	packed = append(packed, crypto.Keccak256(op.InitCode)...)
+	packed = append(packed, crypto.Keccak256(op.CallData)...)
+	packed = append(packed, uint256Word(op.CallGasLimit.ToInt())...)
+	packed = append(packed, uint256Word(op.VerificationGasLimit.ToInt())...)
+	packed = append(packed, uint256Word(op.PreVerificationGas.ToInt())...)
+	packed = append(packed, uint256Word(op.MaxFeePerGas.ToInt())...)
+	packed = append(packed, uint256Word(op.MaxPriorityFeePerGas.ToInt())...)
+	packed = append(packed, crypto.Keccak256(op.PaymasterAndData)...)
+
+	return crypto.Keccak256Hash(
+		crypto.Keccak256(packed),
+		common.LeftPadBytes(entryPoint.Bytes(), common.HashLength),
+		uint256Word(chainID),
+	)
+}
+
+// uint256Word returns the ABI encoding of a uint256.
+// A nil value is encoded as 0.
+func uint256Word(value *big.Int) []byte {
+	if value == nil {
+		return make([]byte, common.HashLength)
+	}
+
+	return common.LeftPadBytes(value.Bytes(), common.HashLength)
+}
+
+// AccountFactoryInitCode returns the init code of a user operation
+// that deploys the smart account of owner with the account factory
+// at factory: the factory address followed by the calldata of a
+// createAccount(address,uint256) call.
+func AccountFactoryInitCode(factory common.Address, owner common.Address) []byte {
+	initCode := make([]byte, 0, common.AddressLength+methodIDLength+2*common.HashLength)
+	initCode = append(initCode, factory.Bytes()...)
+	initCode = append(initCode, accountFactoryCreateAccountMethodID...)
+	initCode = append(initCode, common.LeftPadBytes(owner.Bytes(), common.HashLength)...)
+	initCode = append(initCode, uint256Word(smartAccountSalt)...)
+	return initCode
+}
+
+// CounterfactualSmartAccountAddress returns the address of the
+// smart account of owner created by the account factory at factory,
+// computed without calling the factory. Like the getAddress method
+// of the factory, it is the CREATE2 address of a proxy deployed with
+// proxyCode (i.e. ERC1967Proxy) that points to implementation and
+// calls initialize(owner).
+func CounterfactualSmartAccountAddress(
+	factory common.Address,
+	implementation common.Address,
+	proxyCode []byte,
+	owner common.Address,
+) common.Address {
+	initializeData := make([]byte, 0, methodIDLength+common.HashLength)
+	initializeData = append(initializeData, smartAccountInitializeMethodID...)
+	initializeData = append(initializeData, common.LeftPadBytes(owner.Bytes(), common.HashLength)...)
+	encodedData := abiBytes(initializeData)
+
+	// The proxy constructor takes the implementation and the
+	// dynamic initialize calldata, encoded after the head of 2
+	// words, as arguments appended to its creation code.
+	initCode := make([]byte, 0, len(proxyCode)+2*common.HashLength+len(encodedData))
+	initCode = append(initCode, proxyCode...)
+	initCode = append(initCode, common.LeftPadBytes(implementation.Bytes(), common.HashLength)...)
+	initCode = append(initCode, uint256Word(big.NewInt(2*common.HashLength))...) // nolint:gomnd
+	initCode = append(initCode, encodedData...)
+
+	return crypto.CreateAddress2(
+		factory,
+		common.BytesToHash(uint256Word(smartAccountSalt)),
+		crypto.Keccak256(initCode),
+	)
+}
+
+// SmartAccountExecuteData returns the calldata of a smart account
+// execute(address,uint256,bytes) call, which calls to with value
+// and data from the smart account.
+func SmartAccountExecuteData(to common.Address, value *big.Int, data []byte) []byte {
+	encodedData := abiBytes(data)
+
+	// The bytes argument is dynamic, so it is encoded after the
+	// head of 3 words, starting with its offset.
+	executeData := make([]byte, 0, methodIDLength+3*common.HashLength+len(encodedData))
+	executeData = append(executeData, smartAccountExecuteMethodID...)
+	executeData = append(executeData, common.LeftPadBytes(to.Bytes(), common.HashLength)...)
+	executeData = append(executeData, uint256Word(value)...)
+	executeData = append(executeData, uint256Word(big.NewInt(3*common.HashLength))...) // nolint:gomnd
+	executeData = append(executeData, encodedData...)
+	return executeData
+}
+
+// abiBytes returns the ABI encoding of a bytes value
+// (without its offset): its length followed by the
+// value padded to a multiple of 32 bytes.
+func abiBytes(value []byte) []byte {
+	paddedLength := (len(value) + common.HashLength - 1) / common.HashLength * common.HashLength
+
+	encoded := make([]byte, 0, common.HashLength+paddedLength)
+	encoded = append(encoded, uint256Word(big.NewInt(int64(len(value))))...)
+	encoded = append(encoded, common.RightPadBytes(value, paddedLength)...)
+	return encoded
+}
+
+// ParseSmartAccountExecuteData returns the recipient, value, and
+// calldata of a smart account execute(address,uint256,bytes) call.
+// If the calldata is not an execute call, it returns !ok.
+func ParseSmartAccountExecuteData(data []byte) (common.Address, *big.Int, []byte, bool) {
+	headLength := methodIDLength + 4*common.HashLength // nolint:gomnd
+	if len(data) < headLength || !bytes.Equal(data[:methodIDLength], smartAccountExecuteMethodID) {
+		return common.Address{}, nil, nil, false
+	}
+
+	args := data[methodIDLength:]
+	word := func(i int) []byte {
+		return args[i*common.HashLength : (i+1)*common.HashLength]
+	}
+
+	offset := new(big.Int).SetBytes(word(2))
+	if offset.Cmp(big.NewInt(3*common.HashLength)) != 0 { // nolint:gomnd
+		return common.Address{}, nil, nil, false
+	}
+
+	length := new(big.Int).SetBytes(word(3))
+	if !length.IsUint64() || length.Uint64() > uint64(len(data)-headLength) {
+		return common.Address{}, nil, nil, false
+	}
+
+	to := common.BytesToAddress(word(0))
+	value := new(big.Int).SetBytes(word(1))
+	callData := common.CopyBytes(data[headLength : headLength+int(length.Uint64())])
+	return to, value, callData, true
+}
+
+// SmartAccountAddress returns the counterfactual address of the
+// smart account of owner created by the account factory at
+// factory. The address is returned whether or not the smart
+// account is deployed yet.
+func (ec *Client) SmartAccountAddress(
+	ctx context.Context,
+	factory common.Address,
+	owner common.Address,
+) (common.Address, error) {
+	data := make([]byte, 0, methodIDLength+2*common.HashLength)
+	data = append(data, accountFactoryGetAddressMethodID...)
+	data = append(data, common.LeftPadBytes(owner.Bytes(), common.HashLength)...)
+	data = append(data, uint256Word(smartAccountSalt)...)
+
+	var result hexutil.Bytes
+	if err := ec.c.CallContext(ctx, &result, "eth_call", map[string]interface{}{
+		"to":   factory,
+		"data": hexutil.Bytes(data),
+	}, "latest"); err != nil {
+		return common.Address{}, err
+	}
+
+	if len(result) != common.HashLength {
+		return common.Address{}, fmt.Errorf(
+			"%s returned %d bytes for getAddress",
+			factory.Hex(),
+			len(result),
+		)
+	}
+
+	return common.BytesToAddress(result), nil
+}
+
+// SmartAccountOwner returns the owner of a deployed smart
+// account, whose signature the smart account verifies.
+func (ec *Client) SmartAccountOwner(
+	ctx context.Context,
+	account common.Address,
+) (common.Address, error) {
+	var result hexutil.Bytes
+	if err := ec.c.CallContext(ctx, &result, "eth_call", map[string]interface{}{
+		"to":   account,
+		"data": hexutil.Bytes(smartAccountOwnerMethodID),
+	}, "latest"); err != nil {
+		return common.Address{}, err
+	}
+
+	if len(result) != common.HashLength {
+		return common.Address{}, fmt.Errorf(
+			"%s returned %d bytes for owner",
+			account.Hex(),
+			len(result),
+		)
+	}
+
+	return common.BytesToAddress(result), nil
+}
+
+// UserOperationNonce returns the nonce of the next user
+// operation of sender, as tracked by the EntryPoint.
+func (ec *Client) UserOperationNonce(
+	ctx context.Context,
+	entryPoint common.Address,
+	sender common.Address,
+) (*big.Int, error) {
+	data := make([]byte, 0, methodIDLength+2*common.HashLength)
+	data = append(data, entryPointGetNonceMethodID...)
+	data = append(data, common.LeftPadBytes(sender.Bytes(), common.HashLength)...)
+	data = append(data, uint256Word(userOperationNonceKey)...)
+
+	var result hexutil.Bytes
+	if err := ec.c.CallContext(ctx, &result, "eth_call", map[string]interface{}{
+		"to":   entryPoint,
+		"data": hexutil.Bytes(data),
+	}, "latest"); err != nil {
+		return nil, err
+	}
+
+	if len(result) != common.HashLength {
+		return nil, fmt.Errorf(
+			"%s returned %d bytes for getNonce",
+			entryPoint.Hex(),
+			len(result),
+		)
+	}
+
+	return new(big.Int).SetBytes(result), nil
+}
+
+// EstimateUserOperationGas returns the gas the
+// bundler estimates for the user operation.
+func (ec *Client) EstimateUserOperationGas(
+	ctx context.Context,
+	op *UserOperation,
+	entryPoint common.Address,
+) (*UserOperationGas, error) {
+	if ec.bundler == nil {
+		return nil, ErrBundlerNotConfigured
+	}
+
+	var gas UserOperationGas
+	if err := ec.bundler.CallContext(
+		ctx,
+		&gas,
+		"eth_estimateUserOperationGas",
+		op,
+		entryPoint,
+	); err != nil {
+		return nil, err
+	}
+
+	if gas.PreVerificationGas == nil || gas.VerificationGasLimit == nil || gas.CallGasLimit == nil {
+		return nil, errors.New("bundler returned an incomplete gas estimate")
+	}
+
+	return &gas, nil
+}
+
+// SendUserOperation submits the signed user operation to the
+// bundler and returns its userOpHash.
+func (ec *Client) SendUserOperation(
+	ctx context.Context,
+	op *UserOperation,
+	entryPoint common.Address,
+) (common.Hash, error) {
+	if ec.bundler == nil {
+		return common.Hash{}, ErrBundlerNotConfigured
+	}
+
+	var hash common.Hash
+	if err := ec.bundler.CallContext(
+		ctx,
+		&hash,
+		"eth_sendUserOperation",
+		op,
+		entryPoint,
+	); err != nil {
+		return common.Hash{}, err
+	}
+
+	return hash, nil
+}
@@ -10,8 +10,12 @@ import (
 
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 
+	ethereum "github.com/ethereum/go-ethereum"
+
 	mock "github.com/stretchr/testify/mock"
 
+	rosettaethereum "github.com/coinbase/rosetta-ethereum/ethereum"
+
 	types "github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -20,21 +24,21 @@ type Client struct {
 	mock.Mock
 }
 
-// Balance provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.PartialBlockIdentifier) (*types.AccountBalanceResponse, error) {
+// AccountCoins provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Client) AccountCoins(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 []*types.Currency) (*types.AccountCoinsResponse, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
-	var r0 *types.AccountBalanceResponse
-	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier) *types.AccountBalanceResponse); ok {
+	var r0 *types.AccountCoinsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, []*types.Currency) *types.AccountCoinsResponse); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.AccountBalanceResponse)
+			r0 = ret.Get(0).(*types.AccountCoinsResponse)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, []*types.Currency) error); ok {
 		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
@@ -43,16 +47,39 @@ func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2
 	return r0, r1
 }
 
+// Balance provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.PartialBlockIdentifier, _a3 []*types.Currency) (*types.AccountBalanceResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *types.AccountBalanceResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier, []*types.Currency) *types.AccountBalanceResponse); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.AccountBalanceResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier, []*types.Currency) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Block provides a mock function with given fields: _a0, _a1
-func (_m *Client) Block(_a0 context.Context, _a1 *types.PartialBlockIdentifier) (*types.Block, error) {
+func (_m *Client) Block(_a0 context.Context, _a1 *types.PartialBlockIdentifier) (*types.BlockResponse, error) {
 	ret := _m.Called(_a0, _a1)
 
-	var r0 *types.Block
-	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) *types.Block); ok {
+	var r0 *types.BlockResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) *types.BlockResponse); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*types.Block)
+			r0 = ret.Get(0).(*types.BlockResponse)
 		}
 	}
 
@@ -89,6 +116,119 @@ func (_m *Client) Call(ctx context.Context, request *types.CallRequest) (*types.
 	return r0, r1
 }
 
+// CodeAt provides a mock function with given fields: ctx, account, blockNumber
+func (_m *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	ret := _m.Called(ctx, account, blockNumber)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, *big.Int) []byte); ok {
+		r0 = rf(ctx, account, blockNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, *big.Int) error); ok {
+		r1 = rf(ctx, account, blockNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAccessList provides a mock function with given fields: ctx, msg
+func (_m *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (coretypes.AccessList, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 coretypes.AccessList
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) coretypes.AccessList); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(coretypes.AccessList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, ethereum.CallMsg) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EstimateGas provides a mock function with given fields: ctx, msg
+func (_m *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) uint64); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, ethereum.CallMsg) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EstimateUserOperationGas provides a mock function with given fields: ctx, op, entryPoint
+func (_m *Client) EstimateUserOperationGas(ctx context.Context, op *rosettaethereum.UserOperation, entryPoint common.Address) (*rosettaethereum.UserOperationGas, error) {
+	ret := _m.Called(ctx, op, entryPoint)
+
+	var r0 *rosettaethereum.UserOperationGas
+	if rf, ok := ret.Get(0).(func(context.Context, *rosettaethereum.UserOperation, common.Address) *rosettaethereum.UserOperationGas); ok {
+		r0 = rf(ctx, op, entryPoint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rosettaethereum.UserOperationGas)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *rosettaethereum.UserOperation, common.Address) error); ok {
+		r1 = rf(ctx, op, entryPoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FeeHistory provides a mock function with given fields: ctx, blockCount, lastBlock, rewardPercentiles
+func (_m *Client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*rosettaethereum.FeeHistory, error) {
+	ret := _m.Called(ctx, blockCount, lastBlock, rewardPercentiles)
+
+	var r0 *rosettaethereum.FeeHistory
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, *big.Int, []float64) *rosettaethereum.FeeHistory); ok {
+		r0 = rf(ctx, blockCount, lastBlock, rewardPercentiles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rosettaethereum.FeeHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, *big.Int, []float64) error); ok {
+		r1 = rf(ctx, blockCount, lastBlock, rewardPercentiles)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMempool provides a mock function with given fields: ctx
 func (_m *Client) GetMempool(ctx context.Context) (*types.MempoolResponse, error) {
 	ret := _m.Called(ctx)
@@ -112,6 +252,52 @@ func (_m *Client) GetMempool(ctx context.Context) (*types.MempoolResponse, error
 	return r0, r1
 }
 
+// GetMempoolTransaction provides a mock function with given fields: ctx, hash
+func (_m *Client) GetMempoolTransaction(ctx context.Context, hash string) (*types.Transaction, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Transaction); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HeaderByNumber provides a mock function with given fields: ctx, number
+func (_m *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*coretypes.Header, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 *coretypes.Header
+	if rf, ok := ret.Get(0).(func(context.Context, *big.Int) *coretypes.Header); ok {
+		r0 = rf(ctx, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.Header)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *big.Int) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PendingNonceAt provides a mock function with given fields: _a0, _a1
 func (_m *Client) PendingNonceAt(_a0 context.Context, _a1 common.Address) (uint64, error) {
 	ret := _m.Called(_a0, _a1)
@@ -133,6 +319,29 @@ func (_m *Client) PendingNonceAt(_a0 context.Context, _a1 common.Address) (uint6
 	return r0, r1
 }
 
+// SafeState provides a mock function with given fields: ctx, safe
+func (_m *Client) SafeState(ctx context.Context, safe common.Address) (*rosettaethereum.SafeState, error) {
+	ret := _m.Called(ctx, safe)
+
+	var r0 *rosettaethereum.SafeState
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address) *rosettaethereum.SafeState); ok {
+		r0 = rf(ctx, safe)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rosettaethereum.SafeState)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address) error); ok {
+		r1 = rf(ctx, safe)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SendTransaction provides a mock function with given fields: ctx, tx
 func (_m *Client) SendTransaction(ctx context.Context, tx *coretypes.Transaction) error {
 	ret := _m.Called(ctx, tx)
@@ -147,6 +356,69 @@ func (_m *Client) SendTransaction(ctx context.Context, tx *coretypes.Transaction
 	return r0
 }
 
+// SendUserOperation provides a mock function with given fields: ctx, op, entryPoint
+func (_m *Client) SendUserOperation(ctx context.Context, op *rosettaethereum.UserOperation, entryPoint common.Address) (common.Hash, error) {
+	ret := _m.Called(ctx, op, entryPoint)
+
+	var r0 common.Hash
+	if rf, ok := ret.Get(0).(func(context.Context, *rosettaethereum.UserOperation, common.Address) common.Hash); ok {
+		r0 = rf(ctx, op, entryPoint)
+	} else {
+		r0 = ret.Get(0).(common.Hash)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *rosettaethereum.UserOperation, common.Address) error); ok {
+		r1 = rf(ctx, op, entryPoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SmartAccountAddress provides a mock function with given fields: ctx, factory, owner
+func (_m *Client) SmartAccountAddress(ctx context.Context, factory common.Address, owner common.Address) (common.Address, error) {
+	ret := _m.Called(ctx, factory, owner)
+
+	var r0 common.Address
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, common.Address) common.Address); ok {
+		r0 = rf(ctx, factory, owner)
+	} else {
+		r0 = ret.Get(0).(common.Address)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, common.Address) error); ok {
+		r1 = rf(ctx, factory, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SmartAccountOwner provides a mock function with given fields: ctx, account
+func (_m *Client) SmartAccountOwner(ctx context.Context, account common.Address) (common.Address, error) {
+	ret := _m.Called(ctx, account)
+
+	var r0 common.Address
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address) common.Address); ok {
+		r0 = rf(ctx, account)
+	} else {
+		r0 = ret.Get(0).(common.Address)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address) error); ok {
+		r1 = rf(ctx, account)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Status provides a mock function with given fields: _a0
 func (_m *Client) Status(_a0 context.Context) (*types.BlockIdentifier, int64, *types.SyncStatus, []*types.Peer, error) {
 	ret := _m.Called(_a0)
@@ -218,6 +490,29 @@ func (_m *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	return r0, r1
 }
 
+// SuggestGasTipCap provides a mock function with given fields: ctx
+func (_m *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *big.Int
+	if rf, ok := ret.Get(0).(func(context.Context) *big.Int); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Transaction provides a mock function with given fields: _a0, _a1, _a2
 func (_m *Client) Transaction(_a0 context.Context, _a1 *types.BlockIdentifier, _a2 *types.TransactionIdentifier) (*types.Transaction, error) {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -240,3 +535,26 @@ func (_m *Client) Transaction(_a0 context.Context, _a1 *types.BlockIdentifier, _
 
 	return r0, r1
 }
+
+// UserOperationNonce provides a mock function with given fields: ctx, entryPoint, sender
+func (_m *Client) UserOperationNonce(ctx context.Context, entryPoint common.Address, sender common.Address) (*big.Int, error) {
+	ret := _m.Called(ctx, entryPoint, sender)
+
+	var r0 *big.Int
+	if rf, ok := ret.Get(0).(func(context.Context, common.Address, common.Address) *big.Int); ok {
+		r0 = rf(ctx, entryPoint, sender)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Address, common.Address) error); ok {
+		r1 = rf(ctx, entryPoint, sender)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
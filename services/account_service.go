@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/ethereum"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
@@ -52,7 +54,14 @@ func (s *AccountAPIService) AccountBalance(
 		ctx,
 		request.AccountIdentifier,
 		request.BlockIdentifier,
+		request.Currencies,
 	)
+	if errors.Is(err, ethereum.ErrCurrencyNotSupported) {
+		return nil, wrapErr(ErrCurrencyNotSupported, err)
+	}
+	if errors.Is(err, ethereum.ErrInvalidSubAccount) {
+		return nil, wrapErr(ErrInvalidInput, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrGeth, err)
 	}
@@ -60,10 +69,32 @@ func (s *AccountAPIService) AccountBalance(
 	return balanceResponse, nil
 }
 
-// AccountCoins implements /account/coins.
+// AccountCoins implements /account/coins. As Ethereum is not
+// UTXO-based, no coins are returned (so include_mempool is not
+// supported). Instead, the metadata contains the confirmed and
+// pending state of the account (see ethereum.AccountState).
 func (s *AccountAPIService) AccountCoins(
 	ctx context.Context,
 	request *types.AccountCoinsRequest,
 ) (*types.AccountCoinsResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	coinsResponse, err := s.client.AccountCoins(
+		ctx,
+		request.AccountIdentifier,
+		request.Currencies,
+	)
+	if errors.Is(err, ethereum.ErrCurrencyNotSupported) {
+		return nil, wrapErr(ErrCurrencyNotSupported, err)
+	}
+	if errors.Is(err, ethereum.ErrInvalidSubAccount) {
+		return nil, wrapErr(ErrInvalidInput, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return coinsResponse, nil
 }
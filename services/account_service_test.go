@@ -38,10 +38,9 @@ func TestAccountBalance_Offline(t *testing.T) {
 	assert.Nil(t, bal)
 	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
 
-	coins, err := servicer.AccountCoins(ctx, nil)
+	coins, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{})
 	assert.Nil(t, coins)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
 
 	mockClient.AssertExpectations(t)
 }
@@ -79,6 +78,7 @@ func TestAccountBalance_Online(t *testing.T) {
 		ctx,
 		account,
 		types.ConstructPartialBlockIdentifier(block),
+		([]*types.Currency)(nil),
 	).Return(resp, nil).Once()
 
 	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
@@ -88,10 +88,119 @@ func TestAccountBalance_Online(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, resp, bal)
 
-	coins, err := servicer.AccountCoins(ctx, nil)
-	assert.Nil(t, coins)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAccountCoins_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	resp := &types.AccountCoinsResponse{
+		BlockIdentifier: &types.BlockIdentifier{
+			Index: 1000,
+			Hash:  "block 1000",
+		},
+		Coins: []*types.Coin{},
+		Metadata: map[string]interface{}{
+			"nonce":                int64(3),
+			"pending_nonce":        int64(5),
+			"pending_transactions": []string{"tx 3", "tx 4"},
+			"queued_transactions":  []string{"tx 6"},
+		},
+	}
+
+	mockClient.On(
+		"AccountCoins",
+		ctx,
+		account,
+		([]*types.Currency)(nil),
+	).Return(resp, nil).Once()
+
+	coins, err := servicer.AccountCoins(ctx, &types.AccountCoinsRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, resp, coins)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAccountBalance_UnsupportedCurrency(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	currencies := []*types.Currency{
+		{
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	}
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		(*types.PartialBlockIdentifier)(nil),
+		currencies,
+	).Return(nil, ethereum.ErrCurrencyNotSupported).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+		Currencies:        currencies,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrCurrencyNotSupported.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAccountBalance_InvalidSubAccount(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+		SubAccount: &types.SubAccountIdentifier{
+			Address: "world",
+		},
+	}
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		(*types.PartialBlockIdentifier)(nil),
+		([]*types.Currency)(nil),
+	).Return(nil, ethereum.ErrInvalidSubAccount).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
 
 	mockClient.AssertExpectations(t)
 }
@@ -50,7 +50,7 @@ func (s *BlockAPIService) Block(
 		return nil, ErrUnavailableOffline
 	}
 
-	block, err := s.client.Block(ctx, request.BlockIdentifier)
+	response, err := s.client.Block(ctx, request.BlockIdentifier)
 	if errors.Is(err, ethereum.ErrBlockOrphaned) {
 		return nil, wrapErr(ErrBlockOrphaned, err)
 	}
@@ -58,9 +58,7 @@ func (s *BlockAPIService) Block(
 		return nil, wrapErr(ErrGeth, err)
 	}
 
-	return &types.BlockResponse{
-		Block: block,
-	}, nil
+	return response, nil
 }
 
 // BlockTransaction implements the /block/transaction endpoint.
@@ -73,7 +73,7 @@ func TestBlockService_Online(t *testing.T) {
 			ctx,
 			(*types.PartialBlockIdentifier)(nil),
 		).Return(
-			block,
+			blockResponse,
 			nil,
 		).Once()
 		b, err := servicer.Block(ctx, &types.BlockRequest{})
@@ -83,7 +83,7 @@ func TestBlockService_Online(t *testing.T) {
 
 	t.Run("populated identifier", func(t *testing.T) {
 		pbIdentifier := types.ConstructPartialBlockIdentifier(block.BlockIdentifier)
-		mockClient.On("Block", ctx, pbIdentifier).Return(block, nil).Once()
+		mockClient.On("Block", ctx, pbIdentifier).Return(blockResponse, nil).Once()
 		b, err := servicer.Block(ctx, &types.BlockRequest{
 			BlockIdentifier: pbIdentifier,
 		})
@@ -17,21 +17,48 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
-	"strconv"
+	"sort"
 
 	"github.com/coinbase/rosetta-ethereum/configuration"
 	"github.com/coinbase/rosetta-ethereum/ethereum"
 
+	goEthereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
+const (
+	// baseFeeMultiplier is the multiplier applied to the latest base fee
+	// when computing the max fee per gas of an EIP-1559 transaction.
+	baseFeeMultiplier = 2
+
+	// txPoolPriceBump is the minimum percentage geth requires the
+	// fees of a replacement transaction to increase by (the
+	// default of --txpool.pricebump).
+	txPoolPriceBump = 10
+
+	// feeHistoryBlocks is the number of recent blocks whose
+	// rewards are used to compute the fees of a fee strategy.
+	feeHistoryBlocks = 20
+)
+
+// feeStrategies are the reward percentiles
+// of the supported fee strategies.
+var feeStrategies = map[string]float64{
+	"slow":     10,
+	"standard": 50,
+	"fast":     90,
+}
+
 // ConstructionAPIService implements the server.ConstructionAPIServicer interface.
 type ConstructionAPIService struct {
 	config *configuration.Configuration
@@ -60,6 +87,13 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	}
 
 	addr := crypto.PubkeyToAddress(*pubkey)
+
+	// With account abstraction, the key is the
+	// owner of a smart account instead.
+	if s.config.AccountAbstraction != nil {
+		return s.deriveSmartAccount(ctx, addr)
+	}
+
 	return &types.ConstructionDeriveResponse{
 		AccountIdentifier: &types.AccountIdentifier{
 			Address: addr.Hex(),
@@ -73,42 +107,116 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	ctx context.Context,
 	request *types.ConstructionPreprocessRequest,
 ) (*types.ConstructionPreprocessResponse, *types.Error) {
-	descriptions := &parser.Descriptions{
-		OperationDescriptions: []*parser.OperationDescription{
-			{
-				Type: ethereum.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.NegativeAmountSign,
-					Currency: ethereum.Currency,
-				},
-			},
-			{
-				Type: ethereum.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.PositiveAmountSign,
-					Currency: ethereum.Currency,
-				},
-			},
-		},
-		ErrUnmatched: true,
+	if s.config.AccountAbstraction != nil {
+		return s.preprocessUserOperation(request)
+	}
+
+	if isSafeMode(request.Metadata) {
+		var safeInput safeModeMetadata
+		if err := unmarshalJSONMap(request.Metadata, &safeInput); err != nil {
+			return nil, wrapErr(ErrInvalidInput, err)
+		}
+
+		return s.preprocessSafeTransaction(request.Operations, &safeInput.safeMetadata)
 	}
 
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
+	deployment, rErr := intentDeployment(request.Operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	// Deployments execute the init code, so we include
+	// it to estimate gas in /construction/metadata.
+	var preprocessOutput *options
+	if deployment != nil {
+		preprocessOutput = &options{
+			From: deployment.from,
+			Data: hexutil.Encode(deployment.initCode),
+		}
+		if deployment.value.Sign() > 0 {
+			preprocessOutput.Value = hexutil.EncodeBig(deployment.value)
+		}
+	} else {
+		preprocessOutput, rErr = s.transferOptions(request.Operations)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	// The nonce, fees, and gas limit can be provided in the
+	// metadata to skip fetching them from geth. In offline
+	// mode, all of them must be provided.
+	var input preprocessMetadata
+	if err := unmarshalJSONMap(request.Metadata, &input); err != nil {
+		return nil, wrapErr(ErrInvalidInput, err)
+	}
+
+	if !input.empty() {
+		if err := input.validate(); err != nil {
+			return nil, wrapErr(ErrInvalidInput, err)
+		}
+
+		// Access lists are only created for contract calls, as
+		// a transfer to an account does not access any storage.
+		if input.CreateAccessList {
+			if len(preprocessOutput.ContractAddress) == 0 {
+				return nil, wrapErr(
+					ErrInvalidInput,
+					errors.New("create_access_list is only supported for contract calls"),
+				)
+			}
+
+			if len(preprocessOutput.AccessList) > 0 {
+				return nil, wrapErr(
+					ErrInvalidInput,
+					errors.New("create_access_list cannot be populated with an access_list"),
+				)
+			}
+		}
+
+		// A replaced transaction is fetched from the mempool
+		// in /construction/metadata, so its nonce and fees are
+		// never provided.
+		if s.config.Mode != configuration.Online && len(input.ReplaceTransactionHash) == 0 {
+			if err := input.complete(preprocessOutput.executesCode()); err != nil {
+				return nil, wrapErr(ErrInvalidInput, err)
+			}
+		}
+
+		preprocessOutput.preprocessMetadata = input
+	}
+
+	marshaled, err := marshalJSONMap(preprocessOutput)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: marshaled,
+	}, nil
+}
+
+// transferOptions returns the *options of a transfer
+// of ETH or tokens, which may call a contract.
+func (s *ConstructionAPIService) transferOptions(
+	operations []*types.Operation,
+) (*options, *types.Error) {
+	currency, opType, rErr := s.intentCurrency(operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	matches, err := parser.MatchOperations(
+		transferDescriptions(opType, currency),
+		operations,
+	)
 	if err != nil {
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
 
 	fromOp, _ := matches[0].First()
 	fromAdd := fromOp.Account.Address
-	toOp, _ := matches[1].First()
+	toOp, amount := matches[1].First()
 	toAdd := toOp.Account.Address
 
 	// Ensure valid from address
@@ -118,32 +226,61 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	}
 
 	// Ensure valid to address
-	_, ok = ethereum.ChecksumAddress(toAdd)
+	checkTo, ok := ethereum.ChecksumAddress(toAdd)
 	if !ok {
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
 	}
 
+	call, callData, rErr := intentContractCall(fromOp, toOp, opType, amount)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	accessList, rErr := intentAccessList(toOp)
+	if rErr != nil {
+		return nil, rErr
+	}
+
 	preprocessOutput := &options{
-		From: checkFrom,
+		From:       checkFrom,
+		AccessList: accessList,
 	}
 
-	marshaled, err := marshalJSONMap(preprocessOutput)
-	if err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	// Token transfers and contract calls execute contract
+	// code, so we include the call to estimate gas in
+	// /construction/metadata.
+	if contract, ok := s.config.Tokens.Contract(currency); ok {
+		preprocessOutput.ContractAddress = contract.Hex()
+		preprocessOutput.Data = hexutil.Encode(
+			ethereum.ERC20TransferData(common.HexToAddress(checkTo), amount),
+		)
 	}
 
-	return &types.ConstructionPreprocessResponse{
-		Options: marshaled,
-	}, nil
+	if call != nil {
+		preprocessOutput.ContractAddress = checkTo
+		preprocessOutput.Data = hexutil.Encode(callData)
+		if amount.Sign() > 0 {
+			preprocessOutput.Value = hexutil.EncodeBig(amount)
+		}
+	}
+
+	return preprocessOutput, nil
 }
 
 // ConstructionMetadata implements the /construction/metadata endpoint.
+// Metadata provided to /construction/preprocess is returned instead
+// of being fetched from geth, so if all required metadata was
+// provided, this endpoint is also available in offline mode.
 func (s *ConstructionAPIService) ConstructionMetadata(
 	ctx context.Context,
 	request *types.ConstructionMetadataRequest,
 ) (*types.ConstructionMetadataResponse, *types.Error) {
-	if s.config.Mode != configuration.Online {
-		return nil, ErrUnavailableOffline
+	if s.config.AccountAbstraction != nil {
+		return s.userOperationMetadata(ctx, request)
+	}
+
+	if isSafeMode(request.Options) {
+		return s.safeTransactionMetadata(ctx, request)
 	}
 
 	var input options
@@ -151,18 +288,104 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	nonce, err := s.client.PendingNonceAt(ctx, common.HexToAddress(input.From))
-	if err != nil {
-		return nil, wrapErr(ErrGeth, err)
+	executesCode := input.executesCode()
+	if s.config.Mode != configuration.Online && input.complete(executesCode) != nil {
+		return nil, ErrUnavailableOffline
 	}
-	gasPrice, err := s.client.SuggestGasPrice(ctx)
-	if err != nil {
-		return nil, wrapErr(ErrGeth, err)
+
+	if err := input.validate(); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	metadata := &metadata{
-		Nonce:    nonce,
-		GasPrice: gasPrice,
+	var replaced *replacedTransaction
+	if len(input.ReplaceTransactionHash) > 0 {
+		var rErr *types.Error
+		replaced, rErr = s.replacedTransaction(ctx, input.From, input.ReplaceTransactionHash)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	// Errors decoding populated fields are
+	// returned by validate, so they are ignored.
+	metadata := &metadata{}
+	switch {
+	case replaced != nil:
+		metadata.Nonce = replaced.nonce
+	case len(input.Nonce) > 0:
+		metadata.Nonce, _ = hexutil.DecodeUint64(input.Nonce)
+	default:
+		nonce, err := s.client.PendingNonceAt(ctx, common.HexToAddress(input.From))
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+
+		metadata.Nonce = nonce
+	}
+
+	var msg goEthereum.CallMsg
+	if executesCode {
+		data, err := hexutil.Decode(input.Data)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		value, err := decodeOptionalBig(input.Value)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		msg = goEthereum.CallMsg{
+			From:       common.HexToAddress(input.From),
+			Value:      value,
+			Data:       data,
+			AccessList: input.AccessList,
+		}
+
+		// Deployments are estimated without a recipient.
+		if len(input.ContractAddress) > 0 {
+			contract := common.HexToAddress(input.ContractAddress)
+			msg.To = &contract
+		}
+	}
+
+	if input.CreateAccessList {
+		accessList, err := s.client.CreateAccessList(ctx, msg)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+
+		metadata.AccessList = accessList
+		msg.AccessList = accessList
+	}
+
+	gasLimit := uint64(ethereum.TransferGasLimit)
+	switch {
+	case len(input.GasLimit) > 0:
+		gasLimit, _ = hexutil.DecodeUint64(input.GasLimit)
+		metadata.GasLimit = gasLimit
+	case executesCode:
+		var err error
+		gasLimit, err = s.client.EstimateGas(ctx, msg)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+
+		metadata.GasLimit = gasLimit
+	case len(input.AccessList) > 0:
+		// The intrinsic gas of a transfer increases
+		// with the size of its access list.
+		gasLimit += accessListGas(input.AccessList)
+		metadata.GasLimit = gasLimit
+	}
+
+	gasPrice, rErr := s.gasPrice(ctx, &input.preprocessMetadata, metadata)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if replaced != nil {
+		gasPrice = bumpFees(metadata, replaced)
 	}
 
 	metadataMap, err := marshalJSONMap(metadata)
@@ -171,124 +394,446 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 	}
 
 	// Find suggested gas usage
-	suggestedFee := metadata.GasPrice.Int64() * ethereum.TransferGasLimit
+	suggestedFee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
 
 	return &types.ConstructionMetadataResponse{
 		Metadata: metadataMap,
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    strconv.FormatInt(suggestedFee, 10),
+				Value:    suggestedFee.String(),
 				Currency: ethereum.Currency,
 			},
 		},
 	}, nil
 }
 
+// gasPrice populates the fees of the metadata, using the
+// provided fees if populated, and returns the gas price used
+// to compute the suggested fee.
+func (s *ConstructionAPIService) gasPrice(
+	ctx context.Context,
+	input *preprocessMetadata,
+	metadata *metadata,
+) (*big.Int, *types.Error) {
+	if len(input.GasPrice) > 0 || len(input.GasFeeCap) > 0 {
+		metadata.GasPrice, metadata.GasTipCap, metadata.GasFeeCap, _ = input.fees()
+		if metadata.GasPrice != nil {
+			return metadata.GasPrice, nil
+		}
+
+		// Without the base fee, the fee paid
+		// can be up to the max fee per gas.
+		return metadata.GasFeeCap, nil
+	}
+
+	header, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	// With a fee strategy, the tip is computed from the
+	// rewards of recent blocks instead of being suggested
+	// by geth.
+	var historyTip, nextBaseFee *big.Int
+	if percentile, ok := input.feePercentile(); ok {
+		var rErr *types.Error
+		historyTip, nextBaseFee, rErr = s.feeHistoryFees(ctx, percentile)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	// If the latest block has a base fee, EIP-1559 is active
+	// and we construct a dynamic fee transaction. Otherwise, we
+	// fall back to a legacy transaction.
+	if header.BaseFee != nil {
+		baseFee := header.BaseFee
+		if nextBaseFee != nil && nextBaseFee.Sign() > 0 {
+			baseFee = nextBaseFee
+		}
+
+		gasTipCap := historyTip
+		if gasTipCap == nil {
+			gasTipCap, err = s.client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return nil, wrapErr(ErrGeth, err)
+			}
+		}
+
+		metadata.BaseFee = baseFee
+		metadata.GasTipCap = gasTipCap
+		metadata.GasFeeCap = calculateGasFeeCap(baseFee, gasTipCap)
+
+		// The fee paid is the base fee of the block the transaction
+		// is included in plus the tip.
+		return new(big.Int).Add(baseFee, gasTipCap), nil
+	}
+
+	// Before EIP-1559, the rewards are the gas prices paid.
+	gasPrice := historyTip
+	if gasPrice == nil {
+		gasPrice, err = s.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+	}
+
+	metadata.GasPrice = gasPrice
+	return gasPrice, nil
+}
+
+// feeHistoryFees returns the median of the rewards paid at the
+// percentile in the recent blocks returned by eth_feeHistory and
+// the base fee of the next block. Empty blocks have no rewards,
+// so they are skipped. If all recent blocks are empty, no reward
+// is returned and the fees suggested by geth are used instead.
+func (s *ConstructionAPIService) feeHistoryFees(
+	ctx context.Context,
+	percentile float64,
+) (*big.Int, *big.Int, *types.Error) {
+	history, err := s.client.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, nil, wrapErr(ErrGeth, err)
+	}
+
+	rewards := []*big.Int{}
+	for i, blockRewards := range history.Reward {
+		if len(blockRewards) == 0 || blockRewards[0] == nil {
+			continue
+		}
+
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+
+		rewards = append(rewards, blockRewards[0])
+	}
+
+	var nextBaseFee *big.Int
+	if len(history.BaseFee) > 0 {
+		nextBaseFee = history.BaseFee[len(history.BaseFee)-1]
+	}
+
+	if len(rewards) == 0 {
+		return nil, nextBaseFee, nil
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+
+	return rewards[len(rewards)/2], nextBaseFee, nil
+}
+
+// replacedTransaction is a pending transaction that is
+// replaced by a transaction with the same nonce. The gas
+// price of a legacy transaction is both its tip and fee cap.
+type replacedTransaction struct {
+	nonce     uint64
+	gasTipCap *big.Int
+	gasFeeCap *big.Int
+}
+
+// replacedTransaction fetches the pending transaction with
+// the provided hash from the mempool and ensures it was
+// sent by from.
+func (s *ConstructionAPIService) replacedTransaction(
+	ctx context.Context,
+	from string,
+	hash string,
+) (*replacedTransaction, *types.Error) {
+	tx, err := s.client.GetMempoolTransaction(ctx, hash)
+	if errors.Is(err, goEthereum.NotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	var fields struct {
+		From      string `json:"from"`
+		Nonce     string `json:"nonce"`
+		GasPrice  string `json:"gas_price"`
+		GasTipCap string `json:"max_priority_fee_per_gas"`
+		GasFeeCap string `json:"max_fee_per_gas"`
+	}
+	if err := unmarshalJSONMap(tx.Metadata, &fields); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	if len(fields.From) == 0 || common.HexToAddress(fields.From) != common.HexToAddress(from) {
+		return nil, wrapErr(
+			ErrInvalidInput,
+			fmt.Errorf("transaction %s was not sent by %s", hash, from),
+		)
+	}
+
+	nonce, err := hexutil.DecodeUint64(fields.Nonce)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	gasPrice, err := hexutil.DecodeBig(fields.GasPrice)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	replaced := &replacedTransaction{
+		nonce:     nonce,
+		gasTipCap: gasPrice,
+		gasFeeCap: gasPrice,
+	}
+	if len(fields.GasFeeCap) > 0 {
+		replaced.gasTipCap, err = hexutil.DecodeBig(fields.GasTipCap)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		replaced.gasFeeCap, err = hexutil.DecodeBig(fields.GasFeeCap)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+	}
+
+	return replaced, nil
+}
+
+// replacementFee returns the minimum fee geth accepts to
+// replace a transaction paying fee. geth requires the tip
+// and fee cap of the replacement to be at least
+// txPoolPriceBump percent higher.
+func replacementFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+txPoolPriceBump)) // nolint:gomnd
+	bumped.Div(bumped, big.NewInt(100))                              // nolint:gomnd
+
+	// The replacement must also pay strictly more.
+	minimum := new(big.Int).Add(fee, big.NewInt(1))
+	if bumped.Cmp(minimum) < 0 {
+		return minimum
+	}
+
+	return bumped
+}
+
+// maxBig returns the largest of the provided values.
+func maxBig(values ...*big.Int) *big.Int {
+	max := values[0]
+	for _, value := range values[1:] {
+		if value.Cmp(max) > 0 {
+			max = value
+		}
+	}
+
+	return max
+}
+
+// bumpFees raises the suggested fees of the metadata to the
+// minimum fees geth accepts to replace the replaced transaction
+// and returns the gas price used to compute the suggested fee.
+func bumpFees(metadata *metadata, replaced *replacedTransaction) *big.Int {
+	// A legacy replacement pays its gas price as both the
+	// tip and the fee cap, so it must exceed the fee cap
+	// of the replaced transaction.
+	if metadata.GasPrice != nil {
+		metadata.GasPrice = maxBig(metadata.GasPrice, replacementFee(replaced.gasFeeCap))
+		return metadata.GasPrice
+	}
+
+	metadata.GasTipCap = maxBig(metadata.GasTipCap, replacementFee(replaced.gasTipCap))
+	metadata.GasFeeCap = maxBig(
+		metadata.GasFeeCap,
+		replacementFee(replaced.gasFeeCap),
+		metadata.GasTipCap,
+	)
+
+	return new(big.Int).Add(metadata.BaseFee, metadata.GasTipCap)
+}
+
 // ConstructionPayloads implements the /construction/payloads endpoint.
 func (s *ConstructionAPIService) ConstructionPayloads(
 	ctx context.Context,
 	request *types.ConstructionPayloadsRequest,
 ) (*types.ConstructionPayloadsResponse, *types.Error) {
-	descriptions := &parser.Descriptions{
-		OperationDescriptions: []*parser.OperationDescription{
-			{
-				Type: ethereum.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.NegativeAmountSign,
-					Currency: ethereum.Currency,
-				},
-			},
-			{
-				Type: ethereum.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.PositiveAmountSign,
-					Currency: ethereum.Currency,
-				},
-			},
-		},
-		ErrUnmatched: true,
+	if s.config.AccountAbstraction != nil {
+		return s.userOperationPayloads(request)
+	}
+
+	if isSafeMode(request.Metadata) {
+		return s.safeTransactionPayloads(request)
+	}
+
+	// Convert map to Metadata struct
+	var metadata metadata
+	if err := unmarshalJSONMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	deployment, rErr := intentDeployment(request.Operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	var unsignedTx *transaction
+	if deployment != nil {
+		if metadata.GasLimit == 0 {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				errors.New("gas_limit must be populated for contract deployments"),
+			)
+		}
+
+		unsignedTx = &transaction{
+			From:      deployment.from,
+			Value:     deployment.value,
+			Data:      deployment.initCode,
+			Nonce:     metadata.Nonce,
+			GasPrice:  metadata.GasPrice,
+			GasTipCap: metadata.GasTipCap,
+			GasFeeCap: metadata.GasFeeCap,
+			GasLimit:  metadata.GasLimit,
+			ChainID:   s.config.Params.ChainID,
+		}
+	} else {
+		unsignedTx, rErr = s.transferTransaction(request.Operations, &metadata)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+	tx := ethTransaction(unsignedTx)
+
+	// Construct SigningPayload
+	signer := ethTypes.NewLondonSigner(unsignedTx.ChainID)
+	payload := &types.SigningPayload{
+		AccountIdentifier: &types.AccountIdentifier{Address: unsignedTx.From},
+		Bytes:             signer.Hash(tx).Bytes(),
+		SignatureType:     types.EcdsaRecovery,
+	}
+
+	unsignedTxJSON, err := json.Marshal(unsignedTx)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: string(unsignedTxJSON),
+		Payloads:            []*types.SigningPayload{payload},
+	}, nil
+}
+
+// transferTransaction returns the unsigned *transaction of a
+// transfer of ETH or tokens, which may call a contract.
+func (s *ConstructionAPIService) transferTransaction(
+	operations []*types.Operation,
+	metadata *metadata,
+) (*transaction, *types.Error) {
+	unsignedTx, executesCode, rErr := s.transferCall(operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if executesCode && metadata.GasLimit == 0 {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			errors.New("gas_limit must be populated for contract calls"),
+		)
 	}
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
-	if err != nil {
-		return nil, wrapErr(ErrUnclearIntent, err)
+
+	// An access list created by geth is returned
+	// in the metadata instead of the operations.
+	if len(metadata.AccessList) > 0 {
+		unsignedTx.AccessList = metadata.AccessList
 	}
 
-	// Convert map to Metadata struct
-	var metadata metadata
-	if err := unmarshalJSONMap(request.Metadata, &metadata); err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	unsignedTx.Nonce = metadata.Nonce
+	unsignedTx.GasPrice = metadata.GasPrice
+	unsignedTx.GasTipCap = metadata.GasTipCap
+	unsignedTx.GasFeeCap = metadata.GasFeeCap
+	unsignedTx.GasLimit = uint64(ethereum.TransferGasLimit)
+	unsignedTx.ChainID = s.config.Params.ChainID
+	if metadata.GasLimit > 0 {
+		unsignedTx.GasLimit = metadata.GasLimit
 	}
 
-	// Required Fields for constructing a real Ethereum transaction
-	toOp, amount := matches[1].First()
-	toAdd := toOp.Account.Address
-	nonce := metadata.Nonce
-	gasPrice := metadata.GasPrice
-	chainID := s.config.Params.ChainID
-	transferGasLimit := uint64(ethereum.TransferGasLimit)
-	transferData := []byte{}
+	return unsignedTx, nil
+}
+
+// transferCall returns the sender, recipient, value, calldata,
+// and access list of a transfer of ETH or tokens as an unsigned
+// *transaction without a nonce, fees, or gas limit. It also
+// returns a boolean indicating if the transfer executes contract
+// code (i.e. a token transfer or contract call).
+func (s *ConstructionAPIService) transferCall(
+	operations []*types.Operation,
+) (*transaction, bool, *types.Error) {
+	currency, opType, rErr := s.intentCurrency(operations)
+	if rErr != nil {
+		return nil, false, rErr
+	}
+
+	matches, err := parser.MatchOperations(
+		transferDescriptions(opType, currency),
+		operations,
+	)
+	if err != nil {
+		return nil, false, wrapErr(ErrUnclearIntent, err)
+	}
 
-	// Additional Fields for constructing custom Ethereum tx struct
 	fromOp, _ := matches[0].First()
 	fromAdd := fromOp.Account.Address
+	toOp, amount := matches[1].First()
+	toAdd := toOp.Account.Address
 
 	// Ensure valid from address
 	checkFrom, ok := ethereum.ChecksumAddress(fromAdd)
 	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromAdd))
+		return nil, false, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromAdd))
 	}
 
 	// Ensure valid to address
 	checkTo, ok := ethereum.ChecksumAddress(toAdd)
 	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
+		return nil, false, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
 	}
 
-	tx := ethTypes.NewTransaction(
-		nonce,
-		common.HexToAddress(checkTo),
-		amount,
-		transferGasLimit,
-		gasPrice,
-		transferData,
-	)
+	call, callData, rErr := intentContractCall(fromOp, toOp, opType, amount)
+	if rErr != nil {
+		return nil, false, rErr
+	}
 
-	unsignedTx := &transaction{
-		From:     checkFrom,
-		To:       checkTo,
-		Value:    amount,
-		Data:     tx.Data(),
-		Nonce:    tx.Nonce(),
-		GasPrice: gasPrice,
-		GasLimit: tx.Gas(),
-		ChainID:  chainID,
+	accessList, rErr := intentAccessList(toOp)
+	if rErr != nil {
+		return nil, false, rErr
 	}
 
-	// Construct SigningPayload
-	signer := ethTypes.NewEIP155Signer(chainID)
-	payload := &types.SigningPayload{
-		AccountIdentifier: &types.AccountIdentifier{Address: checkFrom},
-		Bytes:             signer.Hash(tx).Bytes(),
-		SignatureType:     types.EcdsaRecovery,
+	// Token transfers send no ETH and instead call transfer
+	// on the token contract.
+	txTo := checkTo
+	txValue := amount
+	transferData := []byte{}
+	contract, isToken := s.config.Tokens.Contract(currency)
+	if isToken {
+		txTo = contract.Hex()
+		txValue = big.NewInt(0)
+		transferData = ethereum.ERC20TransferData(common.HexToAddress(checkTo), amount)
 	}
 
-	unsignedTxJSON, err := json.Marshal(unsignedTx)
-	if err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	if call != nil {
+		transferData = callData
 	}
 
-	return &types.ConstructionPayloadsResponse{
-		UnsignedTransaction: string(unsignedTxJSON),
-		Payloads:            []*types.SigningPayload{payload},
-	}, nil
+	unsignedTx := &transaction{
+		From:       checkFrom,
+		To:         txTo,
+		Value:      txValue,
+		Data:       transferData,
+		AccessList: accessList,
+	}
+	if call != nil {
+		unsignedTx.MethodSignature = call.MethodSignature
+		unsignedTx.MethodArgs = call.MethodArgs
+	}
+
+	return unsignedTx, isToken || call != nil, nil
 }
 
 // ConstructionCombine implements the /construction/combine
@@ -297,22 +842,23 @@ func (s *ConstructionAPIService) ConstructionCombine(
 	ctx context.Context,
 	request *types.ConstructionCombineRequest,
 ) (*types.ConstructionCombineResponse, *types.Error) {
+	if s.config.AccountAbstraction != nil {
+		return s.combineUserOperation(request)
+	}
+
+	if isSafeTransaction(request.UnsignedTransaction) {
+		return s.combineSafeTransaction(request)
+	}
+
 	var unsignedTx transaction
 	if err := json.Unmarshal([]byte(request.UnsignedTransaction), &unsignedTx); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	ethTransaction := ethTypes.NewTransaction(
-		unsignedTx.Nonce,
-		common.HexToAddress(unsignedTx.To),
-		unsignedTx.Value,
-		unsignedTx.GasLimit,
-		unsignedTx.GasPrice,
-		unsignedTx.Data,
-	)
+	ethTx := ethTransaction(&unsignedTx)
 
-	signer := ethTypes.NewEIP155Signer(unsignedTx.ChainID)
-	signedTx, err := ethTransaction.WithSignature(signer, request.Signatures[0].Bytes)
+	signer := ethTypes.NewLondonSigner(unsignedTx.ChainID)
+	signedTx, err := ethTx.WithSignature(signer, request.Signatures[0].Bytes)
 	if err != nil {
 		return nil, wrapErr(ErrSignatureInvalid, err)
 	}
@@ -332,6 +878,14 @@ func (s *ConstructionAPIService) ConstructionHash(
 	ctx context.Context,
 	request *types.ConstructionHashRequest,
 ) (*types.TransactionIdentifierResponse, *types.Error) {
+	if s.config.AccountAbstraction != nil {
+		return s.hashUserOperation(request)
+	}
+
+	if isSafeTransaction(request.SignedTransaction) {
+		return s.hashSafeTransaction(request)
+	}
+
 	signedTx := ethTypes.Transaction{}
 	if err := signedTx.UnmarshalJSON([]byte(request.SignedTransaction)); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -351,6 +905,14 @@ func (s *ConstructionAPIService) ConstructionParse(
 	ctx context.Context,
 	request *types.ConstructionParseRequest,
 ) (*types.ConstructionParseResponse, *types.Error) {
+	if s.config.AccountAbstraction != nil {
+		return s.parseUserOperation(request)
+	}
+
+	if isSafeTransaction(request.Transaction) {
+		return s.parseSafeTransaction(request)
+	}
+
 	var tx transaction
 	if !request.Signed {
 		err := json.Unmarshal([]byte(request.Transaction), &tx)
@@ -364,15 +926,23 @@ func (s *ConstructionAPIService) ConstructionParse(
 			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 		}
 
-		tx.To = t.To().String()
+		if t.To() != nil {
+			tx.To = t.To().String()
+		}
 		tx.Value = t.Value()
 		tx.Data = t.Data()
 		tx.Nonce = t.Nonce()
-		tx.GasPrice = t.GasPrice()
 		tx.GasLimit = t.Gas()
 		tx.ChainID = t.ChainId()
+		tx.AccessList = t.AccessList()
+		if t.Type() == ethTypes.DynamicFeeTxType {
+			tx.GasTipCap = t.GasTipCap()
+			tx.GasFeeCap = t.GasFeeCap()
+		} else {
+			tx.GasPrice = t.GasPrice()
+		}
 
-		msg, err := t.AsMessage(ethTypes.NewEIP155Signer(t.ChainId()), nil)
+		msg, err := t.AsMessage(ethTypes.NewLondonSigner(t.ChainId()), nil)
 		if err != nil {
 			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 		}
@@ -386,15 +956,125 @@ func (s *ConstructionAPIService) ConstructionParse(
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.From))
 	}
 
+	metadata := &parseMetadata{
+		Nonce:     tx.Nonce,
+		GasPrice:  tx.GasPrice,
+		GasTipCap: tx.GasTipCap,
+		GasFeeCap: tx.GasFeeCap,
+		ChainID:   tx.ChainID,
+	}
+
+	// The address of a deployed contract is derived
+	// from the sender and nonce of the transaction.
+	var ops []*types.Operation
+	var rErr *types.Error
+	if len(tx.To) == 0 {
+		ops, rErr = deploymentOperations(checkFrom, &tx)
+		metadata.ContractAddress = crypto.CreateAddress(
+			common.HexToAddress(checkFrom),
+			tx.Nonce,
+		).Hex()
+	} else {
+		ops, rErr = s.transferOperations(checkFrom, &tx)
+	}
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	metaMap, err := marshalJSONMap(metadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	var resp *types.ConstructionParseResponse
+	if request.Signed {
+		resp = &types.ConstructionParseResponse{
+			Operations: ops,
+			AccountIdentifierSigners: []*types.AccountIdentifier{
+				{
+					Address: checkFrom,
+				},
+			},
+			Metadata: metaMap,
+		}
+	} else {
+		resp = &types.ConstructionParseResponse{
+			Operations:               ops,
+			AccountIdentifierSigners: []*types.AccountIdentifier{},
+			Metadata:                 metaMap,
+		}
+	}
+	return resp, nil
+}
+
+// transferOperations returns the operations of a transfer
+// of ETH or tokens, which may call a contract.
+func (s *ConstructionAPIService) transferOperations(
+	checkFrom string,
+	tx *transaction,
+) ([]*types.Operation, *types.Error) {
 	// Ensure valid to address
 	checkTo, ok := ethereum.ChecksumAddress(tx.To)
 	if !ok {
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.To))
 	}
 
+	// Token transfers are represented by the recipient and amount
+	// in the transfer calldata.
+	currency := ethereum.Currency
+	opType := ethereum.CallOpType
+	value := tx.Value
+	if tokenCurrency, ok := s.config.Tokens.Currency(common.HexToAddress(checkTo)); ok {
+		recipient, amount, ok := ethereum.ParseERC20TransferData(tx.Data)
+		if ok && tx.Value.Sign() == 0 {
+			currency = tokenCurrency
+			opType = ethereum.ERC20TransferOpType
+			checkTo = recipient.Hex()
+			value = amount
+		}
+	}
+
+	// Contract calls are represented by the metadata of the
+	// operation crediting the contract. Signed transactions
+	// do not include the method signature, so we return the
+	// raw calldata instead.
+	var callMetadata map[string]interface{}
+	if opType == ethereum.CallOpType && len(tx.Data) > 0 {
+		call := &contractCall{Data: hexutil.Encode(tx.Data)}
+		if len(tx.MethodSignature) > 0 {
+			call = &contractCall{
+				MethodSignature: tx.MethodSignature,
+				MethodArgs:      tx.MethodArgs,
+			}
+		}
+
+		var err error
+		callMetadata, err = marshalJSONMap(call)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+	}
+
+	// Access lists are represented by the metadata
+	// of the operation crediting the recipient.
+	if len(tx.AccessList) > 0 {
+		if callMetadata == nil {
+			callMetadata = map[string]interface{}{}
+		}
+
+		accessListMap, err := marshalJSONMap(&accessListMetadata{AccessList: tx.AccessList})
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		for k, v := range accessListMap {
+			callMetadata[k] = v
+		}
+	}
+
 	ops := []*types.Operation{
 		{
-			Type: ethereum.CallOpType,
+			Type: opType,
 			OperationIdentifier: &types.OperationIdentifier{
 				Index: 0,
 			},
@@ -402,12 +1082,12 @@ func (s *ConstructionAPIService) ConstructionParse(
 				Address: checkFrom,
 			},
 			Amount: &types.Amount{
-				Value:    new(big.Int).Neg(tx.Value).String(),
-				Currency: ethereum.Currency,
+				Value:    new(big.Int).Neg(value).String(),
+				Currency: currency,
 			},
 		},
 		{
-			Type: ethereum.CallOpType,
+			Type: opType,
 			OperationIdentifier: &types.OperationIdentifier{
 				Index: 1,
 			},
@@ -420,41 +1100,43 @@ func (s *ConstructionAPIService) ConstructionParse(
 				Address: checkTo,
 			},
 			Amount: &types.Amount{
-				Value:    tx.Value.String(),
-				Currency: ethereum.Currency,
+				Value:    value.String(),
+				Currency: currency,
 			},
+			Metadata: callMetadata,
 		},
 	}
 
-	metadata := &parseMetadata{
-		Nonce:    tx.Nonce,
-		GasPrice: tx.GasPrice,
-		ChainID:  tx.ChainID,
-	}
-	metaMap, err := marshalJSONMap(metadata)
+	return ops, nil
+}
+
+// deploymentOperations returns the operation of a contract
+// deployment, which debits the sender the ETH sent to the
+// deployed contract.
+func deploymentOperations(checkFrom string, tx *transaction) ([]*types.Operation, *types.Error) {
+	creationMetadata, err := marshalJSONMap(&contractCreation{
+		InitCode: hexutil.Encode(tx.Data),
+	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	var resp *types.ConstructionParseResponse
-	if request.Signed {
-		resp = &types.ConstructionParseResponse{
-			Operations: ops,
-			AccountIdentifierSigners: []*types.AccountIdentifier{
-				{
-					Address: checkFrom,
-				},
+	return []*types.Operation{
+		{
+			Type: ethereum.CreateOpType,
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
 			},
-			Metadata: metaMap,
-		}
-	} else {
-		resp = &types.ConstructionParseResponse{
-			Operations:               ops,
-			AccountIdentifierSigners: []*types.AccountIdentifier{},
-			Metadata:                 metaMap,
-		}
-	}
-	return resp, nil
+			Account: &types.AccountIdentifier{
+				Address: checkFrom,
+			},
+			Amount: &types.Amount{
+				Value:    new(big.Int).Neg(tx.Value).String(),
+				Currency: ethereum.Currency,
+			},
+			Metadata: creationMetadata,
+		},
+	}, nil
 }
 
 // ConstructionSubmit implements the /construction/submit endpoint.
@@ -466,6 +1148,17 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		return nil, ErrUnavailableOffline
 	}
 
+	if s.config.AccountAbstraction != nil {
+		return s.submitUserOperation(ctx, request)
+	}
+
+	// A signed Safe transaction is executed by a separate
+	// transaction calling the Safe, which any account can
+	// send and pay for.
+	if isSafeTransaction(request.SignedTransaction) {
+		return nil, safeTransactionSubmitError(request.SignedTransaction)
+	}
+
 	var signedTx ethTypes.Transaction
 	if err := signedTx.UnmarshalJSON([]byte(request.SignedTransaction)); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -482,3 +1175,268 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		TransactionIdentifier: txIdentifier,
 	}, nil
 }
+
+// intentCurrency returns the *types.Currency transferred by the
+// provided operations and the operation type used to transfer it.
+func (s *ConstructionAPIService) intentCurrency(
+	operations []*types.Operation,
+) (*types.Currency, string, *types.Error) {
+	for _, op := range operations {
+		if op.Amount == nil || op.Amount.Currency == nil {
+			continue
+		}
+
+		if types.Hash(op.Amount.Currency) == types.Hash(ethereum.Currency) {
+			return ethereum.Currency, ethereum.CallOpType, nil
+		}
+
+		contract, ok := s.config.Tokens.Contract(op.Amount.Currency)
+		if !ok {
+			return nil, "", wrapErr(
+				ErrCurrencyNotSupported,
+				fmt.Errorf("%s is not supported", types.PrintStruct(op.Amount.Currency)),
+			)
+		}
+
+		currency, _ := s.config.Tokens.Currency(contract)
+		return currency, ethereum.ERC20TransferOpType, nil
+	}
+
+	return nil, "", wrapErr(ErrUnclearIntent, errors.New("no currency found in operations"))
+}
+
+// intentContractCall returns the *contractCall and calldata in the
+// metadata of the operation crediting a contract. If the operation
+// does not call a contract, it returns nil. A transfer of zero ETH
+// must call a contract.
+func intentContractCall(
+	fromOp *types.Operation,
+	toOp *types.Operation,
+	opType string,
+	amount *big.Int,
+) (*contractCall, []byte, *types.Error) {
+	var call contractCall
+	if err := unmarshalJSONMap(toOp.Metadata, &call); err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	if len(call.MethodSignature) == 0 && len(call.Data) == 0 {
+		// A transfer of zero ETH to the sender does nothing
+		// but use a nonce, which cancels a pending transaction
+		// with the same nonce.
+		isCancel := common.HexToAddress(fromOp.Account.Address) ==
+			common.HexToAddress(toOp.Account.Address)
+		if amount.Sign() == 0 && !isCancel {
+			return nil, nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("transfers of zero ETH must call a contract"),
+			)
+		}
+
+		return nil, nil, nil
+	}
+
+	if opType != ethereum.CallOpType {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("contract calls are not supported in %s operations", opType),
+		)
+	}
+
+	data, err := call.calldata()
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	return &call, data, nil
+}
+
+// intentDeployment returns the *deployment constructed by the
+// provided operations. If the operations do not deploy a
+// contract, it returns nil.
+func intentDeployment(operations []*types.Operation) (*deployment, *types.Error) {
+	isDeployment := false
+	for _, op := range operations {
+		if op.Type == ethereum.CreateOpType {
+			isDeployment = true
+		}
+	}
+
+	if !isDeployment {
+		return nil, nil
+	}
+
+	matches, err := parser.MatchOperations(deploymentDescriptions(), operations)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	fromOp, amount := matches[0].First()
+	checkFrom, ok := ethereum.ChecksumAddress(fromOp.Account.Address)
+	if !ok {
+		return nil, wrapErr(
+			ErrInvalidAddress,
+			fmt.Errorf("%s is not a valid address", fromOp.Account.Address),
+		)
+	}
+
+	var creation contractCreation
+	if err := unmarshalJSONMap(fromOp.Metadata, &creation); err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	initCode, err := hexutil.Decode(creation.InitCode)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, fmt.Errorf("%w: unable to decode init_code", err))
+	}
+
+	if len(initCode) == 0 {
+		return nil, wrapErr(ErrUnclearIntent, errors.New("init_code must be populated"))
+	}
+
+	return &deployment{
+		from:     checkFrom,
+		value:    new(big.Int).Neg(amount),
+		initCode: initCode,
+	}, nil
+}
+
+// intentAccessList returns the access list in the metadata
+// of the operation crediting the recipient, if any.
+func intentAccessList(toOp *types.Operation) (ethTypes.AccessList, *types.Error) {
+	var input accessListMetadata
+	if err := unmarshalJSONMap(toOp.Metadata, &input); err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	return input.AccessList, nil
+}
+
+// accessListGas returns the intrinsic gas EIP-2930 charges
+// for the addresses and storage slots of an access list.
+func accessListGas(accessList ethTypes.AccessList) uint64 {
+	return uint64(len(accessList))*params.TxAccessListAddressGas +
+		uint64(accessList.StorageKeys())*params.TxAccessListStorageKeyGas
+}
+
+// transferDescriptions returns the *parser.Descriptions of
+// a transfer of the provided currency.
+func transferDescriptions(opType string, currency *types.Currency) *parser.Descriptions {
+	// Contract calls are not required to transfer any ETH,
+	// so we allow zero amounts in CALL operations.
+	var fromSign, toSign parser.AmountSign = parser.NegativeAmountSign, parser.PositiveAmountSign
+	if opType == ethereum.CallOpType {
+		fromSign = parser.NegativeOrZeroAmountSign
+		toSign = parser.PositiveOrZeroAmountSign
+	}
+
+	return &parser.Descriptions{
+		OperationDescriptions: []*parser.OperationDescription{
+			{
+				Type: opType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     fromSign,
+					Currency: currency,
+				},
+			},
+			{
+				Type: opType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     toSign,
+					Currency: currency,
+				},
+			},
+		},
+		ErrUnmatched: true,
+	}
+}
+
+// deploymentDescriptions returns the *parser.Descriptions of a
+// contract deployment. The address of the deployed contract
+// depends on the nonce of the transaction, so there is no
+// operation crediting it.
+func deploymentDescriptions() *parser.Descriptions {
+	return &parser.Descriptions{
+		OperationDescriptions: []*parser.OperationDescription{
+			{
+				Type: ethereum.CreateOpType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.NegativeOrZeroAmountSign,
+					Currency: ethereum.Currency,
+				},
+			},
+		},
+		ErrUnmatched: true,
+	}
+}
+
+// calculateGasFeeCap returns the max fee per gas for an EIP-1559
+// transaction. Like geth, we allow for the base fee to double before
+// the transaction is no longer includable.
+func calculateGasFeeCap(baseFee *big.Int, gasTipCap *big.Int) *big.Int {
+	return new(big.Int).Add(
+		gasTipCap,
+		new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier)),
+	)
+}
+
+// ethTransaction converts a *transaction into an *ethTypes.Transaction.
+// A DynamicFeeTx is returned if the fee caps are populated, an
+// AccessListTx is returned if only an access list is populated, and
+// a legacy transaction is returned otherwise. A transaction
+// without a recipient deploys a contract.
+func ethTransaction(tx *transaction) *ethTypes.Transaction {
+	var to *common.Address
+	if len(tx.To) > 0 {
+		recipient := common.HexToAddress(tx.To)
+		to = &recipient
+	}
+
+	if tx.GasFeeCap != nil {
+		return ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+			ChainID:    tx.ChainID,
+			Nonce:      tx.Nonce,
+			GasTipCap:  tx.GasTipCap,
+			GasFeeCap:  tx.GasFeeCap,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
+		})
+	}
+
+	if len(tx.AccessList) > 0 {
+		return ethTypes.NewTx(&ethTypes.AccessListTx{
+			ChainID:    tx.ChainID,
+			Nonce:      tx.Nonce,
+			GasPrice:   tx.GasPrice,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
+		})
+	}
+
+	return ethTypes.NewTx(&ethTypes.LegacyTx{
+		Nonce:    tx.Nonce,
+		GasPrice: tx.GasPrice,
+		Gas:      tx.GasLimit,
+		To:       to,
+		Value:    tx.Value,
+		Data:     tx.Data,
+	})
+}
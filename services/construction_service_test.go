@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -26,7 +28,11 @@ import (
 	mocks "github.com/coinbase/rosetta-ethereum/mocks/services"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -110,6 +116,14 @@ func TestConstructionService(t *testing.T) {
 		Nonce:    0,
 	}
 
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{},
+		nil,
+	).Once()
 	mockClient.On(
 		"SuggestGasPrice",
 		ctx,
@@ -239,3 +253,2167 @@ func TestConstructionService(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestConstructionService_DynamicFee(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"}`
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(1500000000),
+		GasFeeCap: big.NewInt(25500000000),
+		BaseFee:   big.NewInt(12000000000),
+	}
+
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(12000000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1500000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+	).Return(
+		uint64(2),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "283500000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","to":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","value":"0x9864aac3510d02","data":"0x","nonce":"0x2","max_priority_fee_per_gas":"0x59682f00","max_fee_per_gas":"0x5efeb1f00","gas":"0x5208","chain_id":"0x5"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"ca8b0a8a8f5d8e47c68a57f978df6d01e18a87d31d948320c9f08c28dbb01e8a","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var parseOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parseOpsRaw), &parseOps))
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:     metadata.Nonce,
+		GasTipCap: metadata.GasTipCap,
+		GasFeeCap: metadata.GasFeeCap,
+		ChainID:   big.NewInt(5),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"85df1d1d91e0b3f0c28bda7ec41c7dcb8f2bca64864e731aa8766d22e87bded81a806038cc4e2c3e03d39b25c7b69b8ce546c78fad99e5fd797302935655aedd00","signing_payload":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"ca8b0a8a8f5d8e47c68a57f978df6d01e18a87d31d948320c9f08c28dbb01e8a","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"027ddb3b1645e6b8db46fa6125452ba28142cc50b61c214d0d368a8cc1466f4934","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"type":"0x2","nonce":"0x2","gasPrice":null,"maxPriorityFeePerGas":"0x59682f00","maxFeePerGas":"0x5efeb1f00","gas":"0x5208","value":"0x9864aac3510d02","input":"0x","v":"0x0","r":"0x85df1d1d91e0b3f0c28bda7ec41c7dcb8f2bca64864e731aa8766d22e87bded8","s":"0x1a806038cc4e2c3e03d39b25c7b69b8ce546c78fad99e5fd797302935655aedd","to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d","chainId":"0x5","accessList":[],"hash":"0xf708b22257440f2a02b063663a1a407512deb539a52364e5c2ac35e25b51547c"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0xf708b22257440f2a02b063663a1a407512deb539a52364e5c2ac35e25b51547c",
+		},
+	}, hashResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_FeeStrategy(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"fee_strategy": "fast"},
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","fee_strategy":"fast"}`
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(2000000000),
+		GasFeeCap: big.NewInt(28000000000),
+		BaseFee:   big.NewInt(13000000000),
+	}
+
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(12000000000)},
+		nil,
+	).Once()
+
+	// The reward of the empty block is skipped, and the
+	// base fee of the next block is used.
+	mockClient.On(
+		"FeeHistory",
+		ctx,
+		uint64(feeHistoryBlocks),
+		(*big.Int)(nil),
+		[]float64{90},
+	).Return(
+		&ethereum.FeeHistory{
+			OldestBlock: big.NewInt(100),
+			Reward: [][]*big.Int{
+				{big.NewInt(3000000000)},
+				{big.NewInt(0)},
+				{big.NewInt(1000000000)},
+				{big.NewInt(2000000000)},
+			},
+			BaseFee: []*big.Int{
+				big.NewInt(11000000000),
+				big.NewInt(12000000000),
+				big.NewInt(11000000000),
+				big.NewInt(12000000000),
+				big.NewInt(13000000000),
+			},
+			GasUsedRatio: []float64{0.9, 0, 0.4, 0.8},
+		},
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+	).Return(
+		uint64(2),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "315000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Before EIP-1559, the rewards are gas prices. The
+	// suggested fee does not fit in an int64.
+	percentile := float64(25)
+	options.Nonce = "0x2"
+	options.FeeStrategy = ""
+	options.FeePercentile = &percentile
+	metadata.GasPrice = big.NewInt(1000000000000000)
+	metadata.GasTipCap = nil
+	metadata.GasFeeCap = nil
+	metadata.BaseFee = nil
+
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{},
+		nil,
+	).Once()
+	mockClient.On(
+		"FeeHistory",
+		ctx,
+		uint64(feeHistoryBlocks),
+		(*big.Int)(nil),
+		[]float64{25},
+	).Return(
+		&ethereum.FeeHistory{
+			OldestBlock:  big.NewInt(100),
+			Reward:       [][]*big.Int{{big.NewInt(1000000000000000)}},
+			BaseFee:      []*big.Int{big.NewInt(0), big.NewInt(0)},
+			GasUsedRatio: []float64{0.5},
+		},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "21000000000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Invalid fee strategies are rejected
+	for _, input := range []map[string]interface{}{
+		{"fee_strategy": "urgent"},
+		{"fee_strategy": "fast", "fee_percentile": 50},
+		{"fee_percentile": 101},
+		{"fee_strategy": "slow", "gas_price": "0x3b9aca00"},
+	} {
+		preprocessResponse, err = servicer.ConstructionPreprocess(
+			ctx,
+			&types.ConstructionPreprocessRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        ops,
+				Metadata:          input,
+			},
+		)
+		assert.Nil(t, preprocessResponse)
+		assert.Equal(t, ErrInvalidInput.Code, err.Code)
+	}
+
+	// Fee strategies require geth
+	cfg.Mode = configuration.Offline
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"nonce":        "0x2",
+				"fee_strategy": "standard",
+			},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_Offline(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Offline,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+
+	// Incomplete metadata cannot be used offline
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"nonce": "0x2",
+			},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	// Invalid fees are rejected
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"nonce":           "0x2",
+				"max_fee_per_gas": "0x5efeb1f00",
+			},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"nonce":                    "0x2",
+				"max_priority_fee_per_gas": "0x59682f00",
+				"max_fee_per_gas":          "0x5efeb1f00",
+			},
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","nonce":"0x2","max_priority_fee_per_gas":"0x59682f00","max_fee_per_gas":"0x5efeb1f00"}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options: map[string]interface{}{
+			"from": "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+		},
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(1500000000),
+		GasFeeCap: big.NewInt(25500000000),
+	}
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "535500000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","to":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","value":"0x9864aac3510d02","data":"0x","nonce":"0x2","max_priority_fee_per_gas":"0x59682f00","max_fee_per_gas":"0x5efeb1f00","gas":"0x5208","chain_id":"0x5"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"ca8b0a8a8f5d8e47c68a57f978df6d01e18a87d31d948320c9f08c28dbb01e8a","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_Replace(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess of a cancellation
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	hash := "0xf708b22257440f2a02b063663a1a407512deb539a52364e5c2ac35e25b51547c"
+
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"nonce":                    "0x2",
+				"replace_transaction_hash": hash,
+			},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: map[string]interface{}{
+				"replace_transaction_hash": hash,
+			},
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","replace_transaction_hash":"0xf708b22257440f2a02b063663a1a407512deb539a52364e5c2ac35e25b51547c"}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	mockClient.On(
+		"GetMempoolTransaction",
+		ctx,
+		hash,
+	).Return(
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                ethereum.FeeOpType,
+					Account: &types.AccountIdentifier{
+						Address: "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+					},
+					Amount: &types.Amount{
+						Value:    "-535500000000000",
+						Currency: ethereum.Currency,
+					},
+				},
+			},
+			Metadata: map[string]interface{}{
+				"from":                     "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A",
+				"gas_limit":                "0x5208",
+				"gas_price":                "0x5efeb1f00",
+				"max_priority_fee_per_gas": "0x59682f00",
+				"max_fee_per_gas":          "0x5efeb1f00",
+				"nonce":                    "0x2",
+				"pool":                     "pending",
+			},
+		},
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(12000000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1500000000),
+		nil,
+	).Once()
+
+	// The tip and fee cap are bumped by 10%
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(1650000000),
+		GasFeeCap: big.NewInt(28050000000),
+		BaseFee:   big.NewInt(12000000000),
+	}
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "286650000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, err)
+	var unsignedTx transaction
+	assert.NoError(t, json.Unmarshal([]byte(payloadsResponse.UnsignedTransaction), &unsignedTx))
+	assert.Equal(t, "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A", unsignedTx.To)
+	assert.Equal(t, 0, unsignedTx.Value.Sign())
+	assert.Equal(t, uint64(2), unsignedTx.Nonce)
+	assert.Equal(t, metadata.GasTipCap, unsignedTx.GasTipCap)
+	assert.Equal(t, metadata.GasFeeCap, unsignedTx.GasFeeCap)
+
+	// Transactions of other accounts cannot be replaced
+	mockClient.On(
+		"GetMempoolTransaction",
+		ctx,
+		hash,
+	).Return(
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                ethereum.FeeOpType,
+					Account: &types.AccountIdentifier{
+						Address: "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d",
+					},
+					Amount: &types.Amount{
+						Value:    "-535500000000000",
+						Currency: ethereum.Currency,
+					},
+				},
+			},
+			Metadata: map[string]interface{}{
+				"from":      "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d",
+				"gas_limit": "0x5208",
+				"gas_price": "0x5efeb1f00",
+				"nonce":     "0x2",
+				"pool":      "pending",
+			},
+		},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	// Transactions without a sender cannot be replaced
+	mockClient.On(
+		"GetMempoolTransaction",
+		ctx,
+		hash,
+	).Return(
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                ethereum.FeeOpType,
+				},
+			},
+			Metadata: map[string]interface{}{
+				"gas_limit": "0x5208",
+				"gas_price": "0x5efeb1f00",
+				"nonce":     "0x2",
+				"pool":      "pending",
+			},
+		},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_Token(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	tokens, err := ethereum.NewTokenRegistry([]*ethereum.Token{
+		{
+			Address:  "0x07865c6E87B9F70255377e024ace6630C1Eaa37F",
+			Symbol:   "USDC",
+			Decimals: 6,
+		},
+	})
+	assert.NoError(t, err)
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+		Tokens:  tokens,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"ERC20_TRANSFER","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-1000000","currency":{"symbol":"USDC","decimals":6,"metadata":{"contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"}}}},{"operation_identifier":{"index":1},"type":"ERC20_TRANSFER","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"1000000","currency":{"symbol":"USDC","decimals":6,"metadata":{"contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"}}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F","data":"0xa9059cbb00000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000f4240"}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(1500000000),
+		GasFeeCap: big.NewInt(25500000000),
+		BaseFee:   big.NewInt(12000000000),
+		GasLimit:  65000,
+	}
+
+	contract := common.HexToAddress("0x07865c6E87B9F70255377e024ace6630C1Eaa37F")
+	mockClient.On(
+		"EstimateGas",
+		ctx,
+		goEthereum.CallMsg{
+			From: common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+			To:   &contract,
+			Data: common.FromHex("0xa9059cbb00000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000f4240"), // nolint
+		},
+	).Return(
+		uint64(65000),
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(12000000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1500000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+	).Return(
+		uint64(2),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "877500000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","to":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F","value":"0x0","data":"0xa9059cbb00000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000f4240","nonce":"0x2","max_priority_fee_per_gas":"0x59682f00","max_fee_per_gas":"0x5efeb1f00","gas":"0xfde8","chain_id":"0x5"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"4358e344dbaf649b4f4b13b1119c0ad32a8348da3aa285361fe268a1b64b5290","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"ERC20_TRANSFER","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"-1000000","currency":{"symbol":"USDC","decimals":6,"metadata":{"contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"}}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"ERC20_TRANSFER","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"1000000","currency":{"symbol":"USDC","decimals":6,"metadata":{"contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"}}}}]` // nolint
+	var parseOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parseOpsRaw), &parseOps))
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:     metadata.Nonce,
+		GasTipCap: metadata.GasTipCap,
+		GasFeeCap: metadata.GasFeeCap,
+		ChainID:   big.NewInt(5),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"3a50faac920177aa1abebd606ba696b7a85cd27dec8014e8a3c0f2808c77f3d12d4ac8365eb5afe1d585534be5327ce9641e370690bf87a822af270298b91e3f00","signing_payload":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"4358e344dbaf649b4f4b13b1119c0ad32a8348da3aa285361fe268a1b64b5290","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"027ddb3b1645e6b8db46fa6125452ba28142cc50b61c214d0d368a8cc1466f4934","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"type":"0x2","nonce":"0x2","gasPrice":null,"maxPriorityFeePerGas":"0x59682f00","maxFeePerGas":"0x5efeb1f00","gas":"0xfde8","value":"0x0","input":"0xa9059cbb00000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000f4240","v":"0x0","r":"0x3a50faac920177aa1abebd606ba696b7a85cd27dec8014e8a3c0f2808c77f3d1","s":"0x2d4ac8365eb5afe1d585534be5327ce9641e370690bf87a822af270298b91e3f","to":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","chainId":"0x5","accessList":[],"hash":"0xad7b1bb843e1bf41f0c7f03c89284d4ffe03ff025c84da40f268ad2a357bf388"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_ContractCall(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}},"metadata":{"method_signature":"approve(address,uint256)","method_args":["0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","1000"]}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F","data":"0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8"}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:     2,
+		GasTipCap: big.NewInt(1500000000),
+		GasFeeCap: big.NewInt(25500000000),
+		BaseFee:   big.NewInt(12000000000),
+		GasLimit:  46000,
+	}
+
+	contract := common.HexToAddress("0x07865c6E87B9F70255377e024ace6630C1Eaa37F")
+	mockClient.On(
+		"EstimateGas",
+		ctx,
+		goEthereum.CallMsg{
+			From: common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+			To:   &contract,
+			Data: common.FromHex("0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8"), // nolint
+		},
+	).Return(
+		uint64(46000),
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(12000000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1500000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+	).Return(
+		uint64(2),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "621000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","to":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F","value":"0x0","data":"0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8","nonce":"0x2","max_priority_fee_per_gas":"0x59682f00","max_fee_per_gas":"0x5efeb1f00","gas":"0xb3b0","chain_id":"0x5","method_signature":"approve(address,uint256)","method_args":["0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","1000"]}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"b14c257fbfaf6f8981c3d60968c319fd222d080f8bf60aa79fefa6459ca677ce","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}},"metadata":{"method_signature":"approve(address,uint256)","method_args":["0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","1000"]}}]` // nolint
+	var parseOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parseOpsRaw), &parseOps))
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:     metadata.Nonce,
+		GasTipCap: metadata.GasTipCap,
+		GasFeeCap: metadata.GasFeeCap,
+		ChainID:   big.NewInt(5),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"50240b2408227c1df3788e6f12012c58fbf7ba6e95559dce72b5d2de109326be3202b75b38dcf8fa833d54b2e344bccab3b2a7a6b505e45e702d188833c14bf000","signing_payload":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","hex_bytes":"b14c257fbfaf6f8981c3d60968c319fd222d080f8bf60aa79fefa6459ca677ce","account_identifier":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"027ddb3b1645e6b8db46fa6125452ba28142cc50b61c214d0d368a8cc1466f4934","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"type":"0x2","nonce":"0x2","gasPrice":null,"maxPriorityFeePerGas":"0x59682f00","maxFeePerGas":"0x5efeb1f00","gas":"0xb3b0","value":"0x0","input":"0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8","v":"0x0","r":"0x50240b2408227c1df3788e6f12012c58fbf7ba6e95559dce72b5d2de109326be","s":"0x3202b75b38dcf8fa833d54b2e344bccab3b2a7a6b505e45e702d188833c14bf0","to":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","chainId":"0x5","accessList":[],"hash":"0xdc8d44bb9b65186d36a5c9c5c97b5d342ea356187ef9b2399888c3a50ff71ca1"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Parse Signed
+	parseSignedOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}},"metadata":{"data":"0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8"}}]` // nolint
+	var parseSignedOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parseSignedOpsRaw), &parseSignedOps))
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseSignedOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_AccessList(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"amount":{"value":"-1000","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"1000","currency":{"symbol":"ETH","decimals":18}},"metadata":{"access_list":[{"address":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","access_list":[{"address":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Access lists are only created for contract calls
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"create_access_list": true},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:    3,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 25300, // 21000 + 2400 per address + 1900 per storage key
+	}
+
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasPrice",
+		ctx,
+	).Return(
+		big.NewInt(1000000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+	).Return(
+		uint64(3),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "25300000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","to":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","value":"0x3e8","data":"0x","nonce":"0x3","gas_price":"0x3b9aca00","gas":"0x62d4","chain_id":"0x5","access_list":[{"address":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","hex_bytes":"628d45caa66b8761185c4cbe168f9d6676d9473057b176188635819ec9f71734","account_identifier":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"amount":{"value":"-1000","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"1000","currency":{"symbol":"ETH","decimals":18}},"metadata":{"access_list":[{"address":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]}}]` // nolint
+	var parseOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parseOpsRaw), &parseOps))
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:    metadata.Nonce,
+		GasPrice: metadata.GasPrice,
+		ChainID:  big.NewInt(5),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"f973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c585cb99df4c93fb4e013687c18cf560e06b2f1a444dc66d71a4f27768feb6b282b00","signing_payload":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","hex_bytes":"628d45caa66b8761185c4cbe168f9d6676d9473057b176188635819ec9f71734","account_identifier":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"type":"0x1","nonce":"0x3","gasPrice":"0x3b9aca00","maxPriorityFeePerGas":null,"maxFeePerGas":null,"gas":"0x62d4","value":"0x3e8","input":"0x","v":"0x0","r":"0xf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c58","s":"0x5cb99df4c93fb4e013687c18cf560e06b2f1a444dc66d71a4f27768feb6b282b","to":"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d","chainId":"0x5","accessList":[{"address":"0x07865c6e87b9f70255377e024ace6630c1eaa37f","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}],"hash":"0x3934259099a9441c6d9af54025cb61c5d5b233f024bceefe861565d15f583570"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0x3934259099a9441c6d9af54025cb61c5d5b233f024bceefe861565d15f583570",
+		},
+	}, hashResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_CreateAccessList(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}},"metadata":{"method_signature":"approve(address,uint256)","method_args":["0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d","1000"]}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"create_access_list": true},
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A","contract_address":"0x07865c6E87B9F70255377e024ace6630C1Eaa37F","data":"0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8","create_access_list":true}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	accessList := ethTypes.AccessList{
+		{
+			Address:     common.HexToAddress("0x07865c6E87B9F70255377e024ace6630C1Eaa37F"),
+			StorageKeys: []common.Hash{common.HexToHash("0x01")},
+		},
+	}
+	metadata := &metadata{
+		Nonce:      2,
+		GasPrice:   big.NewInt(1000000000),
+		GasLimit:   44000,
+		AccessList: accessList,
+	}
+
+	contract := common.HexToAddress("0x07865c6E87B9F70255377e024ace6630C1Eaa37F")
+	msg := goEthereum.CallMsg{
+		From: common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+		To:   &contract,
+		Data: common.FromHex("0x095ea7b300000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d00000000000000000000000000000000000000000000000000000000000003e8"), // nolint
+	}
+	mockClient.On(
+		"CreateAccessList",
+		ctx,
+		msg,
+	).Return(
+		accessList,
+		nil,
+	).Once()
+
+	// The gas limit is estimated with the access list
+	msg.AccessList = accessList
+	mockClient.On(
+		"EstimateGas",
+		ctx,
+		msg,
+	).Return(
+		uint64(44000),
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasPrice",
+		ctx,
+	).Return(
+		big.NewInt(1000000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x2fCE4754d7D852405C8ACCB2f8f64FCCEA8B5F1A"),
+	).Return(
+		uint64(2),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "44000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// The created access list is included in the payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+
+	var unsignedTx transaction
+	assert.NoError(t, json.Unmarshal([]byte(payloadsResponse.UnsignedTransaction), &unsignedTx))
+	assert.Equal(t, accessList, unsignedTx.AccessList)
+	assert.Equal(t, uint8(ethTypes.AccessListTxType), ethTransaction(&unsignedTx).Type())
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_ContractDeployment(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.GoerliNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.GoerliChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CREATE","account":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}},"metadata":{"init_code":"0x600a600c600039600a6000f3602a60005260206000f3"}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"from":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","data":"0x600a600c600039600a6000f3602a60005260206000f3"}` // nolint
+	var options options
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Deployments must include init code
+	missingInitCode := `[{"operation_identifier":{"index":0},"type":"CREATE","account":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"amount":{"value":"0","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var missingInitCodeOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(missingInitCode), &missingInitCodeOps))
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        missingInitCodeOps,
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Test Metadata
+	metadata := &metadata{
+		Nonce:    0,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 100000,
+	}
+
+	// Deployments are estimated without a recipient
+	mockClient.On(
+		"EstimateGas",
+		ctx,
+		goEthereum.CallMsg{
+			From: common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+			Data: common.FromHex("0x600a600c600039600a6000f3602a60005260206000f3"),
+		},
+	).Return(
+		uint64(100000),
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasPrice",
+		ctx,
+	).Return(
+		big.NewInt(1000000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"PendingNonceAt",
+		ctx,
+		common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+	).Return(
+		uint64(0),
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "100000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"from":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","value":"0x0","data":"0x600a600c600039600a6000f3602a60005260206000f3","nonce":"0x0","gas_price":"0x3b9aca00","gas":"0x186a0","chain_id":"0x5"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","hex_bytes":"2eea753057abbf299a626d2957f95787bf2545c38198eeac006201df80238a1c","account_identifier":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:           metadata.Nonce,
+		GasPrice:        metadata.GasPrice,
+		ChainID:         big.NewInt(5),
+		ContractAddress: "0xF2E246BB76DF876Cef8b38ae84130F4F55De395b",
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"f973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c583de4851266bf4fada9882acb2664ee56592b2be73f25ff940e1b214123bace5f00","signing_payload":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","hex_bytes":"2eea753057abbf299a626d2957f95787bf2545c38198eeac006201df80238a1c","account_identifier":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"type":"0x0","nonce":"0x0","gasPrice":"0x3b9aca00","maxPriorityFeePerGas":null,"maxFeePerGas":null,"gas":"0x186a0","value":"0x0","input":"0x600a600c600039600a6000f3602a60005260206000f3","v":"0x2d","r":"0xf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c58","s":"0x3de4851266bf4fada9882acb2664ee56592b2be73f25ff940e1b214123bace5f","to":null,"hash":"0x045ade5cdfe4a6ef88c5cb0ea5692a612a45c0cab3f4b3e19f37a25eb8a8ab84"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0x045ade5cdfe4a6ef88c5cb0ea5692a612a45c0cab3f4b3e19f37a25eb8a8ab84",
+		},
+	}, hashResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: ops,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_UserOperation(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.MainnetNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	entryPoint := common.HexToAddress(ethereum.DefaultEntryPoint)
+	factory := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.MainnetChainConfig,
+		AccountAbstraction: &configuration.AccountAbstraction{
+			EntryPoint:     entryPoint,
+			AccountFactory: factory,
+			BundlerURL:     "http://bundler:4337",
+		},
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Derive
+	owner := common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	sender := common.HexToAddress("0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E")
+	publicKey := &types.PublicKey{
+		Bytes: forceHexDecode(
+			t,
+			"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		),
+		CurveType: types.Secp256k1,
+	}
+	mockClient.On(
+		"SmartAccountAddress",
+		ctx,
+		factory,
+		owner,
+	).Return(
+		sender,
+		nil,
+	).Once()
+	deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address:  "0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E",
+			Metadata: map[string]interface{}{"owner": "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		},
+	}, deriveResponse)
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E","metadata":{"owner":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"}},"amount":{"value":"-10000000000000000","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"},"amount":{"value":"10000000000000000","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	optionsRaw := `{"sender":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E","owner":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","call_data":"0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000002386f26fc1000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000"}` // nolint
+	var options userOperationOptions
+	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Metadata
+	opMetadata := &userOperationMetadata{
+		Owner:                "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+		Nonce:                (*hexutil.Big)(big.NewInt(0)),
+		InitCode:             ethereum.AccountFactoryInitCode(factory, owner),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(300000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2000000000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1000000000)),
+	}
+
+	// The smart account is not deployed yet, so the
+	// user operation includes the init code.
+	mockClient.On(
+		"UserOperationNonce",
+		ctx,
+		entryPoint,
+		sender,
+	).Return(
+		big.NewInt(0),
+		nil,
+	).Once()
+	mockClient.On(
+		"CodeAt",
+		ctx,
+		sender,
+		(*big.Int)(nil),
+	).Return(
+		[]byte{},
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(500000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1000000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"EstimateUserOperationGas",
+		ctx,
+		&ethereum.UserOperation{
+			Sender:               sender,
+			Nonce:                (*hexutil.Big)(big.NewInt(0)),
+			InitCode:             ethereum.AccountFactoryInitCode(factory, owner),
+			CallData:             hexutil.MustDecode("0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000002386f26fc1000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000"), // nolint
+			CallGasLimit:         (*hexutil.Big)(big.NewInt(0)),
+			VerificationGasLimit: (*hexutil.Big)(big.NewInt(0)),
+			PreVerificationGas:   (*hexutil.Big)(big.NewInt(0)),
+			MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2000000000)),
+			MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1000000000)),
+			PaymasterAndData:     []byte{},
+			Signature:            userOperationDummySignature,
+		},
+		entryPoint,
+	).Return(
+		&ethereum.UserOperationGas{
+			PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+			VerificationGasLimit: (*hexutil.Big)(big.NewInt(300000)),
+			CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+		},
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, opMetadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "675000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"sender":"0x2f7a8d7bd2b4a53f3c2b3e1b5e2d9f1c2a4b6d8e","nonce":"0x0","initCode":"0x9406cc6185a346906296840746125a0e449764545fbfb9cf0000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf0000000000000000000000000000000000000000000000000000000000000000","callData":"0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000002386f26fc1000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000","callGasLimit":"0x186a0","verificationGasLimit":"0x493e0","preVerificationGas":"0xc350","maxFeePerGas":"0x77359400","maxPriorityFeePerGas":"0x3b9aca00","paymasterAndData":"0x","signature":"0x","owner":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, opMetadata),
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E","hex_bytes":"607aa79e29b4eb940cd026cbc3e5f44efd2454c41d460e7bf8cd3b3bab98d917","account_identifier":{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parsedIntent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"},"amount":{"value":"-10000000000000000","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"},"amount":{"value":"10000000000000000","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var parsedOps []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(parsedIntent), &parsedOps))
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &parseMetadata{
+		Nonce:     0,
+		GasTipCap: big.NewInt(1000000000),
+		GasFeeCap: big.NewInt(2000000000),
+		ChainID:   big.NewInt(1),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parsedOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	signaturesRaw := `[{"hex_bytes":"f973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c581238bc8290fa22092668c222adf1fd180a058f63c536236d77dcbdc897a6af6f01","signing_payload":{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E","hex_bytes":"607aa79e29b4eb940cd026cbc3e5f44efd2454c41d460e7bf8cd3b3bab98d917","account_identifier":{"address":"0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"},"signature_type":"ecdsa_recovery"},"public_key":{"hex_bytes":"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798","curve_type":"secp256k1"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var signatures []*types.Signature
+	assert.NoError(t, json.Unmarshal([]byte(signaturesRaw), &signatures))
+	signedRaw := `{"sender":"0x2f7a8d7bd2b4a53f3c2b3e1b5e2d9f1c2a4b6d8e","nonce":"0x0","initCode":"0x9406cc6185a346906296840746125a0e449764545fbfb9cf0000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf0000000000000000000000000000000000000000000000000000000000000000","callData":"0xb61d27f60000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad000000000000000000000000000000000000000000000000002386f26fc1000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000","callGasLimit":"0x186a0","verificationGasLimit":"0x493e0","preVerificationGas":"0xc350","maxFeePerGas":"0x77359400","maxPriorityFeePerGas":"0x3b9aca00","paymasterAndData":"0x","signature":"0xf973a0b87062c389d125d8199e803b832b6ac6bf7867a4f6cd87506060fc4c581238bc8290fa22092668c222adf1fd180a058f63c536236d77dcbdc897a6af6f1c"}` // nolint
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad",
+		},
+	}, hashResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parsedOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x2f7a8D7bD2B4A53F3c2b3e1B5E2d9f1C2a4b6D8E"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	// Test Submit
+	var signedOp ethereum.UserOperation
+	assert.NoError(t, json.Unmarshal([]byte(signedRaw), &signedOp))
+	mockClient.On(
+		"SendUserOperation",
+		ctx,
+		&signedOp,
+		entryPoint,
+	).Return(
+		common.HexToHash("0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad"),
+		nil,
+	).Once()
+	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0x761956867ad2571e34504dbb3a7990581f452fd00bdaf35b19cc57c810ffc0ad",
+		},
+	}, submitResponse)
+
+	// A user operation is signed by a single owner
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          []*types.Signature{},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrSignatureInvalid.Code, err.Code)
+
+	// The signature must be made by the owner of the
+	// smart account.
+	wrongSignature := *signatures[0]
+	wrongSignature.Bytes = forceHexDecode(
+		t,
+		"bb50e2d89a4ed70663d080659fe0ad4b9bc3e06c17a227433966cb59ceee020d04cbc070ce6d500978057ec0d642cc9540e7bd1e31a0a83a1c159e833aa8748701", // nolint
+	)
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          []*types.Signature{&wrongSignature},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrSignatureInvalid.Code, err.Code)
+
+	// The owner of a deployed smart account is fetched
+	// from the account, and no init code is included.
+	mockClient.On(
+		"UserOperationNonce",
+		ctx,
+		entryPoint,
+		sender,
+	).Return(
+		big.NewInt(1),
+		nil,
+	).Once()
+	mockClient.On(
+		"CodeAt",
+		ctx,
+		sender,
+		(*big.Int)(nil),
+	).Return(
+		[]byte{0x60, 0x80},
+		nil,
+	).Once()
+	mockClient.On(
+		"SmartAccountOwner",
+		ctx,
+		sender,
+	).Return(
+		owner,
+		nil,
+	).Once()
+	mockClient.On(
+		"HeaderByNumber",
+		ctx,
+		(*big.Int)(nil),
+	).Return(
+		&ethTypes.Header{BaseFee: big.NewInt(500000000)},
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestGasTipCap",
+		ctx,
+	).Return(
+		big.NewInt(1000000000),
+		nil,
+	).Once()
+	mockClient.On(
+		"EstimateUserOperationGas",
+		ctx,
+		mock.Anything,
+		entryPoint,
+	).Return(
+		&ethereum.UserOperationGas{
+			PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+			VerificationGasLimit: (*hexutil.Big)(big.NewInt(100000)),
+			CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+		},
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options: forceMarshalMap(t, &userOperationOptions{
+			Sender:   options.Sender,
+			CallData: options.CallData,
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, &userOperationMetadata{
+			Owner:                "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+			Nonce:                (*hexutil.Big)(big.NewInt(1)),
+			CallGasLimit:         (*hexutil.Big)(big.NewInt(100000)),
+			VerificationGasLimit: (*hexutil.Big)(big.NewInt(100000)),
+			PreVerificationGas:   (*hexutil.Big)(big.NewInt(50000)),
+			MaxFeePerGas:         (*hexutil.Big)(big.NewInt(2000000000)),
+			MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(1000000000)),
+		}),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "375000000000000",
+				Currency: ethereum.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Smart accounts cannot be fetched from the account
+	// factory offline without its proxy code.
+	cfg.Mode = configuration.Offline
+	deriveResponse, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+	})
+	assert.Nil(t, deriveResponse)
+	assert.Equal(t, ErrUnavailableOffline, err)
+
+	// With the proxy code of the account factory, the
+	// counterfactual address is computed offline.
+	cfg.AccountAbstraction.AccountImplementation = common.HexToAddress(
+		"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF",
+	)
+	cfg.AccountAbstraction.AccountProxyCode = hexutil.MustDecode(
+		"0x60806040526040516100b83803806100b8833981016040819052610022916100a1565b600080546001600160a01b0319166001600160a01b039390931692909217909155005b", // nolint
+	)
+	deriveResponse, err = servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address:  "0xBd28f98CFbBed987B01DABB31d2dF3505b9536a0",
+			Metadata: map[string]interface{}{"owner": "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		},
+	}, deriveResponse)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionService_Safe(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    ethereum.MainnetNetwork,
+		Blockchain: ethereum.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.MainnetChainConfig,
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	// Test Preprocess
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901"},"amount":{"value":"-1000000000000000000","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"},"amount":{"value":"1000000000000000000","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	var ops []*types.Operation
+	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"safe_transaction": true},
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: map[string]interface{}{
+			"safe_transaction": true,
+			"safe":             "0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901",
+		},
+	}, preprocessResponse)
+
+	// Test Metadata
+	metadataRaw := `{"safe_nonce":"0x7","safe_transaction":true,"safe_owners":["0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"],"safe_threshold":"0x2"}` // nolint
+	var metadata map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(metadataRaw), &metadata))
+
+	mockClient.On(
+		"SafeState",
+		ctx,
+		common.HexToAddress("0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901"),
+	).Return(
+		&ethereum.SafeState{
+			Nonce: big.NewInt(7),
+			Owners: []common.Address{
+				common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"),
+				common.HexToAddress("0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"),
+			},
+			Threshold: 2,
+		},
+		nil,
+	).Once()
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: metadata,
+	}, metadataResponse)
+
+	// Test Payloads
+	unsignedRaw := `{"safe_transaction":true,"safe":"0x1b1c0a2f4d2c0d4e5f60718293a4b5c6d7e8f901","to":"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad","value":"0xde0b6b3a7640000","data":"0x","operation":0,"safeTxGas":"0x0","baseGas":"0x0","gasPrice":"0x0","gasToken":"0x0000000000000000000000000000000000000000","refundReceiver":"0x0000000000000000000000000000000000000000","nonce":"0x7","signatures":"0x","owners":["0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"],"threshold":"0x2"}` // nolint
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadata,
+	})
+	assert.Nil(t, err)
+	payloadsRaw := `[{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf","hex_bytes":"f1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7","account_identifier":{"address":"0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},"signature_type":"ecdsa_recovery"},{"address":"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF","hex_bytes":"f1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7","account_identifier":{"address":"0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},"signature_type":"ecdsa_recovery"}]` // nolint
+	var payloads []*types.SigningPayload
+	assert.NoError(t, json.Unmarshal([]byte(payloadsRaw), &payloads))
+	assert.Equal(t, &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: unsignedRaw,
+		Payloads:            payloads,
+	}, payloadsResponse)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       unsignedRaw,
+	})
+	assert.Nil(t, err)
+	parseMetadata := &safeParseMetadata{
+		SafeNonce:  (*hexutil.Big)(big.NewInt(7)),
+		SafeTxHash: "0xf1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7",
+		ChainID:    (*hexutil.Big)(big.NewInt(1)),
+	}
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+		Metadata:                 forceMarshalMap(t, parseMetadata),
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	keys := map[string]*ecdsa.PrivateKey{}
+	for _, hexKey := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+	} {
+		key, err := crypto.HexToECDSA(hexKey)
+		assert.NoError(t, err)
+		keys[crypto.PubkeyToAddress(key.PublicKey).Hex()] = key
+	}
+
+	signatures := make([]*types.Signature, len(payloadsResponse.Payloads))
+	for i, payload := range payloadsResponse.Payloads {
+		key := keys[payload.AccountIdentifier.Address]
+		signature, err := crypto.Sign(payload.Bytes, key)
+		assert.NoError(t, err)
+		signatures[i] = &types.Signature{
+			SigningPayload: payload,
+			PublicKey: &types.PublicKey{
+				Bytes:     crypto.CompressPubkey(&key.PublicKey),
+				CurveType: types.Secp256k1,
+			},
+			SignatureType: types.EcdsaRecovery,
+			Bytes:         signature,
+		}
+	}
+
+	// The signatures of all owners are required to meet
+	// the threshold.
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures[:1],
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrSignatureInvalid.Code, err.Code)
+
+	// The signatures are packed in order of owner
+	// address, not in the order they are provided,
+	// with recovery IDs of 27 or 28.
+	packedSignatures := []byte{}
+	for _, signature := range []*types.Signature{signatures[1], signatures[0]} {
+		packedSignatures = append(packedSignatures, signature.Bytes[:crypto.RecoveryIDOffset]...)
+		packedSignatures = append(packedSignatures, signature.Bytes[crypto.RecoveryIDOffset]+27) // nolint:gomnd
+	}
+	signedRaw := fmt.Sprintf(
+		`{"safe_transaction":true,"safe":"0x1b1c0a2f4d2c0d4e5f60718293a4b5c6d7e8f901","to":"0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad","value":"0xde0b6b3a7640000","data":"0x","operation":0,"safeTxGas":"0x0","baseGas":"0x0","gasPrice":"0x0","gasToken":"0x0000000000000000000000000000000000000000","refundReceiver":"0x0000000000000000000000000000000000000000","nonce":"0x7","signatures":"%s"}`, // nolint
+		hexutil.Encode(packedSignatures),
+	)
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: unsignedRaw,
+		Signatures:          signatures,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionCombineResponse{
+		SignedTransaction: signedRaw,
+	}, combineResponse)
+
+	// Test Hash
+	hashResponse, err := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "0xf1eb6abfbde91f27e625fead6431d0abdc2deaa169a5b5ff8e7ddf50839cf9e7",
+		},
+	}, hashResponse)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, err)
+	execTransactionData := hexutil.MustDecode(
+		"0x6a7612020000000000000000000000003fc91a3afd70395cd496c647d5a6cc9d4b2b7fad0000000000000000000000000000000000000000000000000de0b6b3a76400000000000000000000000000000000000000000000000000000000000000000140000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000016000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000082", // nolint
+	)
+	execTransactionData = append(execTransactionData, packedSignatures...)
+
+	// The packed signatures are padded to a multiple
+	// of 32 bytes.
+	parseMetadata.ExecTransactionData = append(execTransactionData, make([]byte, 30)...) // nolint:gomnd
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: ops,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},
+			{Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+		},
+		Metadata: forceMarshalMap(t, parseMetadata),
+	}, parseSignedResponse)
+
+	// Test Submit
+	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, submitResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+	assert.Equal(t, "0x1B1C0a2f4d2c0D4e5F60718293a4b5C6D7E8F901", err.Details["safe"])
+	assert.Equal(t, hexutil.Encode(parseMetadata.ExecTransactionData), err.Details["exec_transaction_data"])
+
+	// In offline mode, the nonce, owners, and
+	// threshold must be provided.
+	cfg.Mode = configuration.Offline
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          map[string]interface{}{"safe_transaction": true},
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	offlineMetadata := map[string]interface{}{"safe_transaction": true}
+	for k, v := range metadata {
+		offlineMetadata[k] = v
+	}
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          offlineMetadata,
+		},
+	)
+	assert.Nil(t, err)
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: metadata,
+	}, metadataResponse)
+
+	mockClient.AssertExpectations(t)
+}
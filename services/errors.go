@@ -37,6 +37,9 @@ var (
 		ErrInvalidAddress,
 		ErrGethNotReady,
 		ErrInvalidInput,
+		ErrCurrencyNotSupported,
+		ErrTransactionNotFound,
+		ErrBundler,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -152,6 +155,28 @@ var (
 		Code:    14, //nolint
 		Message: "invalid input",
 	}
+
+	// ErrCurrencyNotSupported is returned when a
+	// *types.Currency is not ETH or a supported token.
+	ErrCurrencyNotSupported = &types.Error{
+		Code:    15, //nolint
+		Message: "Currency not supported",
+	}
+
+	// ErrTransactionNotFound is returned when a
+	// transaction is not in the mempool.
+	ErrTransactionNotFound = &types.Error{
+		Code:      16, //nolint
+		Message:   "Transaction not found",
+		Retriable: true,
+	}
+
+	// ErrBundler is returned when the ERC-4337
+	// bundler returns an error.
+	ErrBundler = &types.Error{
+		Code:    17, //nolint
+		Message: "Bundler error",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
@@ -16,10 +16,12 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/coinbase/rosetta-ethereum/configuration"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
 )
 
 // MempoolAPIService implements the server.MempoolAPIServicer interface.
@@ -61,5 +63,19 @@ func (s *MempoolAPIService) MempoolTransaction(
 	ctx context.Context,
 	request *types.MempoolTransactionRequest,
 ) (*types.MempoolTransactionResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	tx, err := s.client.GetMempoolTransaction(ctx, request.TransactionIdentifier.Hash)
+	if errors.Is(err, goEthereum.NotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return &types.MempoolTransactionResponse{
+		Transaction: tx,
+	}, nil
 }
@@ -16,11 +16,13 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/coinbase/rosetta-ethereum/configuration"
 	mocks "github.com/coinbase/rosetta-ethereum/mocks/services"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,8 +42,8 @@ func TestMempoolService_Offline(t *testing.T) {
 
 	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
 	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 
 	mockClient.AssertExpectations(t)
 }
@@ -74,5 +76,47 @@ func TestMempoolService_Online(t *testing.T) {
 		assert.Equal(t, mempool, actualMempool)
 	})
 
+	t.Run("mempool transaction", func(t *testing.T) {
+		hash := "0xb89dbf00e5c1a6ec89a4d42879969e8ea843a6814a783fb5c2bbf712ea1ef071"
+		transaction := &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{
+				Hash: hash,
+			},
+		}
+		mockClient.
+			On("GetMempoolTransaction", ctx, hash).
+			Return(transaction, nil).
+			Once()
+
+		actualTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: &types.TransactionIdentifier{
+				Hash: hash,
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &types.MempoolTransactionResponse{
+			Transaction: transaction,
+		}, actualTransaction)
+	})
+
+	t.Run("mempool transaction not found", func(t *testing.T) {
+		hash := "0x0000000000000000000000000000000000000000000000000000000000000000"
+		mockClient.
+			On("GetMempoolTransaction", ctx, hash).
+			Return(nil, fmt.Errorf("%w: %s", goEthereum.NotFound, hash)).
+			Once()
+
+		actualTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: &types.TransactionIdentifier{
+				Hash: hash,
+			},
+		})
+
+		assert.Nil(t, actualTransaction)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+		assert.Equal(t, ErrTransactionNotFound.Message, err.Message)
+	})
+
 	mockClient.AssertExpectations(t)
 }
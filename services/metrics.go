@@ -0,0 +1,69 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// serverMetricPrefix is the prefix of the metrics
+	// of each endpoint.
+	serverMetricPrefix = "server"
+
+	// unknownEndpointMetric is the name of the metrics of
+	// requests to endpoints that do not exist, so that
+	// arbitrary paths do not create new metrics.
+	unknownEndpointMetric = serverMetricPrefix + "/unknown"
+)
+
+// statusRecorder is an http.ResponseWriter that
+// records the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+}
+
+// WriteHeader records the status code and writes it.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// MetricsMiddleware records the number of requests, the number
+// of errors, and the latency of each endpoint in the provided
+// metrics.Registry.
+func MetricsMiddleware(registry metrics.Registry, inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		inner.ServeHTTP(recorder, r)
+
+		name := serverMetricPrefix + r.URL.Path
+		if recorder.status == http.StatusNotFound || recorder.status == http.StatusMethodNotAllowed {
+			name = unknownEndpointMetric
+		}
+
+		metrics.GetOrRegisterTimer(name, registry).UpdateSince(start)
+		if recorder.status >= http.StatusBadRequest {
+			metrics.GetOrRegisterCounter(name+"/errors", registry).Inc(1)
+		}
+	})
+}
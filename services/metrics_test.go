@@ -0,0 +1,75 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	metrics.Enabled = true
+	registry := metrics.NewRegistry()
+
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/network/status":
+			// The status is not written explicitly.
+			_, err := w.Write([]byte("{}"))
+			assert.NoError(t, err)
+		case "/block":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	handler := MetricsMiddleware(registry, inner)
+
+	for _, path := range []string{"/network/status", "/network/status", "/block", "/random/path"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	for _, test := range []struct {
+		name   string
+		count  int64
+		errors int64
+	}{
+		{name: "server/network/status", count: 2},
+		{name: "server/block", count: 1, errors: 1},
+		{name: "server/unknown", count: 1, errors: 1},
+	} {
+		timer, ok := registry.Get(test.name).(metrics.Timer)
+		assert.True(t, ok)
+		assert.Equal(t, test.count, timer.Count())
+
+		errs, ok := registry.Get(test.name + "/errors").(metrics.Counter)
+		if test.errors == 0 {
+			assert.False(t, ok)
+			continue
+		}
+
+		assert.True(t, ok)
+		assert.Equal(t, test.errors, errs.Count())
+	}
+
+	// Requests to endpoints that do not exist
+	// do not create new metrics.
+	assert.Nil(t, registry.Get("server/random/path"))
+}
@@ -67,6 +67,7 @@ func (s *NetworkAPIService) NetworkOptions(
 			OperationStatuses:       ethereum.OperationStatuses,
 			HistoricalBalanceLookup: ethereum.HistoricalBalanceSupported,
 			CallMethods:             ethereum.CallMethods,
+			MempoolCoins:            ethereum.IncludeMempoolCoins,
 		},
 	}, nil
 }
@@ -40,6 +40,7 @@ var (
 			Errors:                  Errors,
 			HistoricalBalanceLookup: ethereum.HistoricalBalanceSupported,
 			CallMethods:             ethereum.CallMethods,
+			MempoolCoins:            ethereum.IncludeMempoolCoins,
 		},
 	}
 
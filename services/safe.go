@@ -0,0 +1,418 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// isSafeMode returns a boolean indicating if the options or
+// metadata passed between the construction endpoints are those
+// of a Safe transaction.
+func isSafeMode(m map[string]interface{}) bool {
+	var mode safeMode
+	return unmarshalJSONMap(m, &mode) == nil && mode.SafeTransaction
+}
+
+// isSafeTransaction returns a boolean indicating if the
+// unsigned or signed transaction is a Safe transaction.
+func isSafeTransaction(tx string) bool {
+	var mode safeMode
+	return json.Unmarshal([]byte(tx), &mode) == nil && mode.SafeTransaction
+}
+
+// preprocessSafeTransaction returns the options of a Safe
+// transaction in which the Safe debited by the operations
+// executes the transfer.
+func (s *ConstructionAPIService) preprocessSafeTransaction(
+	operations []*types.Operation,
+	input *safeMetadata,
+) (*types.ConstructionPreprocessResponse, *types.Error) {
+	call, rErr := s.executedCall(operations, "Safe transactions")
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if !input.empty() || s.config.Mode != configuration.Online {
+		if err := input.validate(); err != nil {
+			return nil, wrapErr(ErrInvalidInput, err)
+		}
+	}
+
+	preprocessOutput := &safeOptions{
+		safeMode:     safeMode{SafeTransaction: true},
+		Safe:         call.From,
+		safeMetadata: *input,
+	}
+
+	marshaled, err := marshalJSONMap(preprocessOutput)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: marshaled,
+	}, nil
+}
+
+// safeTransactionMetadata returns the nonce, owners, and
+// threshold of the Safe. Metadata provided to
+// /construction/preprocess is returned instead of being
+// fetched from geth. No fee is suggested, as the owners
+// signing a Safe transaction do not pay for its gas.
+func (s *ConstructionAPIService) safeTransactionMetadata(
+	ctx context.Context,
+	request *types.ConstructionMetadataRequest,
+) (*types.ConstructionMetadataResponse, *types.Error) {
+	var input safeOptions
+	if err := unmarshalJSONMap(request.Options, &input); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	metadata := &input.safeMetadata
+	if metadata.empty() {
+		if s.config.Mode != configuration.Online {
+			return nil, ErrUnavailableOffline
+		}
+
+		state, err := s.client.SafeState(ctx, common.HexToAddress(input.Safe))
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+
+		owners := make([]string, len(state.Owners))
+		for i, owner := range state.Owners {
+			owners[i] = owner.Hex()
+		}
+
+		threshold := hexutil.Uint64(state.Threshold)
+		metadata = &safeMetadata{
+			SafeNonce:     (*hexutil.Big)(state.Nonce),
+			SafeOwners:    owners,
+			SafeThreshold: &threshold,
+		}
+	}
+
+	if err := metadata.validate(); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	metadataMap, err := marshalJSONMap(&safeModeMetadata{
+		safeMode:     input.safeMode,
+		safeMetadata: *metadata,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionMetadataResponse{
+		Metadata: metadataMap,
+	}, nil
+}
+
+// safeTransactionPayloads returns the unsigned Safe transaction
+// and a signing payload of its EIP-712 hash for each owner of
+// the Safe.
+func (s *ConstructionAPIService) safeTransactionPayloads(
+	request *types.ConstructionPayloadsRequest,
+) (*types.ConstructionPayloadsResponse, *types.Error) {
+	var metadata safeModeMetadata
+	if err := unmarshalJSONMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	if err := metadata.validate(); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	call, rErr := s.executedCall(request.Operations, "Safe transactions")
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	safeTx := ethereum.SafeTransaction{
+		Safe:       common.HexToAddress(call.From),
+		To:         common.HexToAddress(call.To),
+		Value:      (*hexutil.Big)(call.Value),
+		Data:       call.Data,
+		SafeTxGas:  (*hexutil.Big)(new(big.Int)),
+		BaseGas:    (*hexutil.Big)(new(big.Int)),
+		GasPrice:   (*hexutil.Big)(new(big.Int)),
+		Nonce:      metadata.SafeNonce,
+		Signatures: []byte{},
+	}
+	safeTxHash := safeTx.Hash(s.config.Params.ChainID)
+
+	owners := make([]string, len(metadata.SafeOwners))
+	payloads := make([]*types.SigningPayload, len(metadata.SafeOwners))
+	for i, owner := range metadata.SafeOwners {
+		owners[i] = ethereum.MustChecksum(owner)
+		payloads[i] = &types.SigningPayload{
+			AccountIdentifier: &types.AccountIdentifier{Address: owners[i]},
+			Bytes:             safeTxHash.Bytes(),
+			SignatureType:     types.EcdsaRecovery,
+		}
+	}
+
+	unsignedTxJSON, err := json.Marshal(&unsignedSafeTransaction{
+		safeMode:        metadata.safeMode,
+		SafeTransaction: safeTx,
+		Owners:          owners,
+		Threshold:       *metadata.SafeThreshold,
+		MethodSignature: call.MethodSignature,
+		MethodArgs:      call.MethodArgs,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: string(unsignedTxJSON),
+		Payloads:            payloads,
+	}, nil
+}
+
+// combineSafeTransaction returns the Safe transaction signed
+// by the owners of the provided signatures. Safes require the
+// signatures to be sorted by owner address, so they are packed
+// in that order regardless of the order they are provided in.
+func (s *ConstructionAPIService) combineSafeTransaction(
+	request *types.ConstructionCombineRequest,
+) (*types.ConstructionCombineResponse, *types.Error) {
+	var unsignedTx unsignedSafeTransaction
+	if err := json.Unmarshal([]byte(request.UnsignedTransaction), &unsignedTx); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	owners := map[common.Address]struct{}{}
+	for _, owner := range unsignedTx.Owners {
+		owners[common.HexToAddress(owner)] = struct{}{}
+	}
+
+	safeTxHash := unsignedTx.Hash(s.config.Params.ChainID)
+	signatures := map[common.Address][]byte{}
+	signers := []common.Address{}
+	for _, signature := range request.Signatures {
+		if len(signature.Bytes) != crypto.SignatureLength {
+			return nil, wrapErr(
+				ErrSignatureInvalid,
+				fmt.Errorf(
+					"expected %d byte signature but got %d",
+					crypto.SignatureLength,
+					len(signature.Bytes),
+				),
+			)
+		}
+
+		pubkey, err := crypto.SigToPub(safeTxHash.Bytes(), signature.Bytes)
+		if err != nil {
+			return nil, wrapErr(ErrSignatureInvalid, err)
+		}
+
+		signer := crypto.PubkeyToAddress(*pubkey)
+		if _, ok := owners[signer]; !ok {
+			return nil, wrapErr(
+				ErrSignatureInvalid,
+				fmt.Errorf("%s is not an owner of %s", signer.Hex(), unsignedTx.Safe.Hex()),
+			)
+		}
+
+		if _, ok := signatures[signer]; ok {
+			return nil, wrapErr(
+				ErrSignatureInvalid,
+				fmt.Errorf("%s signed more than once", signer.Hex()),
+			)
+		}
+
+		// Safes verify EIP-712 signatures with
+		// ecrecover, which expects a recovery ID of
+		// 27 or 28.
+		signatures[signer] = append(
+			common.CopyBytes(signature.Bytes[:crypto.RecoveryIDOffset]),
+			signature.Bytes[crypto.RecoveryIDOffset]+27, // nolint:gomnd
+		)
+		signers = append(signers, signer)
+	}
+
+	if uint64(len(signers)) < uint64(unsignedTx.Threshold) {
+		return nil, wrapErr(
+			ErrSignatureInvalid,
+			fmt.Errorf(
+				"expected at least %d signatures but got %d",
+				unsignedTx.Threshold,
+				len(signers),
+			),
+		)
+	}
+
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i].Bytes(), signers[j].Bytes()) < 0
+	})
+
+	signedTx := signedSafeTransaction{
+		safeMode:        unsignedTx.safeMode,
+		SafeTransaction: unsignedTx.SafeTransaction,
+	}
+	signedTx.Signatures = make([]byte, 0, len(signers)*crypto.SignatureLength)
+	for _, signer := range signers {
+		signedTx.Signatures = append(signedTx.Signatures, signatures[signer]...)
+	}
+
+	signedTxJSON, err := json.Marshal(&signedTx)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionCombineResponse{
+		SignedTransaction: string(signedTxJSON),
+	}, nil
+}
+
+// hashSafeTransaction returns the safeTxHash of the signed
+// Safe transaction, which identifies it in the Safe.
+func (s *ConstructionAPIService) hashSafeTransaction(
+	request *types.ConstructionHashRequest,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	var signedTx signedSafeTransaction
+	if err := json.Unmarshal([]byte(request.SignedTransaction), &signedTx); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: signedTx.Hash(s.config.Params.ChainID).Hex(),
+		},
+	}, nil
+}
+
+// parseSafeTransaction returns the operations of the transfer
+// executed by the Safe. The signers of a signed Safe transaction
+// are recovered from its signatures.
+func (s *ConstructionAPIService) parseSafeTransaction(
+	request *types.ConstructionParseRequest,
+) (*types.ConstructionParseResponse, *types.Error) {
+	var unsignedTx unsignedSafeTransaction
+	if err := json.Unmarshal([]byte(request.Transaction), &unsignedTx); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	safeTx := unsignedTx.SafeTransaction
+	if safeTx.Value == nil || safeTx.Nonce == nil {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			errors.New("value and nonce must be populated"),
+		)
+	}
+
+	// Signed Safe transactions do not include the method
+	// signature, so the raw calldata is returned instead.
+	if request.Signed {
+		unsignedTx.MethodSignature = ""
+		unsignedTx.MethodArgs = nil
+	}
+
+	checkSafe := safeTx.Safe.Hex()
+	ops, rErr := s.transferOperations(checkSafe, &transaction{
+		From:            checkSafe,
+		To:              safeTx.To.Hex(),
+		Value:           safeTx.Value.ToInt(),
+		Data:            safeTx.Data,
+		MethodSignature: unsignedTx.MethodSignature,
+		MethodArgs:      unsignedTx.MethodArgs,
+	})
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	safeTxHash := safeTx.Hash(s.config.Params.ChainID)
+	metadata := &safeParseMetadata{
+		SafeNonce:  safeTx.Nonce,
+		SafeTxHash: safeTxHash.Hex(),
+		ChainID:    (*hexutil.Big)(s.config.Params.ChainID),
+	}
+
+	signers := []*types.AccountIdentifier{}
+	if request.Signed {
+		if len(safeTx.Signatures)%crypto.SignatureLength != 0 {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("signatures are not a multiple of %d bytes", crypto.SignatureLength),
+			)
+		}
+
+		for i := 0; i < len(safeTx.Signatures); i += crypto.SignatureLength {
+			signature := common.CopyBytes(safeTx.Signatures[i : i+crypto.SignatureLength])
+			signature[crypto.RecoveryIDOffset] -= 27 // nolint:gomnd
+
+			pubkey, err := crypto.SigToPub(safeTxHash.Bytes(), signature)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+
+			signers = append(signers, &types.AccountIdentifier{
+				Address: crypto.PubkeyToAddress(*pubkey).Hex(),
+			})
+		}
+
+		metadata.ExecTransactionData = safeTx.ExecTransactionData()
+	}
+
+	metaMap, err := marshalJSONMap(metadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: signers,
+		Metadata:                 metaMap,
+	}, nil
+}
+
+// safeTransactionSubmitError returns the error of
+// /construction/submit for a signed Safe transaction. A Safe
+// transaction is executed by a separate transaction calling the
+// Safe, which any account can construct, sign, and send with a
+// CALL operation crediting the Safe (populating the returned
+// exec_transaction_data as data in its metadata).
+func safeTransactionSubmitError(signedTransaction string) *types.Error {
+	var signedTx signedSafeTransaction
+	if err := json.Unmarshal([]byte(signedTransaction), &signedTx); err != nil {
+		return wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	submitErr := wrapErr(
+		ErrInvalidInput,
+		errors.New("signed Safe transactions are executed by calling the Safe with the exec_transaction_data"),
+	)
+	submitErr.Details["safe"] = signedTx.Safe.Hex()
+	submitErr.Details["exec_transaction_data"] = hexutil.Encode(signedTx.ExecTransactionData())
+	return submitErr
+}
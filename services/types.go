@@ -17,9 +17,15 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-ethereum/ethereum"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
+	goEthereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
@@ -39,7 +45,7 @@ type Client interface {
 	Block(
 		context.Context,
 		*types.PartialBlockIdentifier,
-	) (*types.Block, error)
+	) (*types.BlockResponse, error)
 
 	Transaction(
 		context.Context,
@@ -51,40 +57,341 @@ type Client interface {
 		context.Context,
 		*types.AccountIdentifier,
 		*types.PartialBlockIdentifier,
+		[]*types.Currency,
 	) (*types.AccountBalanceResponse, error)
 
+	AccountCoins(
+		context.Context,
+		*types.AccountIdentifier,
+		[]*types.Currency,
+	) (*types.AccountCoinsResponse, error)
+
+	EstimateGas(ctx context.Context, msg goEthereum.CallMsg) (uint64, error)
+
+	CreateAccessList(ctx context.Context, msg goEthereum.CallMsg) (ethTypes.AccessList, error)
+
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
+
 	PendingNonceAt(context.Context, common.Address) (uint64, error)
 
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+
+	FeeHistory(
+		ctx context.Context,
+		blockCount uint64,
+		lastBlock *big.Int,
+		rewardPercentiles []float64,
+	) (*ethereum.FeeHistory, error)
+
 	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
 
 	GetMempool(ctx context.Context) (*types.MempoolResponse, error)
 
+	GetMempoolTransaction(ctx context.Context, hash string) (*types.Transaction, error)
+
 	Call(
 		ctx context.Context,
 		request *types.CallRequest,
 	) (*types.CallResponse, error)
+
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+
+	SmartAccountAddress(
+		ctx context.Context,
+		factory common.Address,
+		owner common.Address,
+	) (common.Address, error)
+
+	SmartAccountOwner(ctx context.Context, account common.Address) (common.Address, error)
+
+	UserOperationNonce(
+		ctx context.Context,
+		entryPoint common.Address,
+		sender common.Address,
+	) (*big.Int, error)
+
+	EstimateUserOperationGas(
+		ctx context.Context,
+		op *ethereum.UserOperation,
+		entryPoint common.Address,
+	) (*ethereum.UserOperationGas, error)
+
+	SendUserOperation(
+		ctx context.Context,
+		op *ethereum.UserOperation,
+		entryPoint common.Address,
+	) (common.Hash, error)
+
+	SafeState(ctx context.Context, safe common.Address) (*ethereum.SafeState, error)
 }
 
+// options is passed from /construction/preprocess to
+// /construction/metadata. ContractAddress, Data, and Value
+// are populated when the transfer calls a contract. Only Data
+// and Value are populated when the transaction deploys a
+// contract, as a deployment has no recipient. AccessList
+// is populated when the operations include an access list, so
+// that it is accounted for in the gas limit.
 type options struct {
-	From string `json:"from"`
+	From            string              `json:"from"`
+	ContractAddress string              `json:"contract_address,omitempty"`
+	Data            string              `json:"data,omitempty"`
+	Value           string              `json:"value,omitempty"`
+	AccessList      ethTypes.AccessList `json:"access_list,omitempty"`
+
+	preprocessMetadata
+}
+
+// executesCode returns a boolean indicating if the transaction
+// calls or deploys a contract, so that its gas limit is estimated.
+func (o *options) executesCode() bool {
+	return len(o.ContractAddress) > 0 || len(o.Data) > 0
+}
+
+// preprocessMetadata is the optional metadata of
+// /construction/preprocess. Any populated field is used
+// instead of the value /construction/metadata fetches
+// from geth, so a transaction can be constructed in
+// offline mode if all required fields are populated.
+//
+// ReplaceTransactionHash is populated to replace a pending
+// transaction. The replacement uses the nonce of the pending
+// transaction and fees high enough for geth to accept it.
+//
+// CreateAccessList is populated to generate the access list of
+// a contract call with eth_createAccessList.
+//
+// FeeStrategy (slow, standard, or fast) or FeePercentile is
+// populated to set the priority fee (or gas price) to the
+// median of the rewards paid at that percentile in recent
+// blocks, as returned by eth_feeHistory.
+type preprocessMetadata struct {
+	Nonce                  string   `json:"nonce,omitempty"`
+	GasPrice               string   `json:"gas_price,omitempty"`
+	GasTipCap              string   `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap              string   `json:"max_fee_per_gas,omitempty"`
+	GasLimit               string   `json:"gas_limit,omitempty"`
+	ReplaceTransactionHash string   `json:"replace_transaction_hash,omitempty"`
+	CreateAccessList       bool     `json:"create_access_list,omitempty"`
+	FeeStrategy            string   `json:"fee_strategy,omitempty"`
+	FeePercentile          *float64 `json:"fee_percentile,omitempty"`
+}
+
+// empty returns a boolean indicating if no field is populated.
+func (m *preprocessMetadata) empty() bool {
+	return *m == preprocessMetadata{}
+}
+
+// validate ensures all populated fields can be decoded and
+// that the fees are either for a legacy transaction
+// (gas_price) or for an EIP-1559 transaction (max_fee_per_gas
+// and max_priority_fee_per_gas).
+func (m *preprocessMetadata) validate() error {
+	if len(m.Nonce) > 0 {
+		if _, err := hexutil.DecodeUint64(m.Nonce); err != nil {
+			return fmt.Errorf("%w: unable to decode nonce", err)
+		}
+	}
+
+	if len(m.GasLimit) > 0 {
+		if _, err := hexutil.DecodeUint64(m.GasLimit); err != nil {
+			return fmt.Errorf("%w: unable to decode gas_limit", err)
+		}
+	}
+
+	if _, _, _, err := m.fees(); err != nil {
+		return err
+	}
+
+	if len(m.GasPrice) > 0 && (len(m.GasFeeCap) > 0 || len(m.GasTipCap) > 0) {
+		return errors.New("gas_price cannot be populated with max_fee_per_gas or max_priority_fee_per_gas")
+	}
+
+	if (len(m.GasFeeCap) > 0) != (len(m.GasTipCap) > 0) {
+		return errors.New("max_fee_per_gas and max_priority_fee_per_gas must be populated together")
+	}
+
+	if len(m.ReplaceTransactionHash) > 0 {
+		hash, err := hexutil.Decode(m.ReplaceTransactionHash)
+		if err != nil || len(hash) != common.HashLength {
+			return fmt.Errorf("%s is not a valid transaction hash", m.ReplaceTransactionHash)
+		}
+
+		if len(m.Nonce) > 0 || len(m.GasPrice) > 0 || len(m.GasFeeCap) > 0 {
+			return errors.New("nonce and fees cannot be populated with replace_transaction_hash")
+		}
+	}
+
+	if len(m.FeeStrategy) > 0 {
+		if _, ok := feeStrategies[m.FeeStrategy]; !ok {
+			return fmt.Errorf("%s is not a valid fee_strategy", m.FeeStrategy)
+		}
+
+		if m.FeePercentile != nil {
+			return errors.New("fee_strategy cannot be populated with fee_percentile")
+		}
+	}
+
+	if m.FeePercentile != nil && (*m.FeePercentile < 0 || *m.FeePercentile > 100) {
+		return fmt.Errorf("fee_percentile must be between 0 and 100 but got %v", *m.FeePercentile)
+	}
+
+	if _, ok := m.feePercentile(); ok && (len(m.GasPrice) > 0 || len(m.GasFeeCap) > 0) {
+		return errors.New("fees cannot be populated with fee_strategy or fee_percentile")
+	}
+
+	return nil
+}
+
+// feePercentile returns the reward percentile of the
+// populated fee_strategy or fee_percentile, if any.
+func (m *preprocessMetadata) feePercentile() (float64, bool) {
+	if percentile, ok := feeStrategies[m.FeeStrategy]; ok {
+		return percentile, true
+	}
+
+	if m.FeePercentile != nil {
+		return *m.FeePercentile, true
+	}
+
+	return 0, false
+}
+
+// complete returns an error listing the fields that must be
+// populated to construct a transaction without geth. The
+// gas_limit is only required for contract calls and
+// deployments, as the gas limit of a transfer is fixed.
+func (m *preprocessMetadata) complete(executesCode bool) error {
+	if m.CreateAccessList {
+		return errors.New("create_access_list requires geth")
+	}
+
+	if _, ok := m.feePercentile(); ok {
+		return errors.New("fee_strategy and fee_percentile require geth")
+	}
+
+	missing := []string{}
+	if len(m.Nonce) == 0 {
+		missing = append(missing, "nonce")
+	}
+
+	if len(m.GasPrice) == 0 && len(m.GasFeeCap) == 0 {
+		missing = append(missing, "gas_price or max_fee_per_gas")
+	}
+
+	if executesCode && len(m.GasLimit) == 0 {
+		missing = append(missing, "gas_limit")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s must be populated", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// fees decodes the populated fees.
+func (m *preprocessMetadata) fees() (*big.Int, *big.Int, *big.Int, error) {
+	gasPrice, err := decodeOptionalBig(m.GasPrice)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode gas_price", err)
+	}
+
+	gasTipCap, err := decodeOptionalBig(m.GasTipCap)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode max_priority_fee_per_gas", err)
+	}
+
+	gasFeeCap, err := decodeOptionalBig(m.GasFeeCap)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to decode max_fee_per_gas", err)
+	}
+
+	return gasPrice, gasTipCap, gasFeeCap, nil
+}
+
+// accessListMetadata is populated in the metadata of the
+// operation crediting the recipient to send an EIP-2930
+// transaction that accesses the provided addresses and
+// storage slots at a discount.
+type accessListMetadata struct {
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
 }
 
+// contractCall is populated in the metadata of the operation
+// crediting a contract to call it. The calldata is either
+// provided as Data or encoded from MethodSignature and MethodArgs.
+type contractCall struct {
+	MethodSignature string   `json:"method_signature,omitempty"`
+	MethodArgs      []string `json:"method_args,omitempty"`
+	Data            string   `json:"data,omitempty"`
+}
+
+// calldata returns the calldata of the contract call.
+func (c *contractCall) calldata() ([]byte, error) {
+	if len(c.MethodSignature) > 0 {
+		if len(c.Data) > 0 {
+			return nil, errors.New("only one of method_signature and data can be populated")
+		}
+
+		return ethereum.ContractCallData(c.MethodSignature, c.MethodArgs)
+	}
+
+	return hexutil.Decode(c.Data)
+}
+
+// contractCreation is populated in the metadata of a CREATE
+// operation to deploy a contract with the provided init code.
+type contractCreation struct {
+	InitCode string `json:"init_code"`
+}
+
+// deployment is a contract deployment constructed
+// from the operations of a transaction. The sender is
+// debited value, which is sent to the deployed contract.
+type deployment struct {
+	from     string
+	value    *big.Int
+	initCode []byte
+}
+
+// metadata contains the fee parameters for either a legacy
+// transaction (GasPrice) or an EIP-1559 transaction (GasTipCap
+// and GasFeeCap). AccessList is populated when the access list
+// was created by geth.
 type metadata struct {
-	Nonce    uint64   `json:"nonce"`
-	GasPrice *big.Int `json:"gas_price"`
+	Nonce      uint64              `json:"nonce"`
+	GasPrice   *big.Int            `json:"gas_price,omitempty"`
+	GasTipCap  *big.Int            `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap  *big.Int            `json:"max_fee_per_gas,omitempty"`
+	BaseFee    *big.Int            `json:"base_fee,omitempty"`
+	GasLimit   uint64              `json:"gas_limit,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
 }
 
 type metadataWire struct {
-	Nonce    string `json:"nonce"`
-	GasPrice string `json:"gas_price"`
+	Nonce      string              `json:"nonce"`
+	GasPrice   string              `json:"gas_price,omitempty"`
+	GasTipCap  string              `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap  string              `json:"max_fee_per_gas,omitempty"`
+	BaseFee    string              `json:"base_fee,omitempty"`
+	GasLimit   string              `json:"gas_limit,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
 }
 
 func (m *metadata) MarshalJSON() ([]byte, error) {
 	mw := &metadataWire{
-		Nonce:    hexutil.Uint64(m.Nonce).String(),
-		GasPrice: hexutil.EncodeBig(m.GasPrice),
+		Nonce:      hexutil.Uint64(m.Nonce).String(),
+		GasPrice:   encodeOptionalBig(m.GasPrice),
+		GasTipCap:  encodeOptionalBig(m.GasTipCap),
+		GasFeeCap:  encodeOptionalBig(m.GasFeeCap),
+		BaseFee:    encodeOptionalBig(m.BaseFee),
+		AccessList: m.AccessList,
+	}
+	if m.GasLimit > 0 {
+		mw.GasLimit = hexutil.EncodeUint64(m.GasLimit)
 	}
 
 	return json.Marshal(mw)
@@ -101,70 +408,146 @@ func (m *metadata) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	gasPrice, err := hexutil.DecodeBig(mw.GasPrice)
+	gasPrice, err := decodeOptionalBig(mw.GasPrice)
 	if err != nil {
 		return err
 	}
 
+	gasTipCap, err := decodeOptionalBig(mw.GasTipCap)
+	if err != nil {
+		return err
+	}
+
+	gasFeeCap, err := decodeOptionalBig(mw.GasFeeCap)
+	if err != nil {
+		return err
+	}
+
+	baseFee, err := decodeOptionalBig(mw.BaseFee)
+	if err != nil {
+		return err
+	}
+
+	var gasLimit uint64
+	if len(mw.GasLimit) > 0 {
+		gasLimit, err = hexutil.DecodeUint64(mw.GasLimit)
+		if err != nil {
+			return err
+		}
+	}
+
+	if gasPrice == nil && gasFeeCap == nil {
+		return errors.New("either gas_price or max_fee_per_gas must be populated")
+	}
+
+	if gasFeeCap != nil && gasTipCap == nil {
+		return errors.New("max_priority_fee_per_gas must be populated with max_fee_per_gas")
+	}
+
 	m.GasPrice = gasPrice
+	m.GasTipCap = gasTipCap
+	m.GasFeeCap = gasFeeCap
+	m.BaseFee = baseFee
+	m.GasLimit = gasLimit
 	m.Nonce = nonce
+	m.AccessList = mw.AccessList
 	return nil
 }
 
+// parseMetadata is returned by /construction/parse.
+// ContractAddress is populated when the transaction
+// deploys a contract.
 type parseMetadata struct {
-	Nonce    uint64   `json:"nonce"`
-	GasPrice *big.Int `json:"gas_price"`
-	ChainID  *big.Int `json:"chain_id"`
+	Nonce           uint64   `json:"nonce"`
+	GasPrice        *big.Int `json:"gas_price,omitempty"`
+	GasTipCap       *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap       *big.Int `json:"max_fee_per_gas,omitempty"`
+	ChainID         *big.Int `json:"chain_id"`
+	ContractAddress string   `json:"contract_address,omitempty"`
 }
 
 type parseMetadataWire struct {
-	Nonce    string `json:"nonce"`
-	GasPrice string `json:"gas_price"`
-	ChainID  string `json:"chain_id"`
+	Nonce           string `json:"nonce"`
+	GasPrice        string `json:"gas_price,omitempty"`
+	GasTipCap       string `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap       string `json:"max_fee_per_gas,omitempty"`
+	ChainID         string `json:"chain_id"`
+	ContractAddress string `json:"contract_address,omitempty"`
 }
 
 func (p *parseMetadata) MarshalJSON() ([]byte, error) {
 	pmw := &parseMetadataWire{
-		Nonce:    hexutil.Uint64(p.Nonce).String(),
-		GasPrice: hexutil.EncodeBig(p.GasPrice),
-		ChainID:  hexutil.EncodeBig(p.ChainID),
+		Nonce:           hexutil.Uint64(p.Nonce).String(),
+		GasPrice:        encodeOptionalBig(p.GasPrice),
+		GasTipCap:       encodeOptionalBig(p.GasTipCap),
+		GasFeeCap:       encodeOptionalBig(p.GasFeeCap),
+		ChainID:         hexutil.EncodeBig(p.ChainID),
+		ContractAddress: p.ContractAddress,
 	}
 
 	return json.Marshal(pmw)
 }
 
+// transaction is the unsigned transaction passed between
+// /construction/payloads and /construction/combine. GasPrice
+// is populated for legacy and EIP-2930 transactions and
+// GasTipCap/GasFeeCap are populated for EIP-1559 transactions.
+// AccessList is populated for EIP-2930 transactions and
+// EIP-1559 transactions with an access list. To is empty
+// when the transaction deploys a contract.
 type transaction struct {
-	From     string   `json:"from"`
-	To       string   `json:"to"`
-	Value    *big.Int `json:"value"`
-	Data     []byte   `json:"data"`
-	Nonce    uint64   `json:"nonce"`
-	GasPrice *big.Int `json:"gas_price"`
-	GasLimit uint64   `json:"gas"`
-	ChainID  *big.Int `json:"chain_id"`
+	From       string              `json:"from"`
+	To         string              `json:"to,omitempty"`
+	Value      *big.Int            `json:"value"`
+	Data       []byte              `json:"data"`
+	Nonce      uint64              `json:"nonce"`
+	GasPrice   *big.Int            `json:"gas_price,omitempty"`
+	GasTipCap  *big.Int            `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap  *big.Int            `json:"max_fee_per_gas,omitempty"`
+	GasLimit   uint64              `json:"gas"`
+	ChainID    *big.Int            `json:"chain_id"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	// MethodSignature and MethodArgs are populated when the
+	// calldata was encoded from a method signature so that
+	// /construction/parse can return them.
+	MethodSignature string   `json:"method_signature,omitempty"`
+	MethodArgs      []string `json:"method_args,omitempty"`
 }
 
 type transactionWire struct {
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Value    string `json:"value"`
-	Data     string `json:"data"`
-	Nonce    string `json:"nonce"`
-	GasPrice string `json:"gas_price"`
-	GasLimit string `json:"gas"`
-	ChainID  string `json:"chain_id"`
+	From       string              `json:"from"`
+	To         string              `json:"to,omitempty"`
+	Value      string              `json:"value"`
+	Data       string              `json:"data"`
+	Nonce      string              `json:"nonce"`
+	GasPrice   string              `json:"gas_price,omitempty"`
+	GasTipCap  string              `json:"max_priority_fee_per_gas,omitempty"`
+	GasFeeCap  string              `json:"max_fee_per_gas,omitempty"`
+	GasLimit   string              `json:"gas"`
+	ChainID    string              `json:"chain_id"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	MethodSignature string   `json:"method_signature,omitempty"`
+	MethodArgs      []string `json:"method_args,omitempty"`
 }
 
 func (t *transaction) MarshalJSON() ([]byte, error) {
 	tw := &transactionWire{
-		From:     t.From,
-		To:       t.To,
-		Value:    hexutil.EncodeBig(t.Value),
-		Data:     hexutil.Encode(t.Data),
-		Nonce:    hexutil.EncodeUint64(t.Nonce),
-		GasPrice: hexutil.EncodeBig(t.GasPrice),
-		GasLimit: hexutil.EncodeUint64(t.GasLimit),
-		ChainID:  hexutil.EncodeBig(t.ChainID),
+		From:       t.From,
+		To:         t.To,
+		Value:      hexutil.EncodeBig(t.Value),
+		Data:       hexutil.Encode(t.Data),
+		Nonce:      hexutil.EncodeUint64(t.Nonce),
+		GasPrice:   encodeOptionalBig(t.GasPrice),
+		GasTipCap:  encodeOptionalBig(t.GasTipCap),
+		GasFeeCap:  encodeOptionalBig(t.GasFeeCap),
+		GasLimit:   hexutil.EncodeUint64(t.GasLimit),
+		ChainID:    hexutil.EncodeBig(t.ChainID),
+		AccessList: t.AccessList,
+
+		MethodSignature: t.MethodSignature,
+		MethodArgs:      t.MethodArgs,
 	}
 
 	return json.Marshal(tw)
@@ -191,7 +574,17 @@ func (t *transaction) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	gasPrice, err := hexutil.DecodeBig(tw.GasPrice)
+	gasPrice, err := decodeOptionalBig(tw.GasPrice)
+	if err != nil {
+		return err
+	}
+
+	gasTipCap, err := decodeOptionalBig(tw.GasTipCap)
+	if err != nil {
+		return err
+	}
+
+	gasFeeCap, err := decodeOptionalBig(tw.GasFeeCap)
 	if err != nil {
 		return err
 	}
@@ -212,8 +605,221 @@ func (t *transaction) UnmarshalJSON(data []byte) error {
 	t.Data = twData
 	t.Nonce = nonce
 	t.GasPrice = gasPrice
+	t.GasTipCap = gasTipCap
+	t.GasFeeCap = gasFeeCap
 	t.GasLimit = gasLimit
 	t.ChainID = chainID
-	t.GasPrice = gasPrice
+	t.AccessList = tw.AccessList
+	t.MethodSignature = tw.MethodSignature
+	t.MethodArgs = tw.MethodArgs
 	return nil
 }
+
+// smartAccountMetadata is returned in the metadata of the
+// *types.AccountIdentifier of a smart account so that the
+// first user operation of the account can deploy it.
+type smartAccountMetadata struct {
+	Owner string `json:"owner"`
+}
+
+// userOperationOptions is passed from /construction/preprocess to
+// /construction/metadata when constructing user operations. Owner
+// is only populated when the sender account identifier includes
+// it, which is required if the smart account is not deployed yet.
+type userOperationOptions struct {
+	Sender   string        `json:"sender"`
+	Owner    string        `json:"owner,omitempty"`
+	CallData hexutil.Bytes `json:"call_data"`
+}
+
+// userOperationMetadata contains the owner of the smart account
+// and the nonce, gas, and fees of a user operation. InitCode is
+// populated when the smart account is not deployed yet, so that
+// the user operation deploys it.
+type userOperationMetadata struct {
+	Owner                string        `json:"owner"`
+	Nonce                *hexutil.Big  `json:"nonce"`
+	InitCode             hexutil.Bytes `json:"init_code,omitempty"`
+	CallGasLimit         *hexutil.Big  `json:"call_gas_limit"`
+	VerificationGasLimit *hexutil.Big  `json:"verification_gas_limit"`
+	PreVerificationGas   *hexutil.Big  `json:"pre_verification_gas"`
+	MaxFeePerGas         *hexutil.Big  `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big  `json:"max_priority_fee_per_gas"`
+}
+
+// validate returns an error if any field but
+// InitCode is not populated.
+func (m *userOperationMetadata) validate() error {
+	if len(m.Owner) == 0 {
+		return errors.New("owner must be populated")
+	}
+
+	if m.Nonce == nil {
+		return errors.New("nonce must be populated")
+	}
+
+	if m.CallGasLimit == nil || m.VerificationGasLimit == nil || m.PreVerificationGas == nil {
+		return errors.New(
+			"call_gas_limit, verification_gas_limit, and pre_verification_gas must be populated",
+		)
+	}
+
+	if m.MaxFeePerGas == nil || m.MaxPriorityFeePerGas == nil {
+		return errors.New("max_fee_per_gas and max_priority_fee_per_gas must be populated")
+	}
+
+	return nil
+}
+
+// userOperation returns the unsigned *ethereum.UserOperation
+// of sender that executes callData.
+func (m *userOperationMetadata) userOperation(
+	sender common.Address,
+	callData []byte,
+) *ethereum.UserOperation {
+	return &ethereum.UserOperation{
+		Sender:               sender,
+		Nonce:                m.Nonce,
+		InitCode:             m.InitCode,
+		CallData:             callData,
+		CallGasLimit:         m.CallGasLimit,
+		VerificationGasLimit: m.VerificationGasLimit,
+		PreVerificationGas:   m.PreVerificationGas,
+		MaxFeePerGas:         m.MaxFeePerGas,
+		MaxPriorityFeePerGas: m.MaxPriorityFeePerGas,
+		PaymasterAndData:     []byte{},
+		Signature:            []byte{},
+	}
+}
+
+// unsignedUserOperation is the unsigned user operation passed
+// between /construction/payloads and /construction/combine.
+type unsignedUserOperation struct {
+	ethereum.UserOperation
+
+	// Owner is the owner of the smart account, whose signature
+	// of the userOpHash is verified in /construction/combine.
+	Owner string `json:"owner"`
+
+	// MethodSignature and MethodArgs are populated when the
+	// calldata executed by the smart account was encoded from
+	// a method signature so that /construction/parse can
+	// return them.
+	MethodSignature string   `json:"method_signature,omitempty"`
+	MethodArgs      []string `json:"method_args,omitempty"`
+}
+
+// safeMode is embedded in the options, metadata, and
+// transactions passed between the construction endpoints when
+// constructing a Safe transaction. It is set by
+// /construction/preprocess and carried through each endpoint,
+// so that all endpoints select the Safe flow with this field.
+type safeMode struct {
+	SafeTransaction bool `json:"safe_transaction"`
+}
+
+// safeModeMetadata is populated in the metadata of
+// /construction/preprocess to construct a Safe transaction
+// executed by the debited account, which must be a Safe. The
+// nonce, owners, and threshold of the Safe can be provided to
+// skip fetching them from geth. In offline mode, they must be
+// provided. It is also the metadata returned by
+// /construction/metadata.
+type safeModeMetadata struct {
+	safeMode
+	safeMetadata
+}
+
+// safeOptions is passed from /construction/preprocess to
+// /construction/metadata when constructing a Safe transaction.
+type safeOptions struct {
+	safeMode
+
+	Safe string `json:"safe"`
+
+	safeMetadata
+}
+
+// safeMetadata contains the nonce, owners, and
+// signature threshold of a Safe.
+type safeMetadata struct {
+	SafeNonce     *hexutil.Big    `json:"safe_nonce,omitempty"`
+	SafeOwners    []string        `json:"safe_owners,omitempty"`
+	SafeThreshold *hexutil.Uint64 `json:"safe_threshold,omitempty"`
+}
+
+// empty returns a boolean indicating if no
+// field of the metadata is populated.
+func (m *safeMetadata) empty() bool {
+	return m.SafeNonce == nil && len(m.SafeOwners) == 0 && m.SafeThreshold == nil
+}
+
+// validate returns an error if any field of the metadata
+// is not populated, if any owner is not a valid address, or
+// if the threshold cannot be met by the owners.
+func (m *safeMetadata) validate() error {
+	if m.SafeNonce == nil || len(m.SafeOwners) == 0 || m.SafeThreshold == nil {
+		return errors.New("safe_nonce, safe_owners, and safe_threshold must be populated")
+	}
+
+	owners := map[common.Address]struct{}{}
+	for _, owner := range m.SafeOwners {
+		if _, ok := ethereum.ChecksumAddress(owner); !ok {
+			return fmt.Errorf("safe owner %s is not a valid address", owner)
+		}
+
+		if _, ok := owners[common.HexToAddress(owner)]; ok {
+			return fmt.Errorf("safe owner %s is duplicated", owner)
+		}
+		owners[common.HexToAddress(owner)] = struct{}{}
+	}
+
+	threshold := uint64(*m.SafeThreshold)
+	if threshold == 0 || threshold > uint64(len(m.SafeOwners)) {
+		return fmt.Errorf(
+			"safe_threshold %d must be between 1 and the number of owners (%d)",
+			threshold,
+			len(m.SafeOwners),
+		)
+	}
+
+	return nil
+}
+
+// unsignedSafeTransaction is the unsigned Safe transaction
+// passed between /construction/payloads and
+// /construction/combine. Owners are the owners the
+// transaction is signed by, at least Threshold of which
+// must sign it.
+type unsignedSafeTransaction struct {
+	safeMode
+	ethereum.SafeTransaction
+
+	Owners    []string       `json:"owners"`
+	Threshold hexutil.Uint64 `json:"threshold"`
+
+	// MethodSignature and MethodArgs are populated when the
+	// calldata executed by the Safe was encoded from a method
+	// signature so that /construction/parse can return them.
+	MethodSignature string   `json:"method_signature,omitempty"`
+	MethodArgs      []string `json:"method_args,omitempty"`
+}
+
+// signedSafeTransaction is the Safe transaction signed by
+// its owners, passed from /construction/combine to
+// /construction/hash and /construction/submit.
+type signedSafeTransaction struct {
+	safeMode
+	ethereum.SafeTransaction
+}
+
+// safeParseMetadata is returned by /construction/parse for
+// Safe transactions. ExecTransactionData is populated for
+// signed Safe transactions and is the calldata of the call
+// to the Safe that executes the transaction.
+type safeParseMetadata struct {
+	SafeNonce           *hexutil.Big  `json:"safe_nonce"`
+	SafeTxHash          string        `json:"safe_tx_hash"`
+	ChainID             *hexutil.Big  `json:"chain_id"`
+	ExecTransactionData hexutil.Bytes `json:"exec_transaction_data,omitempty"`
+}
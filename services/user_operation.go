@@ -0,0 +1,515 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/ethereum"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// userOperationDummySignature is a well-formed signature used to
+// estimate the gas of a user operation before it is signed. Smart
+// accounts revert on malformed signatures, and the verification
+// gas depends on the length of the signature.
+var userOperationDummySignature = hexutil.MustDecode(
+	"0x" +
+		"fffffffffffffffffffffffffffffff000000000000000000000000000000000" +
+		"7aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" +
+		"1c",
+)
+
+// deriveSmartAccount returns the *types.AccountIdentifier of the
+// smart account of owner, including the owner in its metadata.
+// The counterfactual address is computed from the proxy code of
+// the account factory if it is configured (as it must be offline).
+// Otherwise, it is fetched from the account factory.
+func (s *ConstructionAPIService) deriveSmartAccount(
+	ctx context.Context,
+	owner common.Address,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	accountAbstraction := s.config.AccountAbstraction
+
+	var sender common.Address
+	switch {
+	case len(accountAbstraction.AccountProxyCode) > 0:
+		sender = ethereum.CounterfactualSmartAccountAddress(
+			accountAbstraction.AccountFactory,
+			accountAbstraction.AccountImplementation,
+			accountAbstraction.AccountProxyCode,
+			owner,
+		)
+	case s.config.Mode != configuration.Online:
+		return nil, ErrUnavailableOffline
+	default:
+		var err error
+		sender, err = s.client.SmartAccountAddress(ctx, accountAbstraction.AccountFactory, owner)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+	}
+
+	accountMetadata, err := marshalJSONMap(&smartAccountMetadata{Owner: owner.Hex()})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address:  sender.Hex(),
+			Metadata: accountMetadata,
+		},
+	}, nil
+}
+
+// preprocessUserOperation returns the options of a user operation
+// in which the smart account debited by the operations executes
+// the transfer.
+func (s *ConstructionAPIService) preprocessUserOperation(
+	request *types.ConstructionPreprocessRequest,
+) (*types.ConstructionPreprocessResponse, *types.Error) {
+	// The nonce, gas, and fees of a user operation are
+	// always fetched in /construction/metadata.
+	if len(request.Metadata) > 0 {
+		return nil, wrapErr(
+			ErrInvalidInput,
+			errors.New("metadata is not supported when constructing user operations"),
+		)
+	}
+
+	call, rErr := s.executedCall(request.Operations, "user operations")
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	owner, rErr := intentOwner(request.Operations, call.From)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	preprocessOutput := &userOperationOptions{
+		Sender: call.From,
+		Owner:  owner,
+		CallData: ethereum.SmartAccountExecuteData(
+			common.HexToAddress(call.To),
+			call.Value,
+			call.Data,
+		),
+	}
+
+	marshaled, err := marshalJSONMap(preprocessOutput)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: marshaled,
+	}, nil
+}
+
+// userOperationMetadata returns the nonce, init code, gas, and
+// fees of a user operation. The gas is estimated by the bundler,
+// so the metadata can only be fetched in online mode.
+func (s *ConstructionAPIService) userOperationMetadata(
+	ctx context.Context,
+	request *types.ConstructionMetadataRequest,
+) (*types.ConstructionMetadataResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	var input userOperationOptions
+	if err := unmarshalJSONMap(request.Options, &input); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	sender := common.HexToAddress(input.Sender)
+	entryPoint := s.config.AccountAbstraction.EntryPoint
+
+	nonce, err := s.client.UserOperationNonce(ctx, entryPoint, sender)
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	code, err := s.client.CodeAt(ctx, sender, nil)
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	opMetadata := &userOperationMetadata{
+		Nonce: (*hexutil.Big)(nonce),
+	}
+
+	// The first user operation of a smart account
+	// deploys it with the account factory. Once it is
+	// deployed, its owner is fetched from the account.
+	if len(code) == 0 {
+		if len(input.Owner) == 0 {
+			return nil, wrapErr(
+				ErrInvalidInput,
+				fmt.Errorf("owner of %s must be populated to deploy it", input.Sender),
+			)
+		}
+
+		opMetadata.Owner = input.Owner
+		opMetadata.InitCode = ethereum.AccountFactoryInitCode(
+			s.config.AccountAbstraction.AccountFactory,
+			common.HexToAddress(input.Owner),
+		)
+	} else {
+		owner, err := s.client.SmartAccountOwner(ctx, sender)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+
+		opMetadata.Owner = owner.Hex()
+	}
+
+	// User operations are priced like EIP-1559 transactions.
+	// Before EIP-1559, the gas price is both the tip and
+	// the max fee per gas.
+	fees := &metadata{}
+	gasPrice, rErr := s.gasPrice(ctx, &preprocessMetadata{}, fees)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if fees.GasFeeCap != nil {
+		opMetadata.MaxFeePerGas = (*hexutil.Big)(fees.GasFeeCap)
+		opMetadata.MaxPriorityFeePerGas = (*hexutil.Big)(fees.GasTipCap)
+	} else {
+		opMetadata.MaxFeePerGas = (*hexutil.Big)(fees.GasPrice)
+		opMetadata.MaxPriorityFeePerGas = (*hexutil.Big)(fees.GasPrice)
+	}
+
+	op := opMetadata.userOperation(sender, input.CallData)
+	op.CallGasLimit = (*hexutil.Big)(new(big.Int))
+	op.VerificationGasLimit = (*hexutil.Big)(new(big.Int))
+	op.PreVerificationGas = (*hexutil.Big)(new(big.Int))
+	op.Signature = userOperationDummySignature
+
+	gas, err := s.client.EstimateUserOperationGas(ctx, op, entryPoint)
+	if err != nil {
+		return nil, wrapErr(ErrBundler, err)
+	}
+
+	opMetadata.CallGasLimit = gas.CallGasLimit
+	opMetadata.VerificationGasLimit = gas.VerificationGasLimit
+	opMetadata.PreVerificationGas = gas.PreVerificationGas
+
+	metadataMap, err := marshalJSONMap(opMetadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	// The sender pays for all gas of the user operation.
+	totalGas := new(big.Int).Add(gas.CallGasLimit.ToInt(), gas.VerificationGasLimit.ToInt())
+	totalGas.Add(totalGas, gas.PreVerificationGas.ToInt())
+	suggestedFee := new(big.Int).Mul(gasPrice, totalGas)
+
+	return &types.ConstructionMetadataResponse{
+		Metadata: metadataMap,
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    suggestedFee.String(),
+				Currency: ethereum.Currency,
+			},
+		},
+	}, nil
+}
+
+// userOperationPayloads returns the unsigned user operation and
+// its signing payload. Smart accounts verify an EIP-191 signature
+// of the userOpHash by their owner, so the payload is the hash of
+// the userOpHash as a signed message.
+func (s *ConstructionAPIService) userOperationPayloads(
+	request *types.ConstructionPayloadsRequest,
+) (*types.ConstructionPayloadsResponse, *types.Error) {
+	var opMetadata userOperationMetadata
+	if err := unmarshalJSONMap(request.Metadata, &opMetadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	if err := opMetadata.validate(); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	call, rErr := s.executedCall(request.Operations, "user operations")
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	op := opMetadata.userOperation(
+		common.HexToAddress(call.From),
+		ethereum.SmartAccountExecuteData(common.HexToAddress(call.To), call.Value, call.Data),
+	)
+	userOpHash := op.Hash(s.config.AccountAbstraction.EntryPoint, s.config.Params.ChainID)
+
+	payload := &types.SigningPayload{
+		AccountIdentifier: &types.AccountIdentifier{Address: call.From},
+		Bytes:             accounts.TextHash(userOpHash.Bytes()),
+		SignatureType:     types.EcdsaRecovery,
+	}
+
+	unsignedOpJSON, err := json.Marshal(&unsignedUserOperation{
+		UserOperation:   *op,
+		Owner:           opMetadata.Owner,
+		MethodSignature: call.MethodSignature,
+		MethodArgs:      call.MethodArgs,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: string(unsignedOpJSON),
+		Payloads:            []*types.SigningPayload{payload},
+	}, nil
+}
+
+// combineUserOperation returns the user operation
+// signed with the signature of its payload.
+func (s *ConstructionAPIService) combineUserOperation(
+	request *types.ConstructionCombineRequest,
+) (*types.ConstructionCombineResponse, *types.Error) {
+	var unsignedOp unsignedUserOperation
+	if err := json.Unmarshal([]byte(request.UnsignedTransaction), &unsignedOp); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	if len(request.Signatures) != 1 {
+		return nil, wrapErr(
+			ErrSignatureInvalid,
+			fmt.Errorf("expected 1 signature but got %d", len(request.Signatures)),
+		)
+	}
+
+	signature := request.Signatures[0].Bytes
+	if len(signature) != crypto.SignatureLength {
+		return nil, wrapErr(
+			ErrSignatureInvalid,
+			fmt.Errorf("expected %d byte signature but got %d", crypto.SignatureLength, len(signature)),
+		)
+	}
+
+	// Smart accounts only accept a signature of the
+	// userOpHash by their owner, so any other signature
+	// would be rejected by the bundler.
+	op := unsignedOp.UserOperation
+	userOpHash := op.Hash(s.config.AccountAbstraction.EntryPoint, s.config.Params.ChainID)
+	pubKey, err := crypto.SigToPub(accounts.TextHash(userOpHash.Bytes()), signature)
+	if err != nil {
+		return nil, wrapErr(ErrSignatureInvalid, err)
+	}
+
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != common.HexToAddress(unsignedOp.Owner) {
+		return nil, wrapErr(
+			ErrSignatureInvalid,
+			fmt.Errorf("signer %s is not the owner %s", signer.Hex(), unsignedOp.Owner),
+		)
+	}
+
+	// Smart accounts recover the owner with ecrecover,
+	// which expects a recovery ID of 27 or 28.
+	op.Signature = append(
+		common.CopyBytes(signature[:crypto.RecoveryIDOffset]),
+		signature[crypto.RecoveryIDOffset]+27, // nolint:gomnd
+	)
+
+	signedOpJSON, err := json.Marshal(&op)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionCombineResponse{
+		SignedTransaction: string(signedOpJSON),
+	}, nil
+}
+
+// hashUserOperation returns the userOpHash of the signed user
+// operation, which identifies it in the bundler and EntryPoint.
+func (s *ConstructionAPIService) hashUserOperation(
+	request *types.ConstructionHashRequest,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	var op ethereum.UserOperation
+	if err := json.Unmarshal([]byte(request.SignedTransaction), &op); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	userOpHash := op.Hash(s.config.AccountAbstraction.EntryPoint, s.config.Params.ChainID)
+
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: userOpHash.Hex(),
+		},
+	}, nil
+}
+
+// parseUserOperation returns the operations of the transfer
+// executed by the smart account sending the user operation.
+func (s *ConstructionAPIService) parseUserOperation(
+	request *types.ConstructionParseRequest,
+) (*types.ConstructionParseResponse, *types.Error) {
+	var unsignedOp unsignedUserOperation
+	if err := json.Unmarshal([]byte(request.Transaction), &unsignedOp); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	// Signed user operations do not include the method
+	// signature, so the raw calldata is returned instead.
+	op := unsignedOp.UserOperation
+	if request.Signed {
+		unsignedOp.MethodSignature = ""
+		unsignedOp.MethodArgs = nil
+	}
+
+	if op.Nonce == nil || !op.Nonce.ToInt().IsUint64() {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			errors.New("nonce must be populated with a nonce key of 0"),
+		)
+	}
+
+	to, value, data, ok := ethereum.ParseSmartAccountExecuteData(op.CallData)
+	if !ok {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			errors.New("callData is not a smart account execute call"),
+		)
+	}
+
+	checkSender := op.Sender.Hex()
+	ops, rErr := s.transferOperations(checkSender, &transaction{
+		From:            checkSender,
+		To:              to.Hex(),
+		Value:           value,
+		Data:            data,
+		MethodSignature: unsignedOp.MethodSignature,
+		MethodArgs:      unsignedOp.MethodArgs,
+	})
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	metaMap, err := marshalJSONMap(&parseMetadata{
+		Nonce:     op.Nonce.ToInt().Uint64(),
+		GasTipCap: op.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: op.MaxFeePerGas.ToInt(),
+		ChainID:   s.config.Params.ChainID,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	signers := []*types.AccountIdentifier{}
+	if request.Signed {
+		signers = append(signers, &types.AccountIdentifier{Address: checkSender})
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               ops,
+		AccountIdentifierSigners: signers,
+		Metadata:                 metaMap,
+	}, nil
+}
+
+// submitUserOperation submits the signed user operation
+// to the bundler and returns its userOpHash.
+func (s *ConstructionAPIService) submitUserOperation(
+	ctx context.Context,
+	request *types.ConstructionSubmitRequest,
+) (*types.TransactionIdentifierResponse, *types.Error) {
+	var op ethereum.UserOperation
+	if err := json.Unmarshal([]byte(request.SignedTransaction), &op); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	userOpHash, err := s.client.SendUserOperation(ctx, &op, s.config.AccountAbstraction.EntryPoint)
+	if err != nil {
+		return nil, wrapErr(ErrBroadcastFailed, err)
+	}
+
+	return &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: userOpHash.Hex(),
+		},
+	}, nil
+}
+
+// executedCall returns the call the smart account or Safe
+// debited by the operations executes to transfer ETH or tokens.
+// Access lists only apply to transactions, so they are not
+// supported in the calls of the provided kind.
+func (s *ConstructionAPIService) executedCall(
+	operations []*types.Operation,
+	kind string,
+) (*transaction, *types.Error) {
+	call, _, rErr := s.transferCall(operations)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	if len(call.AccessList) > 0 {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("access lists are not supported in %s", kind),
+		)
+	}
+
+	return call, nil
+}
+
+// intentOwner returns the owner in the account identifier
+// metadata of the sender, if any.
+func intentOwner(operations []*types.Operation, sender string) (string, *types.Error) {
+	for _, op := range operations {
+		if op.Account == nil || common.HexToAddress(op.Account.Address) != common.HexToAddress(sender) {
+			continue
+		}
+
+		var account smartAccountMetadata
+		if err := unmarshalJSONMap(op.Account.Metadata, &account); err != nil {
+			return "", wrapErr(ErrUnclearIntent, err)
+		}
+
+		if len(account.Owner) == 0 {
+			continue
+		}
+
+		checkOwner, ok := ethereum.ChecksumAddress(account.Owner)
+		if !ok {
+			return "", wrapErr(
+				ErrInvalidAddress,
+				fmt.Errorf("%s is not a valid address", account.Owner),
+			)
+		}
+
+		return checkOwner, nil
+	}
+
+	return "", nil
+}
@@ -16,6 +16,9 @@ package services
 
 import (
 	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // *JSONMap functions are needed because `types.MarshalMap/types.UnmarshalMap`
@@ -45,3 +48,23 @@ func unmarshalJSONMap(m map[string]interface{}, i interface{}) error {
 
 	return json.Unmarshal(b, i)
 }
+
+// encodeOptionalBig encodes a *big.Int as a hex string, returning
+// an empty string if the value is nil.
+func encodeOptionalBig(i *big.Int) string {
+	if i == nil {
+		return ""
+	}
+
+	return hexutil.EncodeBig(i)
+}
+
+// decodeOptionalBig decodes a hex string into a *big.Int, returning
+// nil if the string is empty.
+func decodeOptionalBig(s string) (*big.Int, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	return hexutil.DecodeBig(s)
+}